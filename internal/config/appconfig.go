@@ -3,7 +3,6 @@ package config
 import (
 	"encoding/json"
 	"os"
-	"path/filepath"
 )
 
 // BrowseDir defines a directory to scan for subdirectories when browsing.
@@ -12,6 +11,24 @@ type BrowseDir struct {
 	Depth int    `json:"depth"`
 }
 
+// RemoteHost defines a machine whose tmux sessions are listed over ssh.
+type RemoteHost struct {
+	Name string `json:"name"` // label shown in the grid; defaults to Host
+	Host string `json:"host"` // ssh destination, e.g. "user@devbox" or a Host alias
+}
+
+// SessionTemplate lists the windows a new session starts with.
+type SessionTemplate struct {
+	Windows []TemplateWindow `json:"windows"`
+}
+
+// TemplateWindow is one window of a SessionTemplate.
+type TemplateWindow struct {
+	Name    string `json:"name"`
+	Dir     string `json:"dir"`     // relative to the session's directory; empty = the same
+	Command string `json:"command"` // typed into the window's shell
+}
+
 // UIConfig holds visual/layout preferences.
 type UIConfig struct {
 	CardMinWidth int `json:"card_min_width"` // minimum card content width; 0 = use built-in default
@@ -20,10 +37,16 @@ type UIConfig struct {
 // AppConfig holds read-only application settings loaded from tswitch-config.json.
 // This is separate from Config (config.yaml) which stores runtime state.
 type AppConfig struct {
-	Keys          map[string]string `json:"keys"`
-	BrowseDirs    []BrowseDir       `json:"browse_dirs"`
-	BrowseExclude []string          `json:"browse_exclude"`
-	UI            UIConfig          `json:"ui"`
+	Keys          map[string]string            `json:"keys"`    // action -> key sequence, all grid modes
+	Keymaps       map[string]map[string]string `json:"keymaps"` // mode -> action -> key sequence
+	Leader        string                       `json:"leader"`  // key that <leader> stands for; default "\\"
+	KeyTimeoutMs  int                          `json:"key_timeout_ms"`
+	BrowseDirs    []BrowseDir                  `json:"browse_dirs"`
+	BrowseExclude []string                     `json:"browse_exclude"`
+	UI            UIConfig                     `json:"ui"`
+	Sockets       []string                     `json:"sockets"` // extra tmux servers: socket names (-L) or paths (-S)
+	RemoteHosts   []RemoteHost                 `json:"remote_hosts"`
+	Templates     map[string]SessionTemplate   `json:"templates"` // name -> windows, offered by the new-session form
 }
 
 // DefaultAppConfig returns an AppConfig with no overrides (all defaults).
@@ -31,29 +54,19 @@ func DefaultAppConfig() *AppConfig {
 	return &AppConfig{}
 }
 
-// LoadAppConfig searches for tswitch-config.json in order:
-//  1. Same directory as the binary
-//  2. ~/.tswitch/
-//
-// Returns DefaultAppConfig if no file is found.
+// LoadAppConfig reads tswitch-config.json from where AppConfigPath finds it.
+// Returns DefaultAppConfig if there is none, and also along with the error
+// if it can't be read.
 func LoadAppConfig() (*AppConfig, error) {
-	// 1. Same directory as binary.
-	if exePath, err := os.Executable(); err == nil {
-		p := filepath.Join(filepath.Dir(exePath), "tswitch-config.json")
-		if cfg, err := loadAppConfigFrom(p); err == nil {
-			return cfg, nil
-		}
+	p, err := AppConfigPath()
+	if err != nil || !p.Exists && !p.Explicit {
+		return DefaultAppConfig(), err
 	}
-
-	// 2. ~/.tswitch/
-	if home, err := os.UserHomeDir(); err == nil {
-		p := filepath.Join(home, ".tswitch", "tswitch-config.json")
-		if cfg, err := loadAppConfigFrom(p); err == nil {
-			return cfg, nil
-		}
+	cfg, err := loadAppConfigFrom(p.File)
+	if err != nil {
+		return DefaultAppConfig(), err
 	}
-
-	return DefaultAppConfig(), nil
+	return cfg, nil
 }
 
 func loadAppConfigFrom(path string) (*AppConfig, error) {
@@ -4,8 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-
-	"gopkg.in/yaml.v3"
+	"sort"
 )
 
 const (
@@ -15,15 +14,21 @@ const (
 
 // Config holds the application configuration.
 type Config struct {
+	Version      int                 `yaml:"version"` // see StateVersion
 	Tags         map[string][]string `yaml:"tags"`
 	Marks        map[string]Mark     `yaml:"marks"`
 	Settings     Settings            `yaml:"settings"`
-	SessionOrder []string            `yaml:"session_order,omitempty"`
-	WindowOrder  map[string][]int    `yaml:"window_order,omitempty"`
+	SessionOrder []string            `yaml:"session_order,omitempty"` // session keys, see SessionKey
+	WindowOrder  map[string][]int    `yaml:"window_order,omitempty"`  // session key -> window indices
+	History      map[string][]string `yaml:"history,omitempty"`       // dialog -> inputs, oldest first
+
+	loaded loaded // see SaveState
 }
 
-// Mark represents a bookmarked session/window/pane.
+// Mark represents a bookmarked session/window/pane. A session mark has
+// WindowIndex -1, a window mark PaneIndex -1.
 type Mark struct {
+	Server      string `yaml:"server,omitempty"` // empty = current server
 	SessionName string `yaml:"session"`
 	WindowIndex int    `yaml:"window"`
 	PaneIndex   int    `yaml:"pane"`
@@ -31,16 +36,18 @@ type Mark struct {
 
 // Settings holds user-level preferences.
 type Settings struct {
-	PreviewMode string `yaml:"preview_mode"` // last-used preview toggle state; empty = capture
-	Theme       string `yaml:"theme"`
-	SortBy      string `yaml:"sort_by"`
+	PreviewMode   string `yaml:"preview_mode"` // last-used preview toggle state; empty = capture
+	GroupByServer bool   `yaml:"group_by_server,omitempty"`
+	Theme         string `yaml:"theme"`
+	SortBy        string `yaml:"sort_by"`
 }
 
 // Default returns a Config with sensible defaults.
 func Default() *Config {
 	return &Config{
-		Tags:  make(map[string][]string),
-		Marks: make(map[string]Mark),
+		Version: StateVersion,
+		Tags:    make(map[string][]string),
+		Marks:   make(map[string]Mark),
 		Settings: Settings{
 			Theme:  "default",
 			SortBy: "activity",
@@ -48,58 +55,79 @@ func Default() *Config {
 	}
 }
 
-// LoadState reads the state from ~/.tswitch/state.yaml.
-// If the file does not exist, it returns defaults.
+// LoadState reads the state from state.yaml (see StatePath).
+// If the file does not exist, it returns defaults. A file written by an
+// older tswitch is upgraded in place, keeping the original as
+// state.yaml.v<N>.bak.
 func LoadState() (*Config, error) {
-	dir, err := configDir()
+	path, err := statePath()
 	if err != nil {
 		return nil, err
 	}
-
-	path := filepath.Join(dir, "state.yaml")
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return Default(), nil
+	if err := upgradeState(path); err != nil {
+		return nil, err
 	}
-
-	data, err := os.ReadFile(path)
+	cfg, info, err := readState(path)
 	if err != nil {
-		return nil, fmt.Errorf("read config: %w", err)
+		return nil, err
 	}
+	cfg.remember(info)
+	return cfg, nil
+}
 
-	cfg := Default() // start with defaults so missing fields are populated
-	if err := yaml.Unmarshal(data, cfg); err != nil {
-		return nil, fmt.Errorf("parse config: %w", err)
+// ReadState reads the state like LoadState but leaves the file alone: an
+// older version is only upgraded in memory. For commands that only look at
+// the state; don't save the result.
+func ReadState() (*Config, error) {
+	path, err := statePath()
+	if err != nil {
+		return nil, err
 	}
-	cfg.fillDefaults()
-	return cfg, nil
+	cfg, _, err := readState(path)
+	return cfg, err
 }
 
-// SaveState writes the state to ~/.tswitch/state.yaml.
+// SaveState writes the state to state.yaml (see StatePath). Changes another
+// instance saved meanwhile are kept, except where cfg changed the same
+// entry, and merged into cfg.
 func SaveState(cfg *Config) error {
-	dir, err := configDir()
+	path, err := statePath()
 	if err != nil {
 		return err
 	}
-	if err := os.MkdirAll(dir, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return fmt.Errorf("create config dir: %w", err)
 	}
 
-	data, err := yaml.Marshal(cfg)
+	unlock, err := lockState(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	disk, _, err := readState(path)
+	if err != nil {
+		return err
+	}
+	merged := merge(cfg.baseState(), cfg, disk)
+	info, err := writeState(path, merged)
 	if err != nil {
-		return fmt.Errorf("marshal config: %w", err)
+		return err
 	}
-	return os.WriteFile(filepath.Join(dir, "state.yaml"), data, 0644)
+	*cfg = *merged
+	cfg.remember(info)
+	return nil
 }
 
 // ---------------------------------------------------------------------------
 // Marks helpers
 // ---------------------------------------------------------------------------
 
-func (c *Config) SetMark(key, sessionName string, windowIndex, paneIndex int) {
+func (c *Config) SetMark(key, server, sessionName string, windowIndex, paneIndex int) {
 	if c.Marks == nil {
 		c.Marks = make(map[string]Mark)
 	}
-	c.Marks[key] = Mark{SessionName: sessionName, WindowIndex: windowIndex, PaneIndex: paneIndex}
+	c.Marks[key] = Mark{Server: server, SessionName: sessionName, WindowIndex: windowIndex, PaneIndex: paneIndex}
 }
 
 func (c *Config) GetMark(key string) *Mark {
@@ -114,9 +142,9 @@ func (c *Config) DeleteMark(key string) { delete(c.Marks, key) }
 // RemoveMarksForTarget deletes all existing marks that point to the
 // same session+window combination, so that reassigning a new key to
 // the same target replaces the old key rather than accumulating duplicates.
-func (c *Config) RemoveMarksForTarget(sessionName string, windowIndex int) {
+func (c *Config) RemoveMarksForTarget(server, sessionName string, windowIndex int) {
 	for key, m := range c.Marks {
-		if m.SessionName == sessionName && m.WindowIndex == windowIndex {
+		if m.Server == server && m.SessionName == sessionName && m.WindowIndex == windowIndex {
 			delete(c.Marks, key)
 		}
 	}
@@ -127,15 +155,22 @@ func (c *Config) HasMark(key string) bool {
 	return ok
 }
 
-// GetSessionMarks returns all mark keys whose target session matches.
-func (c *Config) GetSessionMarks(sessionName string) []string {
-	var out []string
+// MarksFor returns the sorted keys of the marks on server ("" = current)
+// pointing at a session (window < 0), at a window (pane < 0) or a pane of
+// it, or at a pane.
+func (c *Config) MarksFor(server, sessionName string, window, pane int) []string {
+	keys := []string{}
 	for key, m := range c.Marks {
-		if m.SessionName == sessionName {
-			out = append(out, key)
+		if m.Server != server || m.SessionName != sessionName {
+			continue
+		}
+		if window < 0 && m.WindowIndex < 0 ||
+			window >= 0 && m.WindowIndex == window && (pane < 0 || m.PaneIndex == pane) {
+			keys = append(keys, key)
 		}
 	}
-	return out
+	sort.Strings(keys)
+	return keys
 }
 
 // ---------------------------------------------------------------------------
@@ -161,11 +196,23 @@ func (c *Config) ClearWindowOrder(session string) {
 // Tags helpers
 // ---------------------------------------------------------------------------
 
-func (c *Config) GetSessionTags(sessionName string) []string {
+// SessionKey identifies a session in the state (tags, orders): its name on
+// the current server, "server:name" on another one. tmux doesn't allow ":" in session
+// names, so the key is never ambiguous.
+func SessionKey(server, sessionName string) string {
+	if server == "" {
+		return sessionName
+	}
+	return server + ":" + sessionName
+}
+
+// GetSessionTags returns the tags of a session on server ("" = current).
+func (c *Config) GetSessionTags(server, sessionName string) []string {
+	key := SessionKey(server, sessionName)
 	var tags []string
 	for tag, sessions := range c.Tags {
 		for _, s := range sessions {
-			if s == sessionName {
+			if s == key {
 				tags = append(tags, tag)
 			}
 		}
@@ -173,22 +220,24 @@ func (c *Config) GetSessionTags(sessionName string) []string {
 	return tags
 }
 
-func (c *Config) AddSessionTag(sessionName, tag string) {
+func (c *Config) AddSessionTag(server, sessionName, tag string) {
 	if c.Tags == nil {
 		c.Tags = make(map[string][]string)
 	}
+	key := SessionKey(server, sessionName)
 	for _, s := range c.Tags[tag] {
-		if s == sessionName {
+		if s == key {
 			return
 		}
 	}
-	c.Tags[tag] = append(c.Tags[tag], sessionName)
+	c.Tags[tag] = append(c.Tags[tag], key)
 }
 
-func (c *Config) RemoveSessionTag(sessionName, tag string) {
+func (c *Config) RemoveSessionTag(server, sessionName, tag string) {
+	key := SessionKey(server, sessionName)
 	sessions := c.Tags[tag]
 	for i, s := range sessions {
-		if s == sessionName {
+		if s == key {
 			c.Tags[tag] = append(sessions[:i], sessions[i+1:]...)
 			return
 		}
@@ -196,17 +245,36 @@ func (c *Config) RemoveSessionTag(sessionName, tag string) {
 }
 
 // ---------------------------------------------------------------------------
-// Private
+// History helpers
 // ---------------------------------------------------------------------------
 
-func configDir() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("home dir: %w", err)
+// maxHistory caps the entries kept per dialog.
+const maxHistory = 50
+
+// AddHistory records entry as the newest input of dialog, dropping an
+// earlier copy of it.
+func (c *Config) AddHistory(dialog, entry string) {
+	if c.History == nil {
+		c.History = make(map[string][]string)
+	}
+	h := c.History[dialog]
+	for i, e := range h {
+		if e == entry {
+			h = append(h[:i], h[i+1:]...)
+			break
+		}
+	}
+	h = append(h, entry)
+	if len(h) > maxHistory {
+		h = h[len(h)-maxHistory:]
 	}
-	return filepath.Join(home, ".tswitch"), nil
+	c.History[dialog] = h
 }
 
+// ---------------------------------------------------------------------------
+// Private
+// ---------------------------------------------------------------------------
+
 func (c *Config) fillDefaults() {
 	if c.Settings.Theme == "" {
 		c.Settings.Theme = "default"
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMarksFor(t *testing.T) {
+	c := Default()
+	c.SetMark("s", "", "work", -1, -1)
+	c.SetMark("w", "", "work", 1, -1)
+	c.SetMark("p", "", "work", 1, 0)
+	c.SetMark("q", "", "work", 1, 2)
+	c.SetMark("o", "/tmp/other", "work", -1, -1)
+	c.SetMark("x", "", "play", -1, -1)
+
+	tests := []struct {
+		server       string
+		window, pane int
+		want         []string
+	}{
+		{"", -1, -1, []string{"s"}}, // not the window and pane marks
+		{"/tmp/other", -1, -1, []string{"o"}},
+		{"", 1, -1, []string{"p", "q", "w"}}, // a window lists its panes' marks
+		{"", 1, 2, []string{"q"}},
+		{"", 0, -1, []string{}},
+	}
+	for _, tt := range tests {
+		got := c.MarksFor(tt.server, "work", tt.window, tt.pane)
+		if got == nil || !slices.Equal(got, tt.want) {
+			t.Errorf("MarksFor(%q, work, %d, %d) = %q, want %q", tt.server, tt.window, tt.pane, got, tt.want)
+		}
+	}
+}
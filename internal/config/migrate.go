@@ -0,0 +1,110 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// StateVersion is the version of the state.yaml layout this build writes.
+// Files without a version field are version 0.
+const StateVersion = 1
+
+// migrations[v] upgrades a decoded state.yaml from version v to v+1. Add one
+// for every change that older files would be read wrongly without, and bump
+// StateVersion.
+var migrations = []func(doc map[string]any) error{
+	migrateMarkPanes,
+}
+
+// migrateMarkPanes (0 → 1): session and window marks had pane 0, so a mark
+// on pane 0 of a window read as a mark on the window. They now have -1.
+func migrateMarkPanes(doc map[string]any) error {
+	marks, ok := doc["marks"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	for key, v := range marks {
+		mark, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("mark %q is not a mapping", key)
+		}
+		if pane, _ := mark["pane"].(int); pane == 0 {
+			mark["pane"] = -1
+		}
+	}
+	return nil
+}
+
+// decodeState parses state.yaml, upgrading it to StateVersion in memory. It
+// also returns the version the data was written with.
+func decodeState(data []byte) (*Config, int, error) {
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, 0, fmt.Errorf("parse config: %w", err)
+	}
+	if doc == nil {
+		doc = map[string]any{}
+	}
+	from := 0
+	if v, ok := doc["version"]; ok {
+		if from, ok = v.(int); !ok || from < 0 {
+			return nil, 0, fmt.Errorf("parse config: invalid version %v", v)
+		}
+	}
+	if from > StateVersion {
+		return nil, from, fmt.Errorf("state.yaml is version %d, newer than this tswitch reads (up to %d); upgrade tswitch", from, StateVersion)
+	}
+	for v := from; v < StateVersion; v++ {
+		if err := migrations[v](doc); err != nil {
+			return nil, from, fmt.Errorf("upgrade state from version %d: %w", v, err)
+		}
+	}
+	doc["version"] = StateVersion
+
+	upgraded, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, from, fmt.Errorf("upgrade state: %w", err)
+	}
+	cfg := Default() // start with defaults so missing fields are populated
+	if err := yaml.Unmarshal(upgraded, cfg); err != nil {
+		return nil, from, fmt.Errorf("parse config: %w", err)
+	}
+	cfg.fillDefaults()
+	return cfg, from, nil
+}
+
+// upgradeState rewrites an old state.yaml at path in the current layout,
+// after copying it to state.yaml.v<N>.bak.
+func upgradeState(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+	if _, from, err := decodeState(data); err != nil || from == StateVersion {
+		return err
+	}
+
+	unlock, err := lockState(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	// Another instance may have upgraded it meanwhile.
+	if data, err = os.ReadFile(path); err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+	cfg, from, err := decodeState(data)
+	if err != nil || from == StateVersion {
+		return err
+	}
+	if err := os.WriteFile(fmt.Sprintf("%s.v%d.bak", path, from), data, 0644); err != nil {
+		return fmt.Errorf("back up config: %w", err)
+	}
+	_, err = writeState(path, cfg)
+	return err
+}
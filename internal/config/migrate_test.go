@@ -0,0 +1,126 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestMigrateMarkPanes(t *testing.T) {
+	var doc map[string]any
+	err := yaml.Unmarshal([]byte(`
+marks:
+  s: {session: play, window: -1, pane: 0}
+  w: {session: work, window: 1, pane: 0}
+  p: {session: work, window: 0, pane: 2}
+  o: {session: work, window: 2}
+`), &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrateMarkPanes(doc); err != nil {
+		t.Fatal(err)
+	}
+	marks := doc["marks"].(map[string]any)
+	for key, want := range map[string]int{"s": -1, "w": -1, "p": 2, "o": -1} {
+		if got := marks[key].(map[string]any)["pane"]; got != want {
+			t.Errorf("mark %s: pane = %v, want %d", key, got, want)
+		}
+	}
+
+	if err := migrateMarkPanes(map[string]any{}); err != nil {
+		t.Errorf("no marks: %v", err)
+	}
+}
+
+// writeOld writes a state.yaml as an older tswitch would have.
+func writeOld(t *testing.T, data string) string {
+	t.Helper()
+	tempHome(t)
+	path, err := statePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadStateUpgrades(t *testing.T) {
+	const old = "marks:\n  w: {session: work, window: 1, pane: 0}\nsession_order: [work, play]\n"
+	path := writeOld(t, old)
+
+	cfg := load(t)
+	if cfg.Version != StateVersion {
+		t.Errorf("version = %d, want %d", cfg.Version, StateVersion)
+	}
+	if m := cfg.Marks["w"]; m.WindowIndex != 1 || m.PaneIndex != -1 {
+		t.Errorf("mark = %+v, want window 1 pane -1", m)
+	}
+	if len(cfg.SessionOrder) != 2 {
+		t.Errorf("session order = %v", cfg.SessionOrder)
+	}
+
+	backup, err := os.ReadFile(path + ".v0.bak")
+	if err != nil || string(backup) != old {
+		t.Errorf("backup = %q, %v", backup, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "version: 1") || !strings.Contains(string(data), "pane: -1") {
+		t.Errorf("state.yaml not upgraded:\n%s", data)
+	}
+
+	// Loading the upgraded file leaves it alone.
+	if err := os.Remove(path + ".v0.bak"); err != nil {
+		t.Fatal(err)
+	}
+	load(t)
+	if _, err := os.Stat(path + ".v0.bak"); !os.IsNotExist(err) {
+		t.Errorf("current state backed up again: %v", err)
+	}
+}
+
+func TestLoadStateNewerVersion(t *testing.T) {
+	const newer = "version: 99\nmarks: {}\n"
+	path := writeOld(t, newer)
+
+	_, err := LoadState()
+	if err == nil || !strings.Contains(err.Error(), "version 99") || !strings.Contains(err.Error(), "upgrade tswitch") {
+		t.Fatalf("err = %v, want a newer-version error", err)
+	}
+	if err := SaveState(Default()); err == nil {
+		t.Error("SaveState overwrote a newer state")
+	}
+	if data, _ := os.ReadFile(path); string(data) != newer {
+		t.Errorf("state.yaml changed:\n%s", data)
+	}
+}
+
+func TestReadStateLeavesOldFile(t *testing.T) {
+	const old = "marks:\n  w: {session: work, window: 1, pane: 0}\n"
+	path := writeOld(t, old)
+
+	cfg, err := ReadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := cfg.Marks["w"]; m.WindowIndex != 1 || m.PaneIndex != -1 {
+		t.Errorf("mark = %+v, want it upgraded in memory", m)
+	}
+	if data, _ := os.ReadFile(path); string(data) != old {
+		t.Errorf("state.yaml changed:\n%s", data)
+	}
+	if _, err := os.Stat(path + ".v0.bak"); !os.IsNotExist(err) {
+		t.Errorf("state backed up: %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// File names, and the directory tswitch kept both in before it followed the
+// XDG base directory spec. Files found there are still used.
+const (
+	appConfigFile = "tswitch-config.json"
+	stateFile     = "state.yaml"
+	legacyDir     = ".tswitch"
+)
+
+// Set by the --config and --state flags; they win over everything else.
+var configFlag, stateFlag string
+
+// SetConfigPath makes tswitch read its config from path (--config).
+func SetConfigPath(path string) { configFlag = path }
+
+// SetStatePath makes tswitch keep its state in path (--state).
+func SetStatePath(path string) { stateFlag = path }
+
+// Path is a file tswitch resolved, for `tswitch paths`.
+type Path struct {
+	File     string
+	Reason   string // why this file: the flag, variable or lookup that chose it
+	Exists   bool
+	Explicit bool // given with a flag or variable, rather than looked up
+}
+
+// candidate is a place a file may be, and what to call it.
+type candidate struct {
+	file, reason string
+}
+
+// AppConfigPath resolves tswitch-config.json: the --config flag,
+// $TSWITCH_CONFIG, then the first that exists of the binary's directory,
+// $XDG_CONFIG_HOME/tswitch and ~/.tswitch. When none exists it is the XDG
+// location, and defaults are used.
+func AppConfigPath() (Path, error) {
+	var search []candidate
+	if exe, err := os.Executable(); err == nil {
+		search = append(search, candidate{filepath.Join(filepath.Dir(exe), appConfigFile), "next to the tswitch binary"})
+	}
+	return resolve(configFlag, "--config", "TSWITCH_CONFIG", "XDG_CONFIG_HOME", ".config", appConfigFile, search)
+}
+
+// StatePath resolves state.yaml: the --state flag, $TSWITCH_STATE, then
+// $XDG_STATE_HOME/tswitch or ~/.tswitch, whichever has one. A new one is
+// created in the XDG location.
+func StatePath() (Path, error) {
+	return resolve(stateFlag, "--state", "TSWITCH_STATE", "XDG_STATE_HOME", filepath.Join(".local", "state"), stateFile, nil)
+}
+
+// resolve picks a file from flag, the environment variable env, the
+// candidates in search, the XDG directory from xdgEnv (home/xdgDefault when
+// unset) and the legacy directory, in that order.
+func resolve(flag, flagName, env, xdgEnv, xdgDefault, name string, search []candidate) (Path, error) {
+	explicit := candidate{flag, flagName + " flag"}
+	if flag == "" {
+		explicit = candidate{os.Getenv(env), "$" + env}
+	}
+	if explicit.file != "" {
+		p := stat(explicit)
+		p.Explicit = true
+		if !p.Exists {
+			p.Reason += "; not found"
+		}
+		return p, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return Path{}, fmt.Errorf("home dir: %w", err)
+	}
+	// Relative values are invalid per the spec and ignored.
+	xdg := candidate{filepath.Join(home, xdgDefault, "tswitch", name), "XDG default, $" + xdgEnv + " is unset"}
+	if dir := os.Getenv(xdgEnv); filepath.IsAbs(dir) {
+		xdg = candidate{filepath.Join(dir, "tswitch", name), "$" + xdgEnv}
+	}
+	search = append(search, xdg, candidate{filepath.Join(home, legacyDir, name), "legacy ~/" + legacyDir})
+	for _, c := range search {
+		if p := stat(c); p.Exists {
+			return p, nil
+		}
+	}
+	p := stat(xdg)
+	p.Reason += "; not found"
+	return p, nil
+}
+
+func stat(c candidate) Path {
+	_, err := os.Stat(c.file)
+	return Path{File: c.file, Reason: c.reason, Exists: err == nil}
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func touch(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStatePath(t *testing.T) {
+	home := tempHome(t)
+	xdg := filepath.Join(home, ".local", "state", "tswitch", "state.yaml")
+	legacy := filepath.Join(home, ".tswitch", "state.yaml")
+
+	check := func(name, file, reason string, exists bool) {
+		t.Helper()
+		p, err := StatePath()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.File != file || !strings.Contains(p.Reason, reason) || p.Exists != exists {
+			t.Errorf("%s: StatePath() = %+v, want %s (%s)", name, p, file, reason)
+		}
+	}
+
+	check("nothing yet", xdg, "not found", false)
+	touch(t, legacy)
+	check("legacy file", legacy, "legacy", true)
+	touch(t, xdg)
+	check("both", xdg, "XDG default", true)
+
+	t.Setenv("XDG_STATE_HOME", filepath.Join(home, "state"))
+	check("legacy under $XDG_STATE_HOME", legacy, "legacy", true)
+	t.Setenv("XDG_STATE_HOME", "relative")
+	check("relative $XDG_STATE_HOME", xdg, "XDG default", true)
+
+	t.Setenv("TSWITCH_STATE", filepath.Join(home, "env.yaml"))
+	check("env", filepath.Join(home, "env.yaml"), "$TSWITCH_STATE", false)
+	SetStatePath(filepath.Join(home, "flag.yaml"))
+	t.Cleanup(func() { SetStatePath("") })
+	check("flag", filepath.Join(home, "flag.yaml"), "--state", false)
+}
+
+func TestLoadAppConfigPath(t *testing.T) {
+	home := tempHome(t)
+	xdg := filepath.Join(home, "config")
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	if err := os.MkdirAll(filepath.Join(xdg, "tswitch"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	err := os.WriteFile(filepath.Join(xdg, "tswitch", "tswitch-config.json"), []byte(`{"leader": ","}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err := LoadAppConfig(); err != nil || cfg.Leader != "," {
+		t.Errorf("LoadAppConfig() = %+v, %v; want the XDG config", cfg, err)
+	}
+
+	// A config named explicitly must exist.
+	t.Setenv("TSWITCH_CONFIG", filepath.Join(home, "missing.json"))
+	if cfg, err := LoadAppConfig(); err == nil || cfg == nil {
+		t.Errorf("LoadAppConfig() = %+v, %v; want defaults and an error", cfg, err)
+	}
+}
@@ -0,0 +1,267 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"syscall"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Several tswitch instances share state.yaml: popups on two clients, or the
+// TUI and a `tswitch mark` from a key binding. Each one remembers the state
+// it last read or wrote (its base). Saving locks the file, re-reads it and
+// keeps the other instances' changes to everything this one left as it was
+// in its base; the result is written to a temporary file and renamed over
+// state.yaml, so readers never see a half-written file.
+
+// loaded is what a Config was last read from or written to.
+type loaded struct {
+	base *Config     // the state at that time
+	info os.FileInfo // state.yaml at that time; nil if it didn't exist
+}
+
+func statePath() (string, error) {
+	p, err := StatePath()
+	return p.File, err
+}
+
+// readState reads the state at path, with defaults when it doesn't exist.
+// Older versions are upgraded in memory.
+func readState(path string) (*Config, os.FileInfo, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Default(), nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("read config: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read config: %w", err)
+	}
+	cfg, _, err := decodeState(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, info, nil
+}
+
+// writeState replaces the file at path with cfg in one rename.
+func writeState(path string, cfg *Config) (os.FileInfo, error) {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("marshal config: %w", err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".state-*.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("write config: %w", err)
+	}
+	defer os.Remove(f.Name()) // fails harmlessly once renamed
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Chmod(0644)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("write config: %w", err)
+	}
+	return os.Stat(path)
+}
+
+// lockState takes an exclusive lock on path's lock file and returns the
+// function releasing it. The lock file is separate because state.yaml
+// itself is replaced on every write.
+func lockState(path string) (unlock func(), err error) {
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("lock config: %w", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("lock config: %w", err)
+	}
+	return func() { f.Close() }, nil // closing releases the lock
+}
+
+// remember records cfg as the state last read or written.
+func (c *Config) remember(info os.FileInfo) {
+	c.loaded = loaded{base: c.clone(), info: info}
+}
+
+// baseState returns the state c was loaded from; defaults if it wasn't.
+func (c *Config) baseState() *Config {
+	if c.loaded.base == nil {
+		return Default()
+	}
+	return c.loaded.base
+}
+
+// clone returns a deep copy of c's saved fields.
+func (c *Config) clone() *Config {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		panic(err) // Config always marshals
+	}
+	out := &Config{}
+	if err := yaml.Unmarshal(data, out); err != nil {
+		panic(err)
+	}
+	return out
+}
+
+// Reload merges changes another instance saved since c was last loaded or
+// saved into c, and reports whether there were any. Unsaved changes in c win.
+func (c *Config) Reload() (bool, error) {
+	path, err := statePath()
+	if err != nil {
+		return false, err
+	}
+	info, err := os.Stat(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	if sameFile(info, c.loaded.info) {
+		return false, nil
+	}
+	disk, info, err := readState(path)
+	if err != nil {
+		return false, err
+	}
+	*c = *merge(c.baseState(), c, disk)
+	c.remember(info)
+	return true, nil
+}
+
+// sameFile reports whether a and b are the same version of a file. Every
+// save creates a new file, so a rename also shows up as a change.
+func sameFile(a, b os.FileInfo) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return os.SameFile(a, b) && a.ModTime().Equal(b.ModTime()) && a.Size() == b.Size()
+}
+
+// merge returns disk with the changes from base to local applied: map
+// entries (a mark, a tag, one session's window order, a dialog's history)
+// and settings one by one, lists such as a tag's sessions element by element,
+// other fields as a whole.
+func merge(base, local, disk *Config) *Config {
+	out := disk.clone()
+	b, l, o := reflect.ValueOf(base).Elem(), reflect.ValueOf(local).Elem(), reflect.ValueOf(out).Elem()
+	for i := 0; i < o.NumField(); i++ {
+		if !o.Type().Field(i).IsExported() {
+			continue
+		}
+		mergeField(b.Field(i), l.Field(i), o.Field(i))
+	}
+	return out
+}
+
+func mergeField(base, local, out reflect.Value) {
+	switch out.Kind() {
+	case reflect.Map:
+		for _, keys := range [][]reflect.Value{base.MapKeys(), local.MapKeys()} {
+			for _, k := range keys {
+				lv := local.MapIndex(k)
+				if !changed(base.MapIndex(k), lv) {
+					continue
+				}
+				if out.IsNil() {
+					out.Set(reflect.MakeMap(out.Type()))
+				}
+				if out.Type().Elem().Kind() == reflect.Slice {
+					// A list deleted here is kept if another instance
+					// added to it.
+					merged := mergeList(base.MapIndex(k), lv, out.MapIndex(k), out.Type().Elem())
+					if lv.IsValid() || merged.Len() > 0 {
+						lv = merged
+					}
+				}
+				out.SetMapIndex(k, lv) // the zero Value deletes
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < out.NumField(); i++ {
+			mergeField(base.Field(i), local.Field(i), out.Field(i))
+		}
+	case reflect.Slice:
+		if changed(base, local) {
+			out.Set(mergeList(base, local, out, out.Type()))
+		}
+	default:
+		if changed(base, local) {
+			out.Set(local)
+		}
+	}
+}
+
+// mergeList applies the elements local added to or removed from base to
+// disk, a list of type typ. The list keeps local's order if local reordered
+// it and disk's otherwise; additions from the other side go at the end.
+func mergeList(base, local, disk reflect.Value, typ reflect.Type) reflect.Value {
+	b, first, second := elems(base), elems(disk), elems(local)
+	if reordered(b, second) {
+		first, second = second, first
+	}
+	out := reflect.MakeSlice(typ, 0, len(first)+len(second))
+	for _, e := range first {
+		if slices.Contains(b, e) && !slices.Contains(second, e) {
+			continue // removed by the other side
+		}
+		out = reflect.Append(out, reflect.ValueOf(e))
+	}
+	for _, e := range second {
+		if !slices.Contains(b, e) && !slices.Contains(first, e) {
+			out = reflect.Append(out, reflect.ValueOf(e))
+		}
+	}
+	return out
+}
+
+// elems returns the elements of a list; none if v is the zero Value.
+func elems(v reflect.Value) []any {
+	if !v.IsValid() {
+		return nil
+	}
+	out := make([]any, v.Len())
+	for i := range out {
+		out[i] = v.Index(i).Interface()
+	}
+	return out
+}
+
+// reordered reports whether the elements list shares with base are in a
+// different order than in base.
+func reordered(base, list []any) bool {
+	var a, b []any
+	for _, e := range base {
+		if slices.Contains(list, e) {
+			a = append(a, e)
+		}
+	}
+	for _, e := range list {
+		if slices.Contains(base, e) {
+			b = append(b, e)
+		}
+	}
+	return !slices.Equal(a, b)
+}
+
+func changed(base, local reflect.Value) bool {
+	if !base.IsValid() || !local.IsValid() {
+		return base.IsValid() != local.IsValid()
+	}
+	return !reflect.DeepEqual(base.Interface(), local.Interface())
+}
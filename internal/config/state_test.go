@@ -0,0 +1,193 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// tempHome points the state and config lookup at an empty home directory.
+func tempHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, env := range []string{"XDG_CONFIG_HOME", "XDG_STATE_HOME", "TSWITCH_CONFIG", "TSWITCH_STATE"} {
+		t.Setenv(env, "")
+	}
+	return home
+}
+
+// twoInstances loads the state twice, like two tswitch popups.
+func twoInstances(t *testing.T) (a, b *Config) {
+	t.Helper()
+	tempHome(t)
+	a, err := LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err = LoadState(); err != nil {
+		t.Fatal(err)
+	}
+	return a, b
+}
+
+func save(t *testing.T, cfg *Config) {
+	t.Helper()
+	if err := SaveState(cfg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func load(t *testing.T) *Config {
+	t.Helper()
+	cfg, err := LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestSaveStateMerges(t *testing.T) {
+	a, b := twoInstances(t)
+	a.SetMark("w", "", "work", 0, -1)
+	a.AddSessionTag("", "work", "dev")
+	save(t, a)
+
+	b.SetMark("p", "", "play", -1, -1)
+	b.SetSessionOrder([]string{"play", "work"})
+	b.Settings.GroupByServer = true
+	save(t, b)
+
+	got := load(t)
+	if !got.HasMark("w") || !got.HasMark("p") {
+		t.Errorf("marks = %v, want w and p", got.Marks)
+	}
+	if !slices.Equal(got.Tags["dev"], []string{"work"}) {
+		t.Errorf("tags = %v, want dev: [work]", got.Tags)
+	}
+	if !slices.Equal(got.SessionOrder, []string{"play", "work"}) || !got.Settings.GroupByServer {
+		t.Errorf("order %v, group by server %v, want b's", got.SessionOrder, got.Settings.GroupByServer)
+	}
+	if !b.HasMark("w") {
+		t.Error("saving didn't merge a's mark into b")
+	}
+
+	// A deletion is a change too, and the last save of an entry wins.
+	a.DeleteMark("w")
+	a.SetMark("p", "", "work", 1, -1)
+	save(t, a)
+	b.AddHistory("rename_session", "x")
+	save(t, b)
+	got = load(t)
+	if got.HasMark("w") || got.Marks["p"].SessionName != "work" {
+		t.Errorf("marks = %v, want only p on work", got.Marks)
+	}
+	if !got.Settings.GroupByServer || len(got.History["rename_session"]) != 1 {
+		t.Errorf("state = %+v, want b's settings and history kept", got)
+	}
+}
+
+func TestSaveStateMergesLists(t *testing.T) {
+	a, b := twoInstances(t)
+	a.AddSessionTag("", "work", "dev")
+	a.AddSessionTag("", "play", "dev")
+	a.SetSessionOrder([]string{"work", "play"})
+	a.SetWindowOrder("work", []int{0, 1})
+	save(t, a)
+	if _, err := b.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both add a session to the same tag; one also drops one.
+	a.AddSessionTag("", "api", "dev")
+	a.RemoveSessionTag("", "play", "dev")
+	a.SetWindowOrder("work", []int{0, 1, 2})
+	save(t, a)
+	b.AddSessionTag("", "web", "dev")
+	b.SetSessionOrder([]string{"play", "work", "web"})
+	save(t, b)
+
+	got := load(t)
+	if want := []string{"work", "api", "web"}; !slices.Equal(got.Tags["dev"], want) {
+		t.Errorf("dev = %q, want %q", got.Tags["dev"], want)
+	}
+	if want := []string{"play", "work", "web"}; !slices.Equal(got.SessionOrder, want) {
+		t.Errorf("session order = %q, want b's %q", got.SessionOrder, want)
+	}
+	if want := []int{0, 1, 2}; !slices.Equal(got.WindowOrder["work"], want) {
+		t.Errorf("window order = %v, want a's %v", got.WindowOrder["work"], want)
+	}
+
+	// A tag emptied here keeps what another instance added to it.
+	a, b = load(t), load(t)
+	for _, sess := range []string{"work", "api", "web"} {
+		a.RemoveSessionTag("", sess, "dev")
+	}
+	b.AddSessionTag("", "db", "dev")
+	save(t, b)
+	save(t, a)
+	if got := load(t).Tags["dev"]; !slices.Equal(got, []string{"db"}) {
+		t.Errorf("dev = %q, want [db]", got)
+	}
+}
+
+func TestSaveStateConcurrent(t *testing.T) {
+	tempHome(t)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cfg, err := LoadState()
+			if err == nil {
+				cfg.SetMark(fmt.Sprint(i), "", "s", i, -1)
+				err = SaveState(cfg)
+			}
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := load(t); len(got.Marks) != 10 {
+		t.Errorf("marks = %v, want all 10", got.Marks)
+	}
+	path, _ := statePath()
+	dir := filepath.Dir(path)
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if e.Name() != "state.yaml" && e.Name() != "state.yaml.lock" {
+			t.Errorf("left %s behind", filepath.Join(dir, e.Name()))
+		}
+	}
+}
+
+func TestReload(t *testing.T) {
+	a, b := twoInstances(t)
+	if changed, err := a.Reload(); changed || err != nil {
+		t.Fatalf("Reload() = %v, %v before any save", changed, err)
+	}
+
+	b.SetMark("w", "", "work", 0, -1)
+	save(t, b)
+	a.SetWindowOrder("work", []int{1, 0}) // unsaved
+	if changed, err := a.Reload(); !changed || err != nil {
+		t.Fatalf("Reload() = %v, %v after b saved", changed, err)
+	}
+	if !a.HasMark("w") || !slices.Equal(a.WindowOrder["work"], []int{1, 0}) {
+		t.Errorf("a = %+v, want b's mark and its own window order", a)
+	}
+	if changed, _ := a.Reload(); changed {
+		t.Error("Reload() reported a change twice")
+	}
+
+	// a's own save is not a change from another instance.
+	save(t, a)
+	if changed, _ := a.Reload(); changed {
+		t.Error("Reload() reported a's own save")
+	}
+}
@@ -0,0 +1,225 @@
+package keys
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Info returns the metadata of action.
+func Info(action Action) ActionInfo {
+	return actionInfo[action]
+}
+
+// ValidIn reports whether the action does something in scope.
+func (info ActionInfo) ValidIn(scope Scope) bool {
+	for _, s := range info.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Entry is one line of the key hint popup: a key and what it does.
+type Entry struct {
+	Keys   string // display form, e.g. "k/↑" or "g"
+	Desc   string
+	Prefix bool // the key starts longer sequences
+}
+
+// Continuations lists the keys that may follow prefix in scope; an empty
+// prefix lists the top level of the keymap. Actions that do nothing in scope
+// are left out. Keys bound to the same action
+// are merged into one entry, in action-table order. Keys that start longer
+// sequences follow, described by the actions they lead to.
+func Continuations(scope Scope, prefix []string) []Entry {
+	n := keymaps[scope]
+	for _, key := range prefix {
+		if n = n.next[key]; n == nil {
+			return nil
+		}
+	}
+
+	byAction := make(map[Action][]string)
+	var prefixes []string
+	for key, child := range n.next {
+		if actionInfo[child.action].ValidIn(scope) {
+			byAction[child.action] = append(byAction[child.action], key)
+		}
+		if len(child.next) > 0 && groupLabel(scope, child) != "" {
+			prefixes = append(prefixes, key)
+		}
+	}
+
+	var entries []Entry
+	for _, info := range actionTable {
+		ks, ok := byAction[info.Action]
+		if !ok {
+			continue
+		}
+		sortKeys(ks)
+		for i, k := range ks {
+			ks[i] = displayKey(k)
+		}
+		entries = append(entries, Entry{Keys: strings.Join(ks, "/"), Desc: info.Desc})
+	}
+
+	sort.Strings(prefixes)
+	for _, key := range prefixes {
+		entries = append(entries, Entry{Keys: displayKey(key), Desc: "+" + groupLabel(scope, n.next[key]), Prefix: true})
+	}
+	return entries
+}
+
+// groupLabel names the actions valid in scope reachable below n, e.g.
+// "first, servers".
+func groupLabel(scope Scope, n *node) string {
+	var labels []string
+	seen := make(map[string]bool)
+	var walk func(*node)
+	walk = func(n *node) {
+		for _, child := range n.next {
+			if info := actionInfo[child.action]; info.ValidIn(scope) {
+				if l := info.Label; !seen[l] {
+					seen[l] = true
+					labels = append(labels, l)
+				}
+			}
+			walk(child)
+		}
+	}
+	walk(n)
+	sort.Strings(labels)
+	if len(labels) > 3 {
+		return fmt.Sprintf("%s, … (%d)", strings.Join(labels[:3], ", "), len(labels))
+	}
+	return strings.Join(labels, ", ")
+}
+
+// Hint is one status-bar hint, e.g. {"hjkl", "nav"}.
+type Hint struct {
+	Keys  string
+	Label string
+}
+
+// Hints returns the status-bar hints for scope: the preferred binding of
+// every hinted action valid there, with consecutive actions sharing a label
+// merged ("hjkl:nav", "enter/space:switch"). Unbound actions are left out.
+// If only is given, the hints are restricted to those actions.
+func Hints(scope Scope, only ...Action) []Hint {
+	bound := bindingsByAction(scope)
+
+	var hints []Hint
+	var group []string // preferred sequences of the current label
+	flush := func(label string) {
+		if len(group) > 0 {
+			hints = append(hints, Hint{Keys: joinHintKeys(group), Label: label})
+			group = nil
+		}
+	}
+	label := ""
+	for _, info := range actionTable {
+		if !info.Hint || !info.ValidIn(scope) || (len(only) > 0 && !contains(only, info.Action)) {
+			continue
+		}
+		seqs := bound[info.Action]
+		if len(seqs) == 0 {
+			continue
+		}
+		if info.Label != label {
+			flush(label)
+			label = info.Label
+		}
+		group = append(group, FormatSequence(seqs[0]))
+	}
+	flush(label)
+	return hints
+}
+
+func contains(actions []Action, a Action) bool {
+	for _, x := range actions {
+		if x == a {
+			return true
+		}
+	}
+	return false
+}
+
+// bindingsByAction groups the effective bindings of scope by action, each
+// action's sequences most convenient first.
+func bindingsByAction(scope Scope) map[Action][][]string {
+	out := make(map[Action][][]string)
+	for _, b := range Bindings(scope) {
+		out[b.Action] = append(out[b.Action], b.Keys)
+	}
+	return out
+}
+
+// lessSeq orders sequences by length, then key by key with printable
+// characters before named keys ("h" before "left").
+func lessSeq(a, b []string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return lessKey(a[i], b[i])
+		}
+	}
+	return false
+}
+
+func lessKey(a, b string) bool {
+	ca, cb := utf8.RuneCountInString(a) == 1, utf8.RuneCountInString(b) == 1
+	if ca != cb {
+		return ca
+	}
+	return a < b
+}
+
+func sortKeys(ks []string) {
+	sort.Slice(ks, func(i, j int) bool { return lessKey(ks[i], ks[j]) })
+}
+
+// joinHintKeys merges the keys of a hint group: single characters are run
+// together ("hjkl"), anything longer is separated by slashes.
+func joinHintKeys(ks []string) string {
+	sep := ""
+	for _, k := range ks {
+		if utf8.RuneCountInString(k) != 1 {
+			sep = "/"
+		}
+	}
+	return strings.Join(ks, sep)
+}
+
+// displayKey renders a single key for hints and the pending indicator.
+func displayKey(k string) string {
+	switch k {
+	case leader:
+		return "<leader>"
+	case " ":
+		return "space"
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	case "left":
+		return "←"
+	case "right":
+		return "→"
+	}
+	return k
+}
+
+// KeyFor returns the display form of the preferred binding of action in
+// scope, or "" if it is unbound.
+func KeyFor(scope Scope, action Action) string {
+	seqs := bindingsByAction(scope)[action]
+	if len(seqs) == 0 {
+		return ""
+	}
+	return FormatSequence(seqs[0])
+}
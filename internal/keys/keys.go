@@ -1,5 +1,12 @@
 package keys
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
 // Action represents a user action triggered by a key press.
 type Action int
 
@@ -11,6 +18,8 @@ const (
 	ActionMoveDown
 	ActionMoveLeft
 	ActionMoveRight
+	ActionFocusFirst // home
+	ActionFocusLast  // end
 
 	// Selection
 	ActionConfirm      // o - drill into child view
@@ -20,14 +29,17 @@ const (
 
 	// Marks
 	ActionStartMark // m - enter marking mode
+	ActionJumpMark  // ' - prefix for mark keys (e.g. 'a), frees single keys
 
 	// Management (future)
-	ActionNew    // n
-	ActionRename // r
-	ActionKill   // d - delete (moved from x)
-	ActionCut    // x - cut window/pane to clipboard
-	ActionPaste  // p - paste clipboard onto focused destination
-	ActionTag    // t
+	ActionNew       // n
+	ActionRename    // r
+	ActionKill      // d - delete (moved from x)
+	ActionCut       // x - cut window/pane to clipboard
+	ActionCopy      // unbound - copy window to clipboard; paste links it
+	ActionPaste     // p - paste clipboard onto focused destination
+	ActionTag       // t
+	ActionBroadcast // unbound - send a command to several panes
 
 	// Reorder
 	ActionReorderUp
@@ -35,44 +47,81 @@ const (
 	ActionReorderLeft
 	ActionReorderRight
 
+	// Panes
+	ActionSplitRight  // % - split focused pane side by side
+	ActionSplitDown   // " - split focused pane top and bottom
+	ActionBreakPane   // ! - move pane to a window of its own
+	ActionZoomPane    // unbound
+	ActionResizeLeft  // alt+h
+	ActionResizeDown  // alt+j
+	ActionResizeUp    // alt+k
+	ActionResizeRight // alt+l
+	ActionCycleLayout // = - preview layouts, enter applies
+
 	// Browse
 	ActionBrowseDirs // f
 
 	// UI
 	ActionTogglePreview // tab
+	ActionToggleGroup   // unbound - group sessions by server
 	ActionToggleHelp    // ?
+	ActionMenu          // unbound - action menu for the focused card (or right-click)
 	ActionFilter        // /
 	ActionQuit          // q
+
+	// Filter and dialog input
+	ActionAccept // enter - keep filter / submit dialog
+	ActionCancel // esc - clear filter / close dialog
+	ActionYes    // y - confirm dialog
+	ActionNo     // n - reject dialog
 )
 
-// reservedKeys are keys that cannot be used as mark assignments.
-// Kept as a map for O(1) lookup.
-var reservedKeys = map[string]bool{
-	"esc": true, "enter": true, " ": true, "tab": true,
-	"up": true, "down": true, "left": true, "right": true,
-	"j": true, "k": true, "h": true, "l": true,
-	"?": true, "q": true, "m": true, "/": true,
-	"f": true, "o": true,
-	"n": true, "r": true, "d": true, "x": true, "p": true, "t": true,
-	"H": true, "J": true, "K": true, "L": true,
+// Scope selects the keymap a key press is resolved in. Each navigation
+// level has its own keymap, as do the filter prompt and dialogs.
+type Scope int
+
+const (
+	ScopeSessions Scope = iota
+	ScopeWindows
+	ScopePanes
+	ScopeFilter
+	ScopeDialog
+)
+
+// scopeNames maps scopes to their config-file names.
+var scopeNames = map[Scope]string{
+	ScopeSessions: "sessions",
+	ScopeWindows:  "windows",
+	ScopePanes:    "panes",
+	ScopeFilter:   "filter",
+	ScopeDialog:   "dialog",
 }
 
-// defaultKeymap maps key strings to actions.
-var defaultKeymap = map[string]Action{
+// gridScopes are the navigation levels; the top-level "keys" config applies
+// to all of them.
+var gridScopes = []Scope{ScopeSessions, ScopeWindows, ScopePanes}
+
+// defaultGridKeymap maps key sequences to actions in every grid scope.
+// Sequences are space-separated key strings, as in the config file. Every
+// unbound key is free for marks, so new actions get no single-letter
+// default; users bind them in the config.
+var defaultGridKeymap = map[string]Action{
 	"up": ActionMoveUp, "k": ActionMoveUp,
 	"down": ActionMoveDown, "j": ActionMoveDown,
 	"left": ActionMoveLeft, "h": ActionMoveLeft,
 	"right": ActionMoveRight, "l": ActionMoveRight,
+	"home": ActionFocusFirst, "end": ActionFocusLast,
 
 	"K": ActionReorderUp, "J": ActionReorderDown,
 	"H": ActionReorderLeft, "L": ActionReorderRight,
 
 	"o":     ActionConfirm,
 	"enter": ActionDirectSwitch,
-	" ":     ActionQuickSwap,
+	"space": ActionQuickSwap,
 	"esc":   ActionBack,
 
 	"m": ActionStartMark,
+	"'": ActionJumpMark,
 
 	"f": ActionBrowseDirs,
 	"n": ActionNew,
@@ -88,77 +137,422 @@ var defaultKeymap = map[string]Action{
 	"q":   ActionQuit,
 }
 
-// actionToName maps actions to their config-file names.
-var actionToName = map[Action]string{
-	ActionMoveUp:         "move_up",
-	ActionMoveDown:       "move_down",
-	ActionMoveLeft:       "move_left",
-	ActionMoveRight:      "move_right",
-	ActionConfirm:        "confirm",
-	ActionQuickSwap:      "quick_swap",
-	ActionDirectSwitch:   "direct_switch",
-	ActionBack:           "back",
-	ActionStartMark:      "start_mark",
-	ActionNew:            "new",
-	ActionRename:         "rename",
-	ActionKill:           "kill",
-	ActionCut:            "cut",
-	ActionPaste:          "paste",
-	ActionTag:            "tag",
-	ActionReorderUp:      "reorder_up",
-	ActionReorderDown:    "reorder_down",
-	ActionReorderLeft:    "reorder_left",
-	ActionReorderRight:   "reorder_right",
-	ActionBrowseDirs:    "browse_dirs",
-	ActionTogglePreview:  "toggle_preview",
-	ActionToggleHelp:     "toggle_help",
-	ActionFilter:         "filter",
-	ActionQuit:           "quit",
-}
-
-// nameToAction is the reverse of actionToName.
+// defaultPaneKeymap adds pane management keys to the pane grid, after tmux's
+// own bindings where there is one.
+var defaultPaneKeymap = map[string]Action{
+	"%": ActionSplitRight, `"`: ActionSplitDown,
+	"!": ActionBreakPane,
+	"=": ActionCycleLayout,
+
+	"alt+h": ActionResizeLeft, "alt+j": ActionResizeDown,
+	"alt+k": ActionResizeUp, "alt+l": ActionResizeRight,
+}
+
+// defaultFilterKeymap applies while typing a search term. Printable keys
+// always edit the term; grid actions move through the matches.
+var defaultFilterKeymap = map[string]Action{
+	"enter": ActionAccept,
+	"esc":   ActionCancel,
+	"up":    ActionMoveUp, "down": ActionMoveDown,
+	"left": ActionMoveLeft, "right": ActionMoveRight,
+}
+
+// defaultDialogKeymap applies to dialogs. In text-input dialogs printable
+// keys always edit the input, so only the non-printable bindings apply.
+var defaultDialogKeymap = map[string]Action{
+	"enter": ActionAccept,
+	"esc":   ActionCancel,
+	"y":     ActionYes,
+	"n":     ActionNo,
+	"left":  ActionMoveLeft, "h": ActionMoveLeft,
+	"right": ActionMoveRight, "l": ActionMoveRight,
+	"up": ActionMoveUp, "down": ActionMoveDown,
+	"tab": ActionMoveDown, "shift+tab": ActionMoveUp,
+}
+
+// ActionInfo describes an action for key hints and the help popup.
+type ActionInfo struct {
+	Action Action
+	Name   string  // config-file name
+	Label  string  // short status-bar label; consecutive actions sharing one are merged
+	Desc   string  // one-line description for the help popup
+	Scopes []Scope // modes where the action does something
+	Hint   bool    // listed in the status bar
+}
+
+var (
+	allGrid         = gridScopes
+	gridAndFilter   = []Scope{ScopeSessions, ScopeWindows, ScopePanes, ScopeFilter}
+	sessionsWins    = []Scope{ScopeSessions, ScopeWindows}
+	windowsPanes    = []Scope{ScopeWindows, ScopePanes}
+	panesOnly       = []Scope{ScopePanes}
+	filterAndDialog = []Scope{ScopeFilter, ScopeDialog}
+)
+
+// actionTable lists every action in display order. Directions run h, j, k, l
+// so merged hints read "hjkl".
+var actionTable = []ActionInfo{
+	{ActionMoveLeft, "move_left", "nav", "Move left", append(gridAndFilter, ScopeDialog), true},
+	{ActionMoveDown, "move_down", "nav", "Move down", append(gridAndFilter, ScopeDialog), true},
+	{ActionMoveUp, "move_up", "nav", "Move up", append(gridAndFilter, ScopeDialog), true},
+	{ActionMoveRight, "move_right", "nav", "Move right", append(gridAndFilter, ScopeDialog), true},
+	{ActionFocusFirst, "focus_first", "first", "First card", gridAndFilter, false},
+	{ActionFocusLast, "focus_last", "last", "Last card", gridAndFilter, false},
+	{ActionReorderLeft, "reorder_left", "reorder", "Move item left", allGrid, true},
+	{ActionReorderDown, "reorder_down", "reorder", "Move item down", allGrid, true},
+	{ActionReorderUp, "reorder_up", "reorder", "Move item up", allGrid, true},
+	{ActionReorderRight, "reorder_right", "reorder", "Move item right", allGrid, true},
+	{ActionConfirm, "confirm", "open", "Drill into windows / panes", sessionsWins, true},
+	{ActionDirectSwitch, "direct_switch", "switch", "Switch to focused item", allGrid, true},
+	{ActionQuickSwap, "quick_swap", "switch", "Switch to focused item", allGrid, true},
+	{ActionTogglePreview, "toggle_preview", "preview", "Toggle preview mode", allGrid, true},
+	{ActionFilter, "filter", "search", "Search (fuzzy filter)", allGrid, true},
+	{ActionNew, "new", "new", "New session / window", sessionsWins, true},
+	{ActionRename, "rename", "rename", "Rename session / window", sessionsWins, true},
+	{ActionKill, "kill", "kill", "Kill session / window / pane", allGrid, true},
+	{ActionCut, "cut", "cut", "Cut window / pane (again to clear)", windowsPanes, true},
+	{ActionCopy, "copy", "copy", "Copy window to link it into a session", []Scope{ScopeWindows}, true},
+	{ActionPaste, "paste", "paste", "Paste cut / copied window or pane onto focus", sessionsWins, true},
+	{ActionTag, "tag", "tag", "Tag focused session", []Scope{ScopeSessions}, false},
+	{ActionSplitRight, "split_right", "split", "Split pane side by side", panesOnly, true},
+	{ActionSplitDown, "split_down", "split", "Split pane top and bottom", panesOnly, true},
+	{ActionBreakPane, "break_pane", "break", "Move pane to its own window", panesOnly, false},
+	{ActionZoomPane, "zoom_pane", "zoom", "Toggle pane zoom", panesOnly, true},
+	{ActionResizeLeft, "resize_left", "resize", "Resize pane left", panesOnly, false},
+	{ActionResizeDown, "resize_down", "resize", "Resize pane down", panesOnly, false},
+	{ActionResizeUp, "resize_up", "resize", "Resize pane up", panesOnly, false},
+	{ActionResizeRight, "resize_right", "resize", "Resize pane right", panesOnly, false},
+	{ActionCycleLayout, "cycle_layout", "layout", "Cycle window layouts (enter applies)", panesOnly, true},
+	{ActionBroadcast, "broadcast", "broadcast", "Send a command to panes (pane / window / session / tag)", allGrid, false},
+	{ActionStartMark, "start_mark", "mark", "Mark focused item (then a key)", allGrid, true},
+	{ActionJumpMark, "jump_mark", "jump", "Jump to mark (then its key)", allGrid, true},
+	{ActionBrowseDirs, "browse_dirs", "browse", "Browse dirs (fzf)", allGrid, true},
+	{ActionToggleGroup, "toggle_group", "servers", "Group sessions by server", []Scope{ScopeSessions}, true},
+	{ActionBack, "back", "back", "Go back / quit", allGrid, true},
+	{ActionMenu, "menu", "actions", "Action menu for focused card", allGrid, true},
+	{ActionToggleHelp, "toggle_help", "help", "Show key hints", allGrid, true},
+	{ActionQuit, "quit", "quit", "Quit", allGrid, true},
+	{ActionAccept, "accept", "accept", "Accept", filterAndDialog, true},
+	{ActionCancel, "cancel", "cancel", "Cancel", filterAndDialog, true},
+	{ActionYes, "yes", "yes", "Yes", []Scope{ScopeDialog}, true},
+	{ActionNo, "no", "no", "No", []Scope{ScopeDialog}, true},
+}
+
+// actionInfo indexes actionTable by action.
+var actionInfo map[Action]ActionInfo
+
+// nameToAction maps config-file names to actions.
 var nameToAction map[string]Action
 
+// node is a trie node of key sequences. A node with children is a prefix;
+// if it also has an action, that action fires when the sequence times out.
+type node struct {
+	action Action
+	source string // where the binding came from, see Binding.Source
+	next   map[string]*node
+}
+
+// keymaps holds the active trie per scope.
+var keymaps map[Scope]*node
+
+// leader replaces <leader> in configured sequences.
+var leader = `\`
+
+// timeout is how long a pending sequence waits for its next key.
+var timeout = time.Second
+
 func init() {
-	nameToAction = make(map[string]Action, len(actionToName))
-	for a, n := range actionToName {
-		nameToAction[n] = a
+	nameToAction = make(map[string]Action, len(actionTable))
+	actionInfo = make(map[Action]ActionInfo, len(actionTable))
+	for _, info := range actionTable {
+		nameToAction[info.Name] = info.Action
+		actionInfo[info.Action] = info
 	}
+
+	reset()
 }
 
-// ApplyOverrides adds user-specified key bindings on top of the defaults.
-// Each entry maps an action name (e.g. "quit") to a key string (e.g. "Q").
-// If the new key is already bound to a different action, that conflicting
-// binding is removed. Existing bindings for the same action are preserved
-// (e.g. arrow keys remain alongside hjkl overrides).
-func ApplyOverrides(overrides map[string]string) {
-	for actionName, newKey := range overrides {
-		action, ok := nameToAction[actionName]
+// reset restores the default keymaps, leader and timeout and forgets the
+// conflicts.
+func reset() {
+	leader = `\`
+	timeout = time.Second
+	conflicts = nil
+	keymaps = make(map[Scope]*node)
+	for _, scope := range gridScopes {
+		keymaps[scope] = buildTrie(defaultGridKeymap)
+	}
+	for s, action := range defaultPaneKeymap {
+		seq, _ := ParseSequence(s)
+		keymaps[ScopePanes].bind(seq, action, SourceDefault)
+	}
+	keymaps[ScopeFilter] = buildTrie(defaultFilterKeymap)
+	keymaps[ScopeDialog] = buildTrie(defaultDialogKeymap)
+}
+
+func buildTrie(bindings map[string]Action) *node {
+	root := &node{}
+	for s, action := range bindings {
+		seq, _ := ParseSequence(s)
+		root.bind(seq, action, SourceDefault)
+	}
+	return root
+}
+
+// bind sets the action for seq, replacing any action already bound to it,
+// and returns the conflicts this causes.
+func (n *node) bind(seq []string, action Action, source string) []Conflict {
+	var conflicts []Conflict
+	for i, key := range seq {
+		if n.next == nil {
+			n.next = make(map[string]*node)
+		}
+		child, ok := n.next[key]
 		if !ok {
-			continue // unknown action name, skip
+			child = &node{}
+			n.next[key] = child
 		}
+		n = child
+		if i < len(seq)-1 && n.action != ActionNone && len(n.next) == 0 {
+			// A complete binding becomes a prefix: it now fires on timeout.
+			conflicts = append(conflicts, Conflict{
+				Winner: Binding{Keys: seq, Action: action, Source: source},
+				Loser:  Binding{Keys: seq[:i+1], Action: n.action, Source: n.source},
+				Prefix: true,
+			})
+		}
+	}
+	if n.action != ActionNone && n.action != action {
+		conflicts = append(conflicts, Conflict{
+			Winner: Binding{Keys: seq, Action: action, Source: source},
+			Loser:  Binding{Keys: seq, Action: n.action, Source: n.source},
+		})
+	} else if n.action == ActionNone && len(n.next) > 0 {
+		// seq starts longer bindings, so it can only fire on timeout.
+		conflicts = append(conflicts, Conflict{
+			Winner: Binding{Keys: seq, Action: action, Source: source},
+			Loser:  n.firstBinding(seq),
+			Prefix: true,
+		})
+	}
+	n.action, n.source = action, source
+	return conflicts
+}
+
+// firstBinding returns the first binding below n, whose sequence is seq.
+func (n *node) firstBinding(seq []string) Binding {
+	key := firstKey(n.next)
+	child := n.next[key]
+	seq = append(append([]string(nil), seq...), key)
+	if child.action != ActionNone {
+		return Binding{Keys: seq, Action: child.action, Source: child.source}
+	}
+	return child.firstBinding(seq)
+}
+
+func firstKey(next map[string]*node) string {
+	keys := make([]string, 0, len(next))
+	for k := range next {
+		keys = append(keys, k)
+	}
+	sortKeys(keys)
+	return keys[0]
+}
 
-		// Remove conflicting binding: if newKey is bound to a *different* action, drop it.
-		if existing, bound := defaultKeymap[newKey]; bound && existing != action {
-			delete(defaultKeymap, newKey)
+// SetLeader sets the key that <leader> stands for in configured sequences.
+// Call it before applying overrides. An empty key keeps the default (\).
+func SetLeader(key string) error {
+	if key == "" {
+		return nil
+	}
+	key = normalizeKey(key)
+	if err := ValidateKey(key); err != nil {
+		return fmt.Errorf("leader: %w", err)
+	}
+	leader = key
+	return nil
+}
+
+// SetTimeout sets how long a pending sequence waits for its next key.
+// Non-positive values keep the default.
+func SetTimeout(d time.Duration) {
+	if d > 0 {
+		timeout = d
+	}
+}
+
+// Timeout returns how long a pending sequence waits for its next key.
+func Timeout() time.Duration {
+	return timeout
+}
+
+// ParseSequence splits a configured binding such as "g g" or
+// "<leader> k s" into key strings. "space" and "<space>" stand for the
+// space bar. Every key must be a single character or a key name tswitch
+// can receive (see ValidateKey).
+func ParseSequence(s string) ([]string, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		if s != "" {
+			return []string{" "}, nil // a literal space
 		}
+		return nil, fmt.Errorf("empty key sequence")
+	}
+	for i, f := range fields {
+		fields[i] = normalizeKey(f)
+		if err := ValidateKey(fields[i]); err != nil {
+			return nil, err
+		}
+	}
+	return fields, nil
+}
 
-		// Add new binding.
-		defaultKeymap[newKey] = action
-		reservedKeys[newKey] = true
+func normalizeKey(key string) string {
+	switch strings.ToLower(key) {
+	case "<leader>":
+		return leader
+	case "space", "<space>":
+		return " "
+	}
+	return key
+}
+
+// ApplyOverrides adds user-specified key bindings on top of the defaults in
+// every grid scope. Each entry maps an action name (e.g. "quit") to a key
+// sequence (e.g. "Q" or "<leader> q"). If the sequence is already bound to
+// a different action, that conflicting binding is replaced and recorded in
+// Conflicts. Existing bindings for the same action are preserved (e.g.
+// arrow keys remain alongside hjkl overrides). Entries with an unknown
+// action or an invalid key are skipped and returned as warnings.
+func ApplyOverrides(overrides map[string]string) []string {
+	bindings, warnings := parseOverrides(SourceKeys, overrides)
+	for _, scope := range gridScopes {
+		applyBindings(scope, bindings)
+	}
+	return warnings
+}
+
+// ApplyScopeOverrides adds bindings to a single scope, named as in the
+// config file ("sessions", "windows", "panes", "filter", "dialog").
+func ApplyScopeOverrides(scopeName string, overrides map[string]string) []string {
+	source := "keymaps." + scopeName
+	scope, ok := ScopeByName(scopeName)
+	if !ok {
+		return []string{fmt.Sprintf("%s: unknown mode (want sessions, windows, panes, filter or dialog)", source)}
+	}
+	bindings, warnings := parseOverrides(source, overrides)
+	applyBindings(scope, bindings)
+	return warnings
+}
+
+// parseOverrides validates overrides from one config section. Entries are
+// returned sorted by action name so that two actions claiming the same
+// sequence always resolve the same way.
+func parseOverrides(source string, overrides map[string]string) ([]Binding, []string) {
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var bindings []Binding
+	var warnings []string
+	for _, name := range names {
+		action, ok := nameToAction[name]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("%s.%s: unknown action", source, name))
+			continue
+		}
+		seq, err := ParseSequence(overrides[name])
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s.%s: %v", source, name, err))
+			continue
+		}
+		bindings = append(bindings, Binding{Keys: seq, Action: action, Source: source})
+	}
+	return bindings, warnings
+}
+
+func applyBindings(scope Scope, bindings []Binding) {
+	for _, b := range bindings {
+		for _, c := range keymaps[scope].bind(b.Keys, b.Action, b.Source) {
+			c.Scope = scope
+			conflicts = append(conflicts, c)
+		}
 	}
 }
 
 // IsReserved returns true if the key is reserved and cannot be used as a mark.
+// When a mark prefix (jump_mark) is bound, marks live in their own namespace
+// and only esc is reserved; otherwise any key that starts a binding in a grid
+// scope is.
 func IsReserved(key string) bool {
-	return reservedKeys[key]
+	if key == "esc" {
+		return true
+	}
+	if HasMarkPrefix() {
+		return false
+	}
+	for _, scope := range gridScopes {
+		if _, ok := keymaps[scope].next[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// HasMarkPrefix reports whether jump_mark is bound in any grid scope, i.e.
+// whether mark keys are typed after a prefix rather than on their own.
+func HasMarkPrefix() bool {
+	for _, scope := range gridScopes {
+		if keymaps[scope].hasAction(ActionJumpMark) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *node) hasAction(action Action) bool {
+	if n.action == action {
+		return true
+	}
+	for _, child := range n.next {
+		if child.hasAction(action) {
+			return true
+		}
+	}
+	return false
+}
+
+// Lookup resolves a key sequence in scope. pending is true when seq is the
+// prefix of a longer binding; action is then what fires if no further key
+// arrives before the timeout (ActionNone if nothing). Unbound sequences
+// return ActionNone and pending false.
+func Lookup(scope Scope, seq []string) (action Action, pending bool) {
+	n := keymaps[scope]
+	for _, key := range seq {
+		child, ok := n.next[key]
+		if !ok {
+			return ActionNone, false
+		}
+		n = child
+	}
+	return n.action, len(n.next) > 0
+}
+
+// Resolve maps a single key string to an Action in scope. Returns
+// ActionNone if the key has no binding of its own.
+func Resolve(scope Scope, key string) Action {
+	action, _ := Lookup(scope, []string{key})
+	return action
 }
 
-// Resolve maps a raw key string to an Action using the default keymap.
-// Returns ActionNone if the key has no binding.
-func Resolve(key string) Action {
-	if a, ok := defaultKeymap[key]; ok {
-		return a
+// FormatSequence renders a key sequence for display, e.g. "g" or
+// "<leader> k".
+func FormatSequence(seq []string) string {
+	out := make([]string, len(seq))
+	for i, k := range seq {
+		out[i] = displayKey(k)
 	}
-	return ActionNone
+	return strings.Join(out, " ")
 }
@@ -0,0 +1,246 @@
+package keys
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// defaults restores the default keymaps now and after the test.
+func defaults(t *testing.T) {
+	t.Helper()
+	reset()
+	t.Cleanup(reset)
+}
+
+func TestParseSequence(t *testing.T) {
+	defaults(t)
+	tests := []struct {
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{in: "g", want: []string{"g"}},
+		{in: "g g", want: []string{"g", "g"}},
+		{in: "  d   d ", want: []string{"d", "d"}},
+		{in: "<leader> k s", want: []string{`\`, "k", "s"}},
+		{in: "<Leader> q", want: []string{`\`, "q"}},
+		{in: "space", want: []string{" "}},
+		{in: "<space> x", want: []string{" ", "x"}},
+		{in: " ", want: []string{" "}},
+		{in: "ctrl+a", want: []string{"ctrl+a"}},
+		{in: "alt+h", want: []string{"alt+h"}},
+		{in: "shift+tab", want: []string{"shift+tab"}},
+		{in: "é", want: []string{"é"}},
+		{in: "", wantErr: true},
+		{in: "foo", wantErr: true},
+		{in: "g nope", wantErr: true},
+		{in: "alt+", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSequence(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSequence(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("ParseSequence(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSetLeader(t *testing.T) {
+	defaults(t)
+	if err := SetLeader("nope"); err == nil {
+		t.Error("SetLeader(nope) = nil, want an error")
+	}
+	if err := SetLeader(""); err != nil || leader != `\` {
+		t.Errorf("SetLeader(\"\") = %v, leader %q; want the default kept", err, leader)
+	}
+	if err := SetLeader(","); err != nil {
+		t.Fatal(err)
+	}
+	if warnings := ApplyOverrides(map[string]string{"quit": "<leader> q"}); len(warnings) > 0 {
+		t.Fatal(warnings)
+	}
+	if action, pending := Lookup(ScopeSessions, []string{",", "q"}); action != ActionQuit || pending {
+		t.Errorf("Lookup(, q) = %v, %v; want quit, not pending", action, pending)
+	}
+}
+
+func TestSetTimeout(t *testing.T) {
+	defaults(t)
+	SetTimeout(0)
+	if Timeout() != time.Second {
+		t.Errorf("timeout = %v after SetTimeout(0), want the default 1s", Timeout())
+	}
+	SetTimeout(250 * time.Millisecond)
+	if Timeout() != 250*time.Millisecond {
+		t.Errorf("timeout = %v, want 250ms", Timeout())
+	}
+}
+
+// lookup is the result of Lookup.
+type lookup struct {
+	action  Action
+	pending bool
+}
+
+func TestBindConflicts(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides []map[string]string // applied in order
+		conflict  string              // Conflict.String() of the only conflict; "" = none
+		lookups   map[string]lookup   // sequence -> result in the sessions scope
+	}{
+		{
+			name:      "new key",
+			overrides: []map[string]string{{"broadcast": "b"}},
+			lookups:   map[string]lookup{"b": {ActionBroadcast, false}},
+		},
+		{
+			name:      "same key replaces the binding",
+			overrides: []map[string]string{{"quit": "n"}},
+			conflict:  "sessions: n → quit (keys) replaced new (default)",
+			lookups:   map[string]lookup{"n": {ActionQuit, false}, "q": {ActionQuit, false}},
+		},
+		{
+			name:      "rebinding the same action is no conflict",
+			overrides: []map[string]string{{"quit": "q"}},
+			lookups:   map[string]lookup{"q": {ActionQuit, false}},
+		},
+		{
+			name:      "longer sequence makes a leaf wait",
+			overrides: []map[string]string{{"focus_first": "d d"}},
+			conflict:  "sessions: d d → focus_first (keys) makes d → kill (default) wait for the timeout",
+			lookups: map[string]lookup{
+				"d":   {ActionKill, true},
+				"d d": {ActionFocusFirst, false},
+				"d x": {ActionNone, false},
+			},
+		},
+		{
+			name:      "leaf on an existing prefix fires on timeout",
+			overrides: []map[string]string{{"copy": "y y"}, {"broadcast": "y"}},
+			conflict:  "sessions: y → broadcast (keys) fires only after the timeout, since y y → copy (keys) starts with it",
+			lookups:   map[string]lookup{"y": {ActionBroadcast, true}, "y y": {ActionCopy, false}},
+		},
+		{
+			name:      "prefix without an action of its own",
+			overrides: []map[string]string{{"copy": "g c"}, {"broadcast": "g b"}},
+			lookups: map[string]lookup{
+				"g":   {ActionNone, true},
+				"g c": {ActionCopy, false},
+				"g b": {ActionBroadcast, false},
+			},
+		},
+		{
+			name:      "unbound",
+			overrides: nil,
+			lookups:   map[string]lookup{"g": {ActionNone, false}, "G": {ActionNone, false}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defaults(t)
+			for _, o := range tt.overrides {
+				if warnings := ApplyOverrides(o); len(warnings) > 0 {
+					t.Fatal(warnings)
+				}
+			}
+			// Overrides apply to every grid scope; check the first.
+			var got []string
+			for _, c := range Conflicts() {
+				if c.Scope == ScopeSessions {
+					got = append(got, c.String())
+				}
+			}
+			var want []string
+			if tt.conflict != "" {
+				want = []string{tt.conflict}
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("conflicts = %q, want %q", got, want)
+			}
+			for s, w := range tt.lookups {
+				seq, err := ParseSequence(s)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if action, pending := Lookup(ScopeSessions, seq); action != w.action || pending != w.pending {
+					t.Errorf("Lookup(%s) = %s, pending %v; want %s, pending %v",
+						s, Info(action).Name, pending, Info(w.action).Name, w.pending)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyOverridesWarnings(t *testing.T) {
+	defaults(t)
+	got := ApplyOverrides(map[string]string{"nope": "x", "quit": "foo", "kill": "D"})
+	want := []string{"keys.nope: unknown action", `keys.quit: invalid key "foo"`}
+	if !slices.Equal(got, want) {
+		t.Errorf("warnings = %q, want %q", got, want)
+	}
+	if Resolve(ScopeWindows, "D") != ActionKill {
+		t.Error("valid override skipped along with the invalid ones")
+	}
+
+	got = ApplyScopeOverrides("nowhere", map[string]string{"kill": "D"})
+	if len(got) != 1 {
+		t.Errorf("warnings for an unknown mode = %q, want one", got)
+	}
+	if got := ApplyScopeOverrides("panes", map[string]string{"zoom_pane": "Z"}); len(got) > 0 {
+		t.Fatal(got)
+	}
+	if Resolve(ScopePanes, "Z") != ActionZoomPane || Resolve(ScopeWindows, "Z") != ActionNone {
+		t.Error("scope override leaked out of the panes scope")
+	}
+}
+
+func TestIsReserved(t *testing.T) {
+	defaults(t)
+
+	// With the mark prefix (the default) every key but esc is free.
+	if !HasMarkPrefix() {
+		t.Fatal("jump_mark unbound by default")
+	}
+	for _, key := range []string{"j", "d", "'", "a", "%"} {
+		if IsReserved(key) {
+			t.Errorf("IsReserved(%q) = true with a mark prefix", key)
+		}
+	}
+	if !IsReserved("esc") {
+		t.Error("esc not reserved")
+	}
+	if got := MarkCollisions([]string{"j", "d"}); got != nil {
+		t.Errorf("collisions with a mark prefix = %q, want none", got)
+	}
+
+	// Without it, keys that start a binding in any grid scope are reserved.
+	for _, scope := range gridScopes {
+		delete(keymaps[scope].next, "'")
+	}
+	if HasMarkPrefix() {
+		t.Fatal("jump_mark still bound")
+	}
+	if warnings := ApplyScopeOverrides("panes", map[string]string{"zoom_pane": "z z"}); len(warnings) > 0 {
+		t.Fatal(warnings)
+	}
+	for key, want := range map[string]bool{
+		"j": true, "d": true, "esc": true,
+		"%": true, // panes only
+		"z": true, // starts a sequence
+		"a": false, "'": false, "g": false,
+	} {
+		if got := IsReserved(key); got != want {
+			t.Errorf("IsReserved(%q) = %v without a mark prefix, want %v", key, got, want)
+		}
+	}
+	got := MarkCollisions([]string{"a", "j"})
+	want := []string{`mark "j" is shadowed by j → move_down (default) in sessions`}
+	if !slices.Equal(got, want) {
+		t.Errorf("collisions = %q, want %q", got, want)
+	}
+}
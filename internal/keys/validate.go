@@ -0,0 +1,155 @@
+package keys
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Binding sources.
+const (
+	SourceDefault = "default"
+	SourceKeys    = "keys" // top-level "keys" in tswitch-config.json
+)
+
+// Binding is one key sequence and the action it triggers. Source says where
+// it came from: "default", "keys" or "keymaps.<mode>".
+type Binding struct {
+	Keys   []string
+	Action Action
+	Source string
+}
+
+// Conflict records an override that collided with an existing binding.
+// Normally the loser's sequence is gone; with Prefix set both still exist
+// but one sequence starts the other, so the shorter one only fires after
+// the sequence timeout.
+type Conflict struct {
+	Scope  Scope
+	Winner Binding
+	Loser  Binding
+	Prefix bool
+}
+
+func (c Conflict) String() string {
+	win := fmt.Sprintf("%s → %s (%s)", FormatSequence(c.Winner.Keys), Info(c.Winner.Action).Name, c.Winner.Source)
+	lose := fmt.Sprintf("%s → %s (%s)", FormatSequence(c.Loser.Keys), Info(c.Loser.Action).Name, c.Loser.Source)
+	switch {
+	case !c.Prefix:
+		return fmt.Sprintf("%s: %s replaced %s", ScopeName(c.Scope), win, Info(c.Loser.Action).Name+" ("+c.Loser.Source+")")
+	case len(c.Winner.Keys) > len(c.Loser.Keys):
+		return fmt.Sprintf("%s: %s makes %s wait for the timeout", ScopeName(c.Scope), win, lose)
+	default:
+		return fmt.Sprintf("%s: %s fires only after the timeout, since %s starts with it", ScopeName(c.Scope), win, lose)
+	}
+}
+
+// conflicts collects every conflict caused by overrides, in the order they
+// were applied.
+var conflicts []Conflict
+
+// Conflicts returns the conflicts caused by the overrides applied so far.
+func Conflicts() []Conflict {
+	return conflicts
+}
+
+// namedKeys holds every key name Bubble Tea reports, e.g. "enter",
+// "ctrl+a", "shift+tab", "f5".
+var namedKeys = func() map[string]bool {
+	names := make(map[string]bool)
+	for t := -128; t < 128; t++ {
+		if name := tea.KeyType(t).String(); name != "" && name != "runes" {
+			names[name] = true
+		}
+	}
+	return names
+}()
+
+// ValidateKey checks that key is something a key press can produce: a
+// single character or a named key such as "enter" or "ctrl+a", optionally
+// prefixed with "alt+".
+func ValidateKey(key string) error {
+	k := strings.TrimPrefix(key, "alt+")
+	if utf8.RuneCountInString(k) == 1 || namedKeys[k] {
+		return nil
+	}
+	return fmt.Errorf("invalid key %q", key)
+}
+
+// MarkCollisions warns about marks that can no longer be jumped to because
+// a binding starts with their key. With a mark prefix bound marks have
+// their own namespace and never collide.
+func MarkCollisions(markKeys []string) []string {
+	if HasMarkPrefix() {
+		return nil
+	}
+	sort.Strings(markKeys)
+	var warnings []string
+	for _, key := range markKeys {
+		for _, scope := range gridScopes {
+			child, ok := keymaps[scope].next[key]
+			if !ok {
+				continue
+			}
+			b := Binding{Keys: []string{key}, Action: child.action, Source: child.source}
+			if child.action == ActionNone {
+				b = child.firstBinding(b.Keys)
+			}
+			warnings = append(warnings, fmt.Sprintf("mark %q is shadowed by %s → %s (%s) in %s",
+				key, FormatSequence(b.Keys), Info(b.Action).Name, b.Source, ScopeName(scope)))
+			break
+		}
+	}
+	return warnings
+}
+
+// Scopes returns every scope in display order.
+func Scopes() []Scope {
+	return []Scope{ScopeSessions, ScopeWindows, ScopePanes, ScopeFilter, ScopeDialog}
+}
+
+// ScopeName returns the config-file name of scope.
+func ScopeName(scope Scope) string {
+	return scopeNames[scope]
+}
+
+// ScopeByName looks up a scope by its config-file name.
+func ScopeByName(name string) (Scope, bool) {
+	for scope, n := range scopeNames {
+		if n == name {
+			return scope, true
+		}
+	}
+	return 0, false
+}
+
+// Bindings returns the effective bindings of scope in action-table order,
+// most convenient sequence first within an action.
+func Bindings(scope Scope) []Binding {
+	var out []Binding
+	var walk func(n *node, seq []string)
+	walk = func(n *node, seq []string) {
+		if n.action != ActionNone && len(seq) > 0 {
+			out = append(out, Binding{Keys: append([]string(nil), seq...), Action: n.action, Source: n.source})
+		}
+		for key, child := range n.next {
+			walk(child, append(seq, key))
+		}
+	}
+	walk(keymaps[scope], nil)
+
+	order := make(map[Action]int, len(actionTable))
+	for i, info := range actionTable {
+		order[info.Action] = i
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Action != out[j].Action {
+			return order[out[i].Action] < order[out[j].Action]
+		}
+		return lessSeq(out[i].Keys, out[j].Keys)
+	})
+	return out
+}
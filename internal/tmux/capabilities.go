@@ -0,0 +1,83 @@
+package tmux
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Capabilities are the optional tmux features tswitch can use, derived from
+// the version of the server's tmux binary.
+type Capabilities struct {
+	Version string // as printed by tmux -V, without "tmux "; empty if unknown
+
+	Popup                bool // display-popup (3.2), for the popup key binding
+	ClipboardBuffer      bool // set-buffer -w sends the buffer to the clipboard (3.2)
+	CaptureEscapes       bool // capture-pane -e keeps colors (1.8)
+	ControlMode          bool // tmux -C (1.8)
+	ControlSubscriptions bool // refresh-client -B format subscriptions (3.2)
+
+	major, minor int
+	dev          bool // a build without a release number, e.g. "master"
+}
+
+// ParseVersion returns the capabilities of the tmux that printed v, the
+// output of tmux -V ("tmux 3.3a", "tmux next-3.5", "tmux master"). Builds
+// without a release number are assumed to have every feature; output that
+// isn't a version has none.
+func ParseVersion(v string) Capabilities {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "tmux ")
+	c := Capabilities{Version: v}
+	num := strings.TrimPrefix(v, "next-")
+	major, rest, _ := strings.Cut(num, ".")
+	var err error
+	if c.major, err = strconv.Atoi(major); err == nil {
+		end := strings.IndexFunc(rest, func(r rune) bool { return r < '0' || r > '9' })
+		if end < 0 {
+			end = len(rest)
+		}
+		c.minor, _ = strconv.Atoi(rest[:end])
+	} else {
+		c.dev = v != "" && !strings.ContainsAny(v, " \n")
+	}
+
+	c.Popup = c.AtLeast(3, 2)
+	c.ClipboardBuffer = c.AtLeast(3, 2)
+	c.CaptureEscapes = c.AtLeast(1, 8)
+	c.ControlMode = c.AtLeast(1, 8)
+	c.ControlSubscriptions = c.AtLeast(3, 2)
+	return c
+}
+
+// AtLeast reports whether tmux is version major.minor or later.
+func (c Capabilities) AtLeast(major, minor int) bool {
+	if c.dev {
+		return true
+	}
+	return c.major > major || c.major == major && c.minor >= minor
+}
+
+// String lists the version and the features it lacks, for `tswitch version`.
+func (c Capabilities) String() string {
+	if c.Version == "" {
+		return "tmux (unknown version)"
+	}
+	var missing []string
+	for _, f := range []struct {
+		name string
+		ok   bool
+	}{
+		{"popups", c.Popup},
+		{"copying to the clipboard", c.ClipboardBuffer},
+		{"colored capture", c.CaptureEscapes},
+		{"control mode", c.ControlMode},
+		{"control mode subscriptions", c.ControlSubscriptions},
+	} {
+		if !f.ok {
+			missing = append(missing, f.name)
+		}
+	}
+	if len(missing) == 0 {
+		return "tmux " + c.Version
+	}
+	return "tmux " + c.Version + " (no " + strings.Join(missing, ", ") + ")"
+}
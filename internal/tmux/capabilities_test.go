@@ -0,0 +1,77 @@
+package tmux
+
+import "testing"
+
+func TestParseVersion(t *testing.T) {
+	for _, tc := range []struct {
+		out     string
+		version string
+		at32    bool // popups, set-buffer -w, control mode subscriptions
+		at18    bool // capture-pane -e, control mode
+	}{
+		{"tmux 3.3a\n", "3.3a", true, true},
+		{"tmux 3.2", "3.2", true, true},
+		{"tmux 3.1c", "3.1c", false, true},
+		{"tmux 2.9a", "2.9a", false, true},
+		{"tmux 1.8", "1.8", false, true},
+		{"tmux 1.6", "1.6", false, false},
+		{"tmux 10.0", "10.0", true, true},
+		{"tmux next-3.6", "next-3.6", true, true},
+		{"tmux master", "master", true, true},
+		{"tmux openbsd-7.5", "openbsd-7.5", true, true},
+		{"", "", false, false},
+		{"command not found: tmux", "command not found: tmux", false, false},
+	} {
+		c := ParseVersion(tc.out)
+		if c.Version != tc.version ||
+			c.Popup != tc.at32 || c.ClipboardBuffer != tc.at32 || c.ControlSubscriptions != tc.at32 ||
+			c.CaptureEscapes != tc.at18 || c.ControlMode != tc.at18 {
+			t.Errorf("ParseVersion(%q) = %+v, want version %q, 3.2 features %v, 1.8 features %v",
+				tc.out, c, tc.version, tc.at32, tc.at18)
+		}
+	}
+}
+
+func TestCapabilitiesString(t *testing.T) {
+	for v, want := range map[string]string{
+		"tmux 3.3a": "tmux 3.3a",
+		"tmux 3.1c": "tmux 3.1c (no popups, copying to the clipboard, control mode subscriptions)",
+		"":          "tmux (unknown version)",
+	} {
+		if got := ParseVersion(v).String(); got != want {
+			t.Errorf("ParseVersion(%q).String() = %q, want %q", v, got, want)
+		}
+	}
+}
+
+func TestCapturePaneEscapes(t *testing.T) {
+	for _, tc := range []struct {
+		version string
+		want    string
+	}{
+		{"tmux 3.3a", "capture-pane -t work:1.0 -p -e"},
+		{"tmux 1.7", "capture-pane -t work:1.0 -p"},
+	} {
+		c := NewClientWith(scriptExecutor{"-V": tc.version, tc.want: "out"})
+		if out, err := c.CapturePane("work", 1, 0); err != nil || out != "out" {
+			t.Errorf("%s: CapturePane didn't run %q: %v", tc.version, tc.want, err)
+		}
+	}
+}
+
+func TestSetBufferClipboard(t *testing.T) {
+	for _, tc := range []struct {
+		version string
+		inTmux  bool
+		want    string
+	}{
+		{"tmux 3.3a", true, "set-buffer -w -- x"},
+		{"tmux 3.1c", true, "set-buffer -- x"},
+		{"tmux 3.3a", false, "set-buffer -- x"},
+	} {
+		c := &Client{exec: scriptExecutor{"-V": tc.version, tc.want: ""}, inTmux: tc.inTmux}
+		if err := c.SetBuffer("x"); err != nil {
+			t.Errorf("%s, in tmux %v: SetBuffer didn't run %q: %v", tc.version, tc.inTmux, tc.want, err)
+		}
+	}
+}
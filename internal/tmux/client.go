@@ -1,12 +1,15 @@
 package tmux
 
 import (
+	"cmp"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"sort"
 	"strings"
-	"time"
+	"sync"
+	"syscall"
 )
 
 // Compile-time check: Client must satisfy Service.
@@ -18,29 +21,58 @@ type Executor interface {
 	Run(args ...string) (string, error)
 }
 
-// shellExecutor runs real tmux commands.
-type shellExecutor struct{}
+// shellExecutor runs real tmux commands. When socketName or socketPath is
+// set, every command targets that server (tmux -L / -S) instead of the one
+// inherited from $TMUX or the default socket.
+type shellExecutor struct {
+	socketName string // tmux -L
+	socketPath string // tmux -S
+}
 
 func (e *shellExecutor) Run(args ...string) (string, error) {
-	cmd := exec.Command("tmux", args...)
+	argv := e.Command(append([]string{"-u"}, args...)...) // -u: see fieldSep
+	cmd := exec.Command(argv[0], argv[1:]...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return "", fmt.Errorf("tmux command failed: %w, output: %s", err, string(output))
+		return "", newError("", args, output, err)
 	}
 	return string(output), nil
 }
 
+// Command returns the full argv for a tmux invocation, including the socket
+// flags, so it can be handed to another process.
+func (e *shellExecutor) Command(args ...string) []string {
+	argv := []string{"tmux"}
+	switch {
+	case e.socketPath != "":
+		argv = append(argv, "-S", e.socketPath)
+	case e.socketName != "":
+		argv = append(argv, "-L", e.socketName)
+	}
+	return append(argv, args...)
+}
+
+// CommandBuilder is implemented by executors that can describe the full
+// command line of a tmux invocation (e.g. to attach from a new window).
+type CommandBuilder interface {
+	Command(args ...string) []string
+}
+
 // Client wraps tmux commands and implements Service.
 type Client struct {
 	exec           Executor
 	inTmux         bool
+	remote         bool   // server runs on another host; its PIDs mean nothing here
 	currentSession string // session tswitch is running in (empty if not in tmux)
+
+	capsOnce sync.Once
+	caps     Capabilities
 }
 
 // NewClient creates a Client that shells out to the real tmux binary.
 func NewClient() *Client {
 	c := &Client{
-		exec:   &shellExecutor{},
+		exec:   tapped("", &shellExecutor{}),
 		inTmux: os.Getenv("TMUX") != "",
 	}
 	if c.inTmux {
@@ -51,6 +83,22 @@ func NewClient() *Client {
 	return c
 }
 
+// NewSocketClient creates a Client for the tmux server listening on the given
+// socket. A value containing a slash is treated as a socket path (tmux -S),
+// anything else as a socket name (tmux -L).
+func NewSocketClient(socket string) *Client {
+	e := &shellExecutor{}
+	if strings.Contains(socket, "/") {
+		e.socketPath = socket
+	} else {
+		e.socketName = socket
+	}
+	return &Client{
+		exec:   tapped(socket, e),
+		inTmux: os.Getenv("TMUX") != "",
+	}
+}
+
 // NewClientWith creates a Client using the given Executor (useful for tests).
 func NewClientWith(exec Executor) *Client {
 	return &Client{
@@ -64,24 +112,104 @@ func (c *Client) IsInTmux() bool {
 	return c.inTmux
 }
 
+// Capabilities probes tmux -V on the first call and returns the features of
+// the server's tmux; the zero value (nothing optional) if it can't be run.
+func (c *Client) Capabilities() Capabilities {
+	c.capsOnce.Do(func() {
+		if out, err := c.exec.Run("-V"); err == nil {
+			c.caps = ParseVersion(out)
+		}
+	})
+	return c.caps
+}
+
+// AttachCommand returns the argv that attaches a new client to target
+// (session[:window[.pane]]) on this client's server. It returns nil when the
+// executor cannot describe its command line.
+func (c *Client) AttachCommand(target string) []string {
+	b, ok := c.exec.(CommandBuilder)
+	if !ok {
+		return nil
+	}
+	return b.Command("attach-session", "-t", target)
+}
+
 // ---------------------------------------------------------------------------
 // Queries
 // ---------------------------------------------------------------------------
 
-func (c *Client) ListSessions() ([]Session, error) {
-	output, err := c.exec.Run("list-sessions", "-F",
-		"#{session_name}|#{session_windows}|#{session_attached}|#{session_created}|#{session_last_attached}|#{session_width}|#{session_height}|#{pane_current_path}|#{pane_current_command}|#{pane_pid}|#{pane_title}")
+// Snapshot returns every session with its windows and their panes, sorted
+// like ListSessions. It reads them with a single list-panes -a, so the
+// levels always agree, even when tmux changes while tswitch loads.
+func (c *Client) Snapshot() ([]Session, error) {
+	type row struct {
+		Session
+		Window
+		Pane
+	}
+	output, err := c.exec.Run("list-panes", "-a", "-F", formatOf(row{}))
 	if err != nil {
 		return nil, fmt.Errorf("failed to list sessions: %w", err)
 	}
 
+	// list-panes -a goes through sessions, then windows, then panes, so the
+	// rows of each session and each window are contiguous. A row's session
+	// and window fields that come from a pane or window describe that row's
+	// pane and window; they are kept from the active ones.
+	rows, err := parseRows[row](output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
 	var sessions []Session
-	for _, line := range splitLines(output) {
-		s, err := parseSessionLine(line)
-		if err != nil {
-			continue
+	for _, r := range rows {
+		if c.remote {
+			r.Session.ActivePanePID, r.Window.ActivePanePID, r.Pane.PID = 0, 0, 0
+		}
+		if n := len(sessions); n == 0 || sessions[n-1].Name != r.Session.Name {
+			sessions = append(sessions, r.Session)
+		}
+		s := &sessions[len(sessions)-1]
+		if n := len(s.Windows); n == 0 || s.Windows[n-1].Index != r.Window.Index {
+			if !r.Window.Linked {
+				r.Window.LinkedSessions = nil
+			}
+			s.Windows = append(s.Windows, r.Window)
+		}
+		w := &s.Windows[len(s.Windows)-1]
+		w.Panes = append(w.Panes, r.Pane)
+		s.PaneCount++
+
+		if r.Pane.Active {
+			w.WorkingDir, w.ActivePaneCmd, w.ActivePaneTitle, w.ActivePanePID =
+				r.Window.WorkingDir, r.Window.ActivePaneCmd, r.Window.ActivePaneTitle, r.Window.ActivePanePID
+			if r.Window.Active {
+				s.Width, s.Height = r.Session.Width, r.Session.Height
+				s.ActivePaneDir, s.ActivePaneCmd, s.ActivePaneTitle, s.ActivePanePID =
+					r.Session.ActivePaneDir, r.Session.ActivePaneCmd, r.Session.ActivePaneTitle, r.Session.ActivePanePID
+			}
+		}
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].LastActive.After(sessions[j].LastActive)
+	})
+	return sessions, nil
+}
+
+func (c *Client) ListSessions() ([]Session, error) {
+	output, err := c.exec.Run("list-sessions", "-F", formatOf(Session{}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	sessions, err := parseRows[Session](output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	if c.remote {
+		for i := range sessions {
+			sessions[i].ActivePanePID = 0
 		}
-		sessions = append(sessions, s)
 	}
 
 	sort.Slice(sessions, func(i, j int) bool {
@@ -91,19 +219,22 @@ func (c *Client) ListSessions() ([]Session, error) {
 }
 
 func (c *Client) ListWindows(sessionName string) ([]Window, error) {
-	output, err := c.exec.Run("list-windows", "-t", sessionName, "-F",
-		"#{window_index}|#{window_name}|#{window_panes}|#{window_active}|#{window_layout}|#{pane_current_path}|#{pane_current_command}|#{pane_pid}|#{pane_title}")
+	output, err := c.exec.Run("list-windows", "-t", sessionName, "-F", formatOf(Window{}))
 	if err != nil {
 		return nil, fmt.Errorf("failed to list windows in session %s: %w", sessionName, err)
 	}
 
-	var windows []Window
-	for _, line := range splitLines(output) {
-		w, err := parseWindowLine(line)
-		if err != nil {
-			continue
+	windows, err := parseRows[Window](output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list windows in session %s: %w", sessionName, err)
+	}
+	for i := range windows {
+		if !windows[i].Linked {
+			windows[i].LinkedSessions = nil
+		}
+		if c.remote {
+			windows[i].ActivePanePID = 0
 		}
-		windows = append(windows, w)
 	}
 	return windows, nil
 }
@@ -111,53 +242,61 @@ func (c *Client) ListWindows(sessionName string) ([]Window, error) {
 // ListAllWindowNames returns a map of session name -> window names by querying
 // all sessions at once with list-windows -a.
 func (c *Client) ListAllWindowNames() (map[string][]string, error) {
-	output, err := c.exec.Run("list-windows", "-a", "-F", "#{session_name}|#{window_name}")
+	type row struct {
+		Session string `tmux:"session_name"`
+		Window  string `tmux:"window_name"`
+	}
+	output, err := c.exec.Run("list-windows", "-a", "-F", formatOf(row{}))
 	if err != nil {
 		return nil, fmt.Errorf("failed to list all windows: %w", err)
 	}
 
+	rows, err := parseRows[row](output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list all windows: %w", err)
+	}
 	result := make(map[string][]string)
-	for _, line := range splitLines(output) {
-		parts := strings.Split(line, "|")
-		if len(parts) < 2 {
-			continue
-		}
-		sessName, winName := parts[0], parts[1]
-		result[sessName] = append(result[sessName], winName)
+	for _, r := range rows {
+		result[r.Session] = append(result[r.Session], r.Window)
 	}
 	return result, nil
 }
 
 func (c *Client) ListAllPaneCounts() (map[string]int, error) {
-	output, err := c.exec.Run("list-panes", "-a", "-F", "#{session_name}")
+	type row struct {
+		Session string `tmux:"session_name"`
+	}
+	output, err := c.exec.Run("list-panes", "-a", "-F", formatOf(row{}))
 	if err != nil {
 		return nil, fmt.Errorf("failed to list all panes: %w", err)
 	}
 
+	rows, err := parseRows[row](output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list all panes: %w", err)
+	}
 	result := make(map[string]int)
-	for _, line := range splitLines(output) {
-		if line != "" {
-			result[line]++
-		}
+	for _, r := range rows {
+		result[r.Session]++
 	}
 	return result, nil
 }
 
 func (c *Client) ListPanes(sessionName string, windowIndex int) ([]Pane, error) {
 	target := fmt.Sprintf("%s:%d", sessionName, windowIndex)
-	output, err := c.exec.Run("list-panes", "-t", target, "-F",
-		"#{pane_index}|#{pane_active}|#{pane_width}|#{pane_height}|#{pane_current_command}|#{pane_current_path}|#{pane_pid}|#{pane_title}")
+	output, err := c.exec.Run("list-panes", "-t", target, "-F", formatOf(Pane{}))
 	if err != nil {
 		return nil, fmt.Errorf("failed to list panes: %w", err)
 	}
 
-	var panes []Pane
-	for _, line := range splitLines(output) {
-		p, err := parsePaneLine(line)
-		if err != nil {
-			continue
+	panes, err := parseRows[Pane](output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list panes: %w", err)
+	}
+	if c.remote {
+		for i := range panes {
+			panes[i].PID = 0
 		}
-		panes = append(panes, p)
 	}
 	return panes, nil
 }
@@ -170,7 +309,33 @@ func (c *Client) CapturePane(sessionName string, windowIndex int, paneIndex int)
 	} else {
 		target = fmt.Sprintf("%s:%d.%d", sessionName, windowIndex, paneIndex)
 	}
-	return c.exec.Run("capture-pane", "-t", target, "-p")
+	args := []string{"capture-pane", "-t", target, "-p"}
+	if c.Capabilities().CaptureEscapes {
+		args = append(args, "-e") // keep the colors; tmux before 1.8 rejects -e
+	}
+	return c.exec.Run(args...)
+}
+
+// CurrentPane returns the session, window and pane of the client tswitch
+// runs in.
+func (c *Client) CurrentPane() (sessionName string, windowIndex, paneIndex int, err error) {
+	type row struct {
+		Session string `tmux:"session_name"`
+		Window  int    `tmux:"window_index"`
+		Pane    int    `tmux:"pane_index"`
+	}
+	out, err := c.exec.Run("display-message", "-p", formatOf(row{}))
+	if err != nil {
+		return "", 0, 0, err
+	}
+	rows, err := parseRows[row](out)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	if len(rows) != 1 {
+		return "", 0, 0, fmt.Errorf("unexpected display-message output %q", out)
+	}
+	return rows[0].Session, rows[0].Window, rows[0].Pane, nil
 }
 
 // ---------------------------------------------------------------------------
@@ -202,9 +367,24 @@ func (c *Client) SelectPane(sessionName string, windowIndex int, paneIndex int)
 	return err
 }
 
+// AttachSession replaces the current process with a tmux client attached to
+// the session. It only returns on failure. Call it after the TUI has released
+// the terminal.
 func (c *Client) AttachSession(sessionName string) error {
-	_, err := c.exec.Run("attach-session", "-t", sessionName)
-	return err
+	argv := c.AttachCommand(sessionName)
+	if argv == nil {
+		return fmt.Errorf("cannot attach to %s: executor has no command line", sessionName)
+	}
+	return Exec(argv)
+}
+
+// Exec replaces the current process with argv, resolving argv[0] in PATH.
+func Exec(argv []string) error {
+	path, err := exec.LookPath(argv[0])
+	if err != nil {
+		return err
+	}
+	return syscall.Exec(path, argv, os.Environ())
 }
 
 // ---------------------------------------------------------------------------
@@ -221,8 +401,47 @@ func (c *Client) NewSessionInDir(sessionName string, dir string) error {
 	return err
 }
 
+// CreateSession creates a detached session from spec: its first window
+// becomes the initial window, the rest are added after it. Commands are
+// typed into the windows' shells so the shell remains when they exit.
+func (c *Client) CreateSession(spec SessionSpec) error {
+	var first WindowSpec
+	if len(spec.Windows) > 0 {
+		first = spec.Windows[0]
+	}
+	args := []string{"new-session", "-d", "-P", "-F", "#{window_id}", "-s", spec.Name}
+	if dir := joinDir(spec.Dir, first.Dir); dir != "" {
+		args = append(args, "-c", dir)
+	}
+	if first.Name != "" {
+		args = append(args, "-n", first.Name)
+	}
+	out, err := c.exec.Run(args...)
+	if err != nil {
+		return err
+	}
+	if err := c.sendCommand(strings.TrimSpace(out), first.Command); err != nil {
+		return err
+	}
+	for _, w := range spec.Windows[min(1, len(spec.Windows)):] {
+		w.Dir = joinDir(spec.Dir, w.Dir)
+		if _, err := c.CreateWindow(spec.Name, w); err != nil {
+			return fmt.Errorf("window %q: %w", w.Name, err)
+		}
+	}
+	return nil
+}
+
+// StartServer starts the tmux server if none is running.
+func (c *Client) StartServer() error {
+	_, err := c.exec.Run("start-server")
+	return err
+}
+
+// HasSession reports whether a session is named exactly sessionName; a bare
+// target would also match sessions it is a prefix of.
 func (c *Client) HasSession(sessionName string) bool {
-	_, err := c.exec.Run("has-session", "-t", sessionName)
+	_, err := c.exec.Run("has-session", "-t", "="+sessionName)
 	return err == nil
 }
 
@@ -251,6 +470,78 @@ func (c *Client) NewWindow(sessionName string, windowName string) error {
 	return err
 }
 
+// CreateWindow adds a window described by spec to sessionName, at the first
+// free index, and returns that index.
+func (c *Client) CreateWindow(sessionName string, spec WindowSpec) (int, error) {
+	type row struct {
+		ID    string `tmux:"window_id"`
+		Index int    `tmux:"window_index"`
+	}
+	args := []string{"new-window", "-P", "-F", formatOf(row{}), "-t", sessionName + ":"}
+	if spec.Dir != "" {
+		args = append(args, "-c", spec.Dir)
+	}
+	if spec.Name != "" {
+		args = append(args, "-n", spec.Name)
+	}
+	out, err := c.exec.Run(args...)
+	if err != nil {
+		return 0, err
+	}
+	rows, err := parseRows[row](out)
+	if err != nil {
+		return 0, err
+	}
+	if len(rows) != 1 {
+		return 0, fmt.Errorf("unexpected new-window output %q", out)
+	}
+	return rows[0].Index, c.sendCommand(rows[0].ID, spec.Command)
+}
+
+// sendCommand types command into the shell of target and presses enter.
+func (c *Client) sendCommand(target, command string) error {
+	if command == "" {
+		return nil
+	}
+	return c.sendKeys(target, command, true)
+}
+
+// sendKeys types text into target literally, so key names in it are not
+// interpreted, then presses enter if asked.
+func (c *Client) sendKeys(target, text string, enter bool) error {
+	if text != "" {
+		if _, err := c.exec.Run("send-keys", "-t", target, "-l", text); err != nil {
+			return err
+		}
+	}
+	if enter {
+		_, err := c.exec.Run("send-keys", "-t", target, "Enter")
+		return err
+	}
+	return nil
+}
+
+// joinDir resolves dir against base unless it is absolute or empty.
+func joinDir(base, dir string) string {
+	if dir == "" || filepath.IsAbs(dir) || base == "" {
+		return cmp.Or(dir, base)
+	}
+	return filepath.Join(base, dir)
+}
+
+// OpenWindow opens a new window in the current session running command and
+// selects it. TMUX is cleared for the command so it may start a nested tmux
+// client (e.g. attaching to a session on another server).
+func (c *Client) OpenWindow(windowName string, command []string) error {
+	args := []string{"new-window", "-n", windowName}
+	if c.currentSession != "" {
+		args = append(args, "-t", c.currentSession+":")
+	}
+	argv := append([]string{"env", "-u", "TMUX"}, command...)
+	_, err := c.exec.Run(append(args, ShellJoin(argv))...)
+	return err
+}
+
 func (c *Client) RenameWindow(sessionName string, windowIndex int, newName string) error {
 	target := fmt.Sprintf("%s:%d", sessionName, windowIndex)
 	_, err := c.exec.Run("rename-window", "-t", target, newName)
@@ -285,6 +576,23 @@ func (c *Client) SwapWindow(sessionName string, srcIndex, dstIndex int) error {
 	return err
 }
 
+// LinkWindow links a window into another session, appending it to the end
+// of the destination's window list. Both sessions then share the window.
+func (c *Client) LinkWindow(srcSession string, srcIndex int, dstSession string) error {
+	src := fmt.Sprintf("%s:%d", srcSession, srcIndex)
+	dst := fmt.Sprintf("%s:", dstSession)
+	_, err := c.exec.Run("link-window", "-s", src, "-t", dst)
+	return err
+}
+
+// UnlinkWindow removes a linked window from one session; the other sessions
+// keep it. It fails for windows that are not linked elsewhere.
+func (c *Client) UnlinkWindow(sessionName string, windowIndex int) error {
+	target := fmt.Sprintf("%s:%d", sessionName, windowIndex)
+	_, err := c.exec.Run("unlink-window", "-t", target)
+	return err
+}
+
 // ---------------------------------------------------------------------------
 // Pane management
 // ---------------------------------------------------------------------------
@@ -298,113 +606,137 @@ func (c *Client) JoinPane(srcSession string, srcWindow, srcPane int, dstSession
 	return err
 }
 
-// ---------------------------------------------------------------------------
-// Parsing helpers
-// ---------------------------------------------------------------------------
-
-func splitLines(output string) []string {
-	var lines []string
-	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
-		if line != "" {
-			lines = append(lines, line)
-		}
+// SplitWindow splits a pane, starting the new pane in the same directory.
+// horizontal places the new pane to the right instead of below. Negative
+// indexes pick the session's active window and the window's active pane.
+func (c *Client) SplitWindow(sessionName string, windowIndex, paneIndex int, horizontal bool) error {
+	target := sessionName + ":"
+	if windowIndex >= 0 {
+		target += fmt.Sprint(windowIndex)
+	}
+	if windowIndex >= 0 && paneIndex >= 0 {
+		target += fmt.Sprintf(".%d", paneIndex)
 	}
-	return lines
+	args := []string{"split-window", "-t", target, "-c", "#{pane_current_path}"}
+	if horizontal {
+		args = append(args, "-h")
+	}
+	_, err := c.exec.Run(args...)
+	return err
 }
 
-func parseSessionLine(line string) (Session, error) {
-	parts := strings.SplitN(line, "|", 11)
-	if len(parts) < 7 {
-		return Session{}, fmt.Errorf("invalid session line: need 7 fields, got %d", len(parts))
-	}
+// KillPane kills a single pane; killing the last pane also closes its window.
+func (c *Client) KillPane(sessionName string, windowIndex, paneIndex int) error {
+	target := fmt.Sprintf("%s:%d.%d", sessionName, windowIndex, paneIndex)
+	_, err := c.exec.Run("kill-pane", "-t", target)
+	return err
+}
 
-	var windowCount int
-	if _, err := fmt.Sscanf(parts[1], "%d", &windowCount); err != nil {
-		return Session{}, fmt.Errorf("invalid window count %q: %w", parts[1], err)
-	}
+// BreakPane moves a pane out into a new window of the same session, without
+// making the new window current.
+func (c *Client) BreakPane(sessionName string, windowIndex, paneIndex int) error {
+	src := fmt.Sprintf("%s:%d.%d", sessionName, windowIndex, paneIndex)
+	_, err := c.exec.Run("break-pane", "-d", "-s", src, "-t", sessionName+":")
+	return err
+}
 
-	var width, height int
-	fmt.Sscanf(parts[5], "%d", &width)  // non-critical, zero is acceptable
-	fmt.Sscanf(parts[6], "%d", &height) // non-critical, zero is acceptable
+// SwapPane swaps two panes of a window by their indices. The active pane
+// stays where it is.
+func (c *Client) SwapPane(sessionName string, windowIndex, srcPane, dstPane int) error {
+	src := fmt.Sprintf("%s:%d.%d", sessionName, windowIndex, srcPane)
+	dst := fmt.Sprintf("%s:%d.%d", sessionName, windowIndex, dstPane)
+	_, err := c.exec.Run("swap-pane", "-d", "-s", src, "-t", dst)
+	return err
+}
 
-	s := Session{
-		Name:        parts[0],
-		WindowCount: windowCount,
-		Attached:    parts[2] == "1",
-		Created:     parseUnixTime(parts[3]),
-		LastActive:  parseUnixTime(parts[4]),
-		Width:       width,
-		Height:      height,
+// ResizePane moves a pane's borders by dx columns and dy rows; negative
+// values move them left/up.
+func (c *Client) ResizePane(sessionName string, windowIndex, paneIndex int, dx, dy int) error {
+	target := fmt.Sprintf("%s:%d.%d", sessionName, windowIndex, paneIndex)
+	resize := func(flag string, n int) error {
+		_, err := c.exec.Run("resize-pane", "-t", target, flag, fmt.Sprint(n))
+		return err
+	}
+	switch {
+	case dx < 0:
+		if err := resize("-L", -dx); err != nil {
+			return err
+		}
+	case dx > 0:
+		if err := resize("-R", dx); err != nil {
+			return err
+		}
 	}
-	if len(parts) >= 11 {
-		s.ActivePaneDir = parts[7]
-		s.ActivePaneCmd = parts[8]
-		fmt.Sscanf(parts[9], "%d", &s.ActivePanePID)
-		s.ActivePaneTitle = parts[10]
+	switch {
+	case dy < 0:
+		return resize("-U", -dy)
+	case dy > 0:
+		return resize("-D", dy)
 	}
-	return s, nil
+	return nil
 }
 
-func parseWindowLine(line string) (Window, error) {
-	parts := strings.SplitN(line, "|", 9)
-	if len(parts) < 6 {
-		return Window{}, fmt.Errorf("invalid window line: need 6 fields, got %d", len(parts))
-	}
+// ZoomPane toggles the zoomed state of a pane.
+func (c *Client) ZoomPane(sessionName string, windowIndex, paneIndex int) error {
+	target := fmt.Sprintf("%s:%d.%d", sessionName, windowIndex, paneIndex)
+	_, err := c.exec.Run("resize-pane", "-Z", "-t", target)
+	return err
+}
 
-	var index, paneCount int
-	if _, err := fmt.Sscanf(parts[0], "%d", &index); err != nil {
-		return Window{}, fmt.Errorf("invalid window index %q: %w", parts[0], err)
-	}
-	fmt.Sscanf(parts[2], "%d", &paneCount) // zero is acceptable fallback
+// SelectLayout arranges a window's panes with a preset layout name
+// (even-horizontal, tiled, ...) or a layout string.
+func (c *Client) SelectLayout(sessionName string, windowIndex int, layout string) error {
+	target := fmt.Sprintf("%s:%d", sessionName, windowIndex)
+	_, err := c.exec.Run("select-layout", "-t", target, layout)
+	return err
+}
 
-	w := Window{
-		Index:      index,
-		Name:       parts[1],
-		PaneCount:  paneCount,
-		Active:     parts[3] == "1",
-		Layout:     parts[4],
-		WorkingDir: parts[5],
-	}
-	if len(parts) >= 9 {
-		w.ActivePaneCmd = parts[6]
-		fmt.Sscanf(parts[7], "%d", &w.ActivePanePID)
-		w.ActivePaneTitle = parts[8]
-	}
-	return w, nil
+// ---------------------------------------------------------------------------
+// Input
+// ---------------------------------------------------------------------------
+
+// SendKeys types text into a pane as if typed there, pressing enter after it
+// when enter is set.
+func (c *Client) SendKeys(sessionName string, windowIndex, paneIndex int, text string, enter bool) error {
+	return c.sendKeys(fmt.Sprintf("%s:%d.%d", sessionName, windowIndex, paneIndex), text, enter)
 }
 
-func parsePaneLine(line string) (Pane, error) {
-	parts := strings.SplitN(line, "|", 8)
-	if len(parts) < 6 {
-		return Pane{}, fmt.Errorf("invalid pane line: need 6 fields, got %d", len(parts))
-	}
+// ---------------------------------------------------------------------------
+// Buffers
+// ---------------------------------------------------------------------------
 
-	var index, width, height int
-	if _, err := fmt.Sscanf(parts[0], "%d", &index); err != nil {
-		return Pane{}, fmt.Errorf("invalid pane index %q: %w", parts[0], err)
+// SetBuffer stores text in a tmux paste buffer. Inside tmux 3.2 and later
+// the buffer is also sent to the system clipboard of the attached terminal.
+func (c *Client) SetBuffer(text string) error {
+	args := []string{"set-buffer"}
+	if c.IsInTmux() && c.Capabilities().ClipboardBuffer {
+		args = append(args, "-w")
 	}
-	fmt.Sscanf(parts[2], "%d", &width)  // zero is acceptable fallback
-	fmt.Sscanf(parts[3], "%d", &height) // zero is acceptable fallback
+	_, err := c.exec.Run(append(args, "--", text)...)
+	return err
+}
 
-	p := Pane{
-		Index:      index,
-		Active:     parts[1] == "1",
-		Width:      width,
-		Height:     height,
-		Command:    parts[4],
-		WorkingDir: parts[5],
-	}
-	if len(parts) >= 8 {
-		fmt.Sscanf(parts[6], "%d", &p.PID)
-		p.Title = parts[7]
+// ---------------------------------------------------------------------------
+// Parsing helpers
+// ---------------------------------------------------------------------------
+
+// ShellJoin quotes args for a POSIX shell and joins them with spaces.
+func ShellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = shellQuote(a)
 	}
-	return p, nil
+	return strings.Join(quoted, " ")
 }
 
-func parseUnixTime(s string) time.Time {
-	var unix int64
-	if _, err := fmt.Sscanf(s, "%d", &unix); err == nil && unix > 0 {
-		return time.Unix(unix, 0)
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./=:@%+,", r))
+	}) < 0 {
+		return s
 	}
-	return time.Time{}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
 }
@@ -0,0 +1,705 @@
+package tmux
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// These tests run every Client method against a real tmux server, so the
+// parsers are checked against the output of the installed tmux version.
+
+// testServer is a private tmux server for one test. It listens on its own
+// socket under a temporary TMUX_TMPDIR, so the user's servers are never
+// touched, and starts without a config file, so options are tmux's defaults.
+//
+// Fixture:
+//
+//	work 0:editor (two panes, side by side) 1:logs
+//	play 0:sh
+type testServer struct {
+	*Client
+	t      *testing.T
+	socket string
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("starts a tmux server")
+	}
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not installed")
+	}
+
+	// Socket paths are limited to about 100 bytes, too few for t.TempDir.
+	dir, err := os.MkdirTemp("", "tswitch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	t.Setenv("TMUX_TMPDIR", dir)
+	t.Setenv("TMUX", "")
+	t.Setenv("SHELL", "/bin/sh")
+
+	b := make([]byte, 4)
+	rand.Read(b)
+	s := &testServer{Client: NewSocketClient("tswitch-test-" + hex.EncodeToString(b)), t: t}
+	s.socket = s.exec.(*shellExecutor).socketName
+
+	out, err := exec.Command("tmux", "-L", s.socket, "-f", "/dev/null",
+		"new-session", "-d", "-s", "work", "-n", "editor", "-x", "80", "-y", "24").CombinedOutput()
+	if err != nil {
+		t.Fatalf("starting tmux: %v: %s", err, out)
+	}
+	t.Cleanup(func() { exec.Command("tmux", "-L", s.socket, "kill-server").Run() })
+
+	s.run("split-window", "-h", "-t", "work:0")
+	s.run("new-window", "-d", "-t", "work:", "-n", "logs")
+	s.run("new-session", "-d", "-s", "play", "-x", "80", "-y", "24")
+	return s
+}
+
+// run runs a tmux command on the server, failing the test on error.
+func (s *testServer) run(args ...string) string {
+	s.t.Helper()
+	out, err := s.exec.Run(args...)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return strings.TrimSpace(out)
+}
+
+// lines runs a tmux command and returns its output lines.
+func (s *testServer) lines(args ...string) []string {
+	s.t.Helper()
+	return strings.Split(strings.TrimSpace(s.run(args...)), "\n")
+}
+
+// format expands a tmux format for target.
+func (s *testServer) format(target, format string) string {
+	s.t.Helper()
+	return s.run("display-message", "-p", "-t", target, format)
+}
+
+// attachClient attaches a tmux client to session from a pane of a
+// separate "host" session, so the navigation commands have a client to move.
+func (s *testServer) attachClient(session string) {
+	s.t.Helper()
+	s.run("new-session", "-d", "-s", "host", "-x", "100", "-y", "30",
+		ShellJoin([]string{"env", "-u", "TMUX", "tmux", "-L", s.socket, "attach-session", "-t", session}))
+	s.eventually("a client to attach", func() bool { return len(s.lines("list-clients")) == 1 })
+}
+
+// client returns the attached client's session:window.pane.
+func (s *testServer) client() string {
+	s.t.Helper()
+	return s.run("list-clients", "-F", "#{client_session}:#{window_index}.#{pane_index}")
+}
+
+// eventually waits for cond, which depends on a shell catching up.
+func (s *testServer) eventually(what string, cond func() bool) {
+	s.t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); !cond(); {
+		if time.Now().After(deadline) {
+			s.t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// windowNames returns the window names of session in index order.
+func (s *testServer) windowNames(session string) []string {
+	s.t.Helper()
+	return s.lines("list-windows", "-t", session, "-F", "#{window_index}:#{window_name}")
+}
+
+// paneIDs returns the pane ids of a window in index order.
+func (s *testServer) paneIDs(target string) []string {
+	s.t.Helper()
+	return s.lines("list-panes", "-t", target, "-F", "#{pane_id}")
+}
+
+// ---------------------------------------------------------------------------
+// Queries
+// ---------------------------------------------------------------------------
+
+func TestClientListSessions(t *testing.T) {
+	s := newTestServer(t)
+	if s.IsInTmux() {
+		t.Error("IsInTmux() = true with TMUX unset")
+	}
+
+	s.run("rename-session", "-t", "play", "pl|ay") // names may contain "|"
+
+	sessions, err := s.ListSessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("ListSessions() returned %d sessions, want 2: %+v", len(sessions), sessions)
+	}
+	if !slices.ContainsFunc(sessions, func(x Session) bool { return x.Name == "pl|ay" && x.WindowCount == 1 }) {
+		t.Errorf("no session pl|ay with 1 window in %+v", sessions)
+	}
+	i := slices.IndexFunc(sessions, func(x Session) bool { return x.Name == "work" })
+	if i < 0 {
+		t.Fatalf("no session work in %+v", sessions)
+	}
+	work := sessions[i]
+	if work.WindowCount != 2 || work.Attached || work.Width != 80 || work.Height != 24 {
+		t.Errorf("work = %+v, want 2 windows, detached, 80x24", work)
+	}
+	if work.Created.IsZero() || work.ActivePaneCmd != "sh" || work.ActivePanePID <= 0 || work.ActivePaneDir == "" {
+		t.Errorf("work = %+v, want creation time and active pane details", work)
+	}
+}
+
+// Outside tmux and without a UTF-8 locale, tmux prints the field separator
+// as "_" unless it is run with -u.
+func TestClientCLocale(t *testing.T) {
+	s := newTestServer(t)
+	// tmux takes a set TMUX, even an empty one, as a UTF-8 terminal.
+	os.Unsetenv("TMUX") // newTestServer's t.Setenv restores it
+	t.Setenv("LANG", "C")
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_CTYPE", "")
+
+	sessions, err := s.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 {
+		t.Errorf("Snapshot() returned %d sessions under LANG=C, want 2: %+v", len(sessions), sessions)
+	}
+	if sessions, err := s.ListSessions(); err != nil || len(sessions) != 2 {
+		t.Errorf("ListSessions() = %+v, %v under LANG=C, want 2 sessions", sessions, err)
+	}
+}
+
+func TestClientListWindows(t *testing.T) {
+	s := newTestServer(t)
+	s.run("select-pane", "-t", "work:0.1", "-T", "a|b") // titles may contain "|"
+
+	windows, err := s.ListWindows("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(windows) != 2 {
+		t.Fatalf("ListWindows(work) returned %d windows, want 2: %+v", len(windows), windows)
+	}
+	editor, logs := windows[0], windows[1]
+	if editor.Index != 0 || editor.Name != "editor" || editor.PaneCount != 2 || !editor.Active {
+		t.Errorf("window 0 = %+v, want active editor with 2 panes", editor)
+	}
+	if editor.ActivePaneTitle != "a|b" {
+		t.Errorf("active pane title = %q, want %q", editor.ActivePaneTitle, "a|b")
+	}
+	if editor.Layout != s.format("work:0", "#{window_layout}") {
+		t.Errorf("layout = %q, want %q", editor.Layout, s.format("work:0", "#{window_layout}"))
+	}
+	if logs.Index != 1 || logs.Name != "logs" || logs.PaneCount != 1 || logs.Active || logs.Linked {
+		t.Errorf("window 1 = %+v, want inactive, unlinked logs with 1 pane", logs)
+	}
+	if editor.ActivePaneCmd != "sh" || editor.ActivePanePID <= 0 || editor.WorkingDir == "" {
+		t.Errorf("window 0 = %+v, want active pane details", editor)
+	}
+
+	if _, err := s.ListWindows("nope"); err == nil {
+		t.Error("ListWindows(nope) succeeded")
+	}
+}
+
+func TestClientListAll(t *testing.T) {
+	s := newTestServer(t)
+
+	names, err := s.ListAllWindowNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"editor", "logs"}; !slices.Equal(names["work"], want) {
+		t.Errorf("window names of work = %q, want %q", names["work"], want)
+	}
+	if want := []string{"sh"}; !slices.Equal(names["play"], want) {
+		t.Errorf("window names of play = %q, want %q", names["play"], want)
+	}
+
+	counts, err := s.ListAllPaneCounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts["work"] != 3 || counts["play"] != 1 {
+		t.Errorf("pane counts = %v, want work 3, play 1", counts)
+	}
+}
+
+// TestClientSnapshot checks the tree from one list-panes -a against the
+// per-level queries.
+func TestClientSnapshot(t *testing.T) {
+	s := newTestServer(t)
+	s.run("link-window", "-s", "work:1", "-t", "play:")
+	s.run("select-window", "-t", "work:1") // the active window isn't the first
+	s.run("select-pane", "-t", "work:0.0", "-T", "left|title")
+
+	snap, err := s.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := s.ListSessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snap) != len(sessions) {
+		t.Fatalf("Snapshot() returned %d sessions, want %d", len(snap), len(sessions))
+	}
+	for i, got := range snap {
+		windows, err := s.ListWindows(got.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		panes := 0
+		for j := range windows {
+			if windows[j].Panes, err = s.ListPanes(got.Name, windows[j].Index); err != nil {
+				t.Fatal(err)
+			}
+			panes += len(windows[j].Panes)
+		}
+		want := sessions[i]
+		want.Windows, want.PaneCount = windows, panes
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Snapshot()[%d] =\n%+v\nwant\n%+v", i, got, want)
+		}
+	}
+}
+
+func TestClientListPanes(t *testing.T) {
+	s := newTestServer(t)
+
+	panes, err := s.ListPanes("work", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(panes) != 2 {
+		t.Fatalf("ListPanes(work, 0) returned %d panes, want 2: %+v", len(panes), panes)
+	}
+	left, right := panes[0], panes[1]
+	if left.Index != 0 || right.Index != 1 || left.Active || !right.Active {
+		t.Errorf("panes = %+v, want indexes 0 and 1 with the new pane active", panes)
+	}
+	if left.Width+1+right.Width != 80 || left.Height != 24 {
+		t.Errorf("pane sizes %dx%d and %dx%d don't fill 80x24", left.Width, left.Height, right.Width, right.Height)
+	}
+	if left.Command != "sh" || left.PID <= 0 || left.WorkingDir == "" {
+		t.Errorf("pane 0 = %+v, want command, pid and directory", left)
+	}
+
+	if _, err := s.ListPanes("work", 9); err == nil {
+		t.Error("ListPanes(work, 9) succeeded")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Navigation
+// ---------------------------------------------------------------------------
+
+func TestClientNavigation(t *testing.T) {
+	s := newTestServer(t)
+	s.attachClient("work")
+
+	if err := s.SwitchToSession("play"); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.client(); got != "play:0.0" {
+		t.Errorf("after SwitchToSession(play) client is on %s", got)
+	}
+	if err := s.SwitchToLast(); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.client(); got != "work:0.1" {
+		t.Errorf("after SwitchToLast() client is on %s, want work:0.1", got)
+	}
+	if err := s.SwitchClient("work", 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.client(); got != "work:1.0" {
+		t.Errorf("after SwitchClient(work, 1) client is on %s", got)
+	}
+	if err := s.SelectPane("work", 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.client(); got != "work:0.0" {
+		t.Errorf("after SelectPane(work, 0, 0) client is on %s", got)
+	}
+
+	sess, win, pane, err := s.CurrentPane()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sess != "work" || win != 0 || pane != 0 {
+		t.Errorf("CurrentPane() = %s:%d.%d, want work:0.0", sess, win, pane)
+	}
+
+	if err := s.SwitchToSession("nope"); err == nil {
+		t.Error("SwitchToSession(nope) succeeded")
+	}
+}
+
+func TestClientAttachCommand(t *testing.T) {
+	s := newTestServer(t)
+	want := []string{"tmux", "-L", s.socket, "attach-session", "-t", "work:1"}
+	if got := s.AttachCommand("work:1"); !slices.Equal(got, want) {
+		t.Errorf("AttachCommand(work:1) = %q, want %q", got, want)
+	}
+	// AttachSession execs that command line, replacing the test binary, so
+	// it is not run here.
+}
+
+func TestClientOpenWindow(t *testing.T) {
+	s := newTestServer(t)
+	s.attachClient("play")
+
+	if err := s.OpenWindow("remote", []string{"sleep", "60"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"0:sh", "1:remote"}; !slices.Equal(s.windowNames("play"), want) {
+		t.Errorf("windows of play = %q, want %q", s.windowNames("play"), want)
+	}
+	if got := s.client(); got != "play:1.0" {
+		t.Errorf("client is on %s, want the new window play:1.0", got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Session management
+// ---------------------------------------------------------------------------
+
+func TestClientSessions(t *testing.T) {
+	s := newTestServer(t)
+	dir := t.TempDir()
+
+	if err := s.StartServer(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.NewSession("a"); err != nil {
+		t.Fatal(err)
+	}
+	if !s.HasSession("a") {
+		t.Error("HasSession(a) = false after NewSession")
+	}
+	if err := s.NewSession("a"); err == nil {
+		t.Error("NewSession(a) succeeded twice")
+	}
+
+	if err := s.NewSessionInDir("b", dir); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.format("b:", "#{pane_current_path}"); got != dir {
+		t.Errorf("session b starts in %s, want %s", got, dir)
+	}
+
+	if err := s.RenameSession("a", "a2"); err != nil {
+		t.Fatal(err)
+	}
+	if s.HasSession("a") || !s.HasSession("a2") {
+		t.Error("RenameSession(a, a2) didn't rename") // or HasSession matched a prefix
+	}
+	if err := s.RenameSession("nope", "x"); err == nil {
+		t.Error("RenameSession(nope) succeeded")
+	}
+
+	if err := s.KillSession("a2"); err != nil {
+		t.Fatal(err)
+	}
+	if s.HasSession("a2") {
+		t.Error("session a2 still exists after KillSession")
+	}
+}
+
+func TestClientCreateSession(t *testing.T) {
+	s := newTestServer(t)
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	err := s.CreateSession(SessionSpec{Name: "c", Dir: dir, Windows: []WindowSpec{
+		{Name: "one", Command: "echo created-$((20+22))"},
+		{Name: "two", Dir: "sub"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"0:one", "1:two"}; !slices.Equal(s.windowNames("c"), want) {
+		t.Errorf("windows of c = %q, want %q", s.windowNames("c"), want)
+	}
+	if got := s.format("c:1", "#{pane_current_path}"); got != filepath.Join(dir, "sub") {
+		t.Errorf("window two starts in %s, want %s/sub", got, dir)
+	}
+	s.eventually("the command to run", func() bool {
+		out, _ := s.CapturePane("c", 0, 0)
+		return strings.Contains(out, "created-42")
+	})
+}
+
+// ---------------------------------------------------------------------------
+// Window management
+// ---------------------------------------------------------------------------
+
+func TestClientWindows(t *testing.T) {
+	s := newTestServer(t)
+	dir := t.TempDir()
+
+	if err := s.NewWindow("work", "n"); err != nil {
+		t.Fatal(err)
+	}
+	if index, err := s.CreateWindow("work", WindowSpec{Name: "w", Dir: dir}); err != nil || index != 3 {
+		t.Fatalf("CreateWindow() = %d, %v, want index 3", index, err)
+	}
+	if got := s.format("work:3", "#{pane_current_path}"); got != dir {
+		t.Errorf("window w starts in %s, want %s", got, dir)
+	}
+	if err := s.RenameWindow("work", 2, "renamed"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SwapWindow("work", 0, 1); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"0:logs", "1:editor", "2:renamed", "3:w"}; !slices.Equal(s.windowNames("work"), want) {
+		t.Errorf("windows of work = %q, want %q", s.windowNames("work"), want)
+	}
+
+	if err := s.KillWindow("work", 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.MoveWindow("work", 2, "play"); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"0:logs", "1:editor"}; !slices.Equal(s.windowNames("work"), want) {
+		t.Errorf("windows of work = %q, want %q", s.windowNames("work"), want)
+	}
+	if want := []string{"0:sh", "1:renamed"}; !slices.Equal(s.windowNames("play"), want) {
+		t.Errorf("windows of play = %q, want %q", s.windowNames("play"), want)
+	}
+}
+
+func TestClientLinkWindow(t *testing.T) {
+	s := newTestServer(t)
+
+	if err := s.UnlinkWindow("work", 1); err == nil {
+		t.Error("UnlinkWindow succeeded on a window in one session")
+	}
+	if err := s.LinkWindow("work", 1, "play"); err != nil {
+		t.Fatal(err)
+	}
+	windows, err := s.ListWindows("play")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(windows) != 2 {
+		t.Fatalf("play has %d windows after LinkWindow, want 2", len(windows))
+	}
+	w := windows[1]
+	slices.Sort(w.LinkedSessions)
+	if w.Name != "logs" || !w.Linked || !slices.Equal(w.LinkedSessions, []string{"play", "work"}) {
+		t.Errorf("linked window = %+v, want logs linked to play and work", w)
+	}
+
+	if err := s.UnlinkWindow("play", 1); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"0:sh"}; !slices.Equal(s.windowNames("play"), want) {
+		t.Errorf("windows of play = %q, want %q", s.windowNames("play"), want)
+	}
+	if want := []string{"0:editor", "1:logs"}; !slices.Equal(s.windowNames("work"), want) {
+		t.Errorf("windows of work = %q, want %q", s.windowNames("work"), want)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Pane management
+// ---------------------------------------------------------------------------
+
+func TestClientSplitAndKillPane(t *testing.T) {
+	s := newTestServer(t)
+
+	if err := s.SplitWindow("play", -1, -1, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.format("play:0.1", "#{pane_top}"); got == "0" {
+		t.Error("SplitWindow(horizontal=false) put the new pane beside the old one")
+	}
+	if err := s.SplitWindow("work", 1, 0, true); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.format("work:1.1", "#{pane_left}"); got == "0" {
+		t.Error("SplitWindow(horizontal=true) put the new pane below the old one")
+	}
+
+	ids := s.paneIDs("work:1")
+	if err := s.KillPane("work", 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.paneIDs("work:1"); !slices.Equal(got, ids[1:]) {
+		t.Errorf("panes after KillPane = %q, want %q", got, ids[1:])
+	}
+}
+
+func TestClientMovePanes(t *testing.T) {
+	s := newTestServer(t)
+	editor := s.paneIDs("work:0")
+	logs := s.paneIDs("work:1")
+
+	if err := s.SwapPane("work", 0, 0, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.paneIDs("work:0"), []string{editor[1], editor[0]}; !slices.Equal(got, want) {
+		t.Errorf("panes after SwapPane = %q, want %q", got, want)
+	}
+
+	if err := s.JoinPane("work", 0, 0, "play", 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.paneIDs("play:0"); len(got) != 2 || got[1] != editor[1] {
+		t.Errorf("panes of play after JoinPane = %q, want %s last", got, editor[1])
+	}
+
+	if err := s.BreakPane("play", 0, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.paneIDs("play:1"); !slices.Equal(got, []string{editor[1]}) {
+		t.Errorf("panes of the broken-out window = %q, want %s", got, editor[1])
+	}
+	// Breaking out a window's only pane moves the window instead.
+	if err := s.BreakPane("work", 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"0:editor", "2:logs"}; !slices.Equal(s.windowNames("work"), want) {
+		t.Errorf("windows of work = %q, want %q", s.windowNames("work"), want)
+	}
+	if got := s.paneIDs("work:2"); !slices.Equal(got, logs) {
+		t.Errorf("panes of the moved window = %q, want %q", got, logs)
+	}
+}
+
+func TestClientLayout(t *testing.T) {
+	s := newTestServer(t)
+	width := func(pane string) string { return s.format("work:0."+pane, "#{pane_width}") }
+
+	before := width("0")
+	if err := s.ResizePane("work", 0, 0, 5, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := width("0"); got == before {
+		t.Errorf("pane width still %s after ResizePane", got)
+	}
+
+	if err := s.ZoomPane("work", 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.format("work:0", "#{window_zoomed_flag}"); got != "1" {
+		t.Errorf("window_zoomed_flag = %s after ZoomPane, want 1", got)
+	}
+	if err := s.ZoomPane("work", 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.format("work:0", "#{window_zoomed_flag}"); got != "0" {
+		t.Errorf("window_zoomed_flag = %s after a second ZoomPane, want 0", got)
+	}
+
+	if err := s.SelectLayout("work", 0, "even-vertical"); err != nil {
+		t.Fatal(err)
+	}
+	if width("0") != "80" || width("1") != "80" {
+		t.Errorf("pane widths %s and %s after even-vertical, want 80", width("0"), width("1"))
+	}
+	if err := s.SelectLayout("work", 0, "nope"); err == nil {
+		t.Error("SelectLayout(nope) succeeded")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Input and buffers
+// ---------------------------------------------------------------------------
+
+func TestClientSendKeysAndCapture(t *testing.T) {
+	s := newTestServer(t)
+
+	// Key names are typed literally, not sent as keys.
+	if err := s.SendKeys("work", 1, 0, "echo Enter C-c $((6*7))", true); err != nil {
+		t.Fatal(err)
+	}
+	s.eventually("the command to run", func() bool {
+		out, err := s.CapturePane("work", 1, 0)
+		return err == nil && strings.Contains(out, "Enter C-c 42")
+	})
+
+	// A negative window captures the session's active pane.
+	s.run("select-window", "-t", "work:1")
+	out, err := s.CapturePane("work", -1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Enter C-c 42") {
+		t.Errorf("CapturePane(work, -1) = %q, want the active window", out)
+	}
+}
+
+func TestClientSetBuffer(t *testing.T) {
+	s := newTestServer(t)
+	if err := s.SetBuffer("-buffer text"); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.run("show-buffer"); got != "-buffer text" {
+		t.Errorf("show-buffer = %q, want %q", got, "-buffer text")
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	s := newTestServer(t)
+	for _, tc := range []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"duplicate session", s.NewSession("work"), ErrDuplicateSession},
+		{"missing session", s.RenameSession("nope", "x"), ErrSessionNotFound},
+		{"missing window", s.KillWindow("work", 7), ErrWindowNotFound},
+		{"missing pane", s.KillPane("work", 0, 7), ErrPaneNotFound},
+		{"no client", s.SwitchToSession("play"), ErrNoClient},
+	} {
+		if !errors.Is(tc.err, tc.want) {
+			t.Errorf("%s: error %v is not %v", tc.name, tc.err, tc.want)
+		}
+		var terr *Error
+		if !errors.As(tc.err, &terr) || terr.Output == "" {
+			t.Errorf("%s: error %v carries no tmux output", tc.name, tc.err)
+		}
+	}
+
+	s.run("kill-server")
+	if _, err := s.ListSessions(); !errors.Is(err, ErrNoServer) {
+		t.Errorf("ListSessions after kill-server: %v is not ErrNoServer", err)
+	}
+}
+
+func TestClientCapabilities(t *testing.T) {
+	s := newTestServer(t)
+	out, err := exec.Command("tmux", "-V").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.Capabilities(), ParseVersion(string(out)); got != want {
+		t.Errorf("Capabilities() = %+v, want %+v", got, want)
+	}
+	if s.Capabilities().Version == "" {
+		t.Error("Capabilities() has no version")
+	}
+}
@@ -0,0 +1,104 @@
+package tmux
+
+import (
+	"errors"
+	"strings"
+)
+
+// Failures tmux reports, matched with errors.Is against the errors returned
+// by Client methods.
+var (
+	ErrNoServer         = errors.New("no tmux server running")
+	ErrNoClient         = errors.New("no tmux client")
+	ErrSessionNotFound  = errors.New("session not found")
+	ErrWindowNotFound   = errors.New("window not found")
+	ErrPaneNotFound     = errors.New("pane not found")
+	ErrDuplicateSession = errors.New("duplicate session")
+	ErrUnsupported      = errors.New("not supported by this tmux version")
+)
+
+// errorKinds maps fragments of tmux's messages to the errors above. tmux has
+// no error codes; these are the wordings used from 2.x to 3.5.
+var errorKinds = []struct {
+	fragment string
+	err      error
+}{
+	{"no server running", ErrNoServer},
+	{"error connecting to", ErrNoServer},
+	{"server exited unexpectedly", ErrNoServer},
+	{"no current client", ErrNoClient},
+	{"can't find client", ErrNoClient},
+	{"can't find session", ErrSessionNotFound},
+	{"session not found", ErrSessionNotFound},
+	{"can't find window", ErrWindowNotFound},
+	{"window not found", ErrWindowNotFound},
+	{"can't find pane", ErrPaneNotFound},
+	{"pane not found", ErrPaneNotFound},
+	{"duplicate session", ErrDuplicateSession},
+	{"unknown command", ErrUnsupported},
+	{"unknown flag", ErrUnsupported},
+	{"unknown option", ErrUnsupported},
+	{"invalid option", ErrUnsupported},
+	{"unknown format", ErrUnsupported},
+}
+
+// classify returns the error matching tmux's message, or nil.
+func classify(msg string) error {
+	for _, k := range errorKinds {
+		if strings.Contains(msg, k.fragment) {
+			return k.err
+		}
+	}
+	return nil
+}
+
+// Error is a tmux command that failed. It matches the sentinel for what
+// tmux printed (ErrSessionNotFound, ...) and the underlying exec error.
+type Error struct {
+	Host   string   // remote host, empty for a local tmux
+	Args   []string // tmux arguments, without socket flags
+	Output string   // what tmux printed, trimmed
+	Err    error    // how the process failed, e.g. *exec.ExitError
+}
+
+func newError(host string, args []string, output []byte, err error) *Error {
+	return &Error{Host: host, Args: args, Output: strings.TrimSpace(string(output)), Err: err}
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	b.WriteString("tmux")
+	if len(e.Args) > 0 {
+		b.WriteString(" " + e.Args[0])
+	}
+	if e.Host != "" {
+		b.WriteString(" on " + e.Host)
+	}
+	b.WriteString(": ")
+	if e.Output != "" {
+		msg, _, _ := strings.Cut(e.Output, "\n")
+		b.WriteString(msg)
+	} else {
+		b.WriteString(e.Err.Error())
+	}
+	return b.String()
+}
+
+func (e *Error) Unwrap() []error {
+	var errs []error
+	if kind := classify(e.Output); kind != nil {
+		errs = append(errs, kind)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+// replayedError is an error read back from a recording. Only its message
+// survives, so it is classified again from that.
+type replayedError string
+
+func (e replayedError) Error() string { return string(e) }
+
+func (e replayedError) Unwrap() error { return classify(string(e)) }
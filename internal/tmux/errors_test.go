@@ -0,0 +1,20 @@
+package tmux
+
+import "testing"
+
+func TestClassify(t *testing.T) {
+	for msg, want := range map[string]error{
+		"no server running on /tmp/tmux-0/default": ErrNoServer,
+		"no current client":                        ErrNoClient,
+		"can't find client: /dev/pts/9":            ErrNoClient,
+		"can't find session: work":                 ErrSessionNotFound,
+		"can't find window: 7":                     ErrWindowNotFound,
+		"duplicate session: work":                  ErrDuplicateSession,
+		"unknown flag -w":                          ErrUnsupported,
+		"something else":                           nil,
+	} {
+		if got := classify(msg); got != want {
+			t.Errorf("classify(%q) = %v, want %v", msg, got, want)
+		}
+	}
+}
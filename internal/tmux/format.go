@@ -0,0 +1,128 @@
+package tmux
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Queries describe what they read from tmux as structs whose fields are
+// tagged with a format variable:
+//
+//	type row struct {
+//		Name  string `tmux:"session_name"`
+//		Count int    `tmux:"session_windows"`
+//	}
+//
+// formatOf(row{}) is the -F argument requesting exactly those variables and
+// parseRows decodes the output into one row per line; a line without a value
+// for every variable is an error. Values are decoded by
+// the field's type: string as is, int as a decimal, bool as a non-zero
+// number (flags are 0 or 1, session_attached counts clients), time.Time as
+// unix seconds and []string as a comma-separated list. An empty value —
+// also what tmux prints for a variable it doesn't know — leaves the zero
+// value, and so does one that doesn't parse. Fields tagged with the same
+// variable share one value.
+
+// fieldSep separates the values in a line of output. It is the ASCII unit
+// separator, which can't be typed into a name or a title, unlike "|". tmux
+// prints control characters as "_" unless it believes the terminal handles
+// UTF-8, which is why every command is run with -u.
+const fieldSep = "\x1f"
+
+// column is a tagged struct field and the position of its variable in a
+// line of output.
+type column struct {
+	index []int // for reflect.Value.FieldByIndex
+	value int
+}
+
+// schemaOf returns the variables requested for struct type t, each once, and
+// the columns filled from them. Embedded structs contribute their tagged
+// fields, so one row can combine a Session, a Window and a Pane.
+func schemaOf(t reflect.Type) (vars []string, cols []column) {
+	pos := map[string]int{}
+	var walk func(t reflect.Type, prefix []int)
+	walk = func(t reflect.Type, prefix []int) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			index := append(append([]int(nil), prefix...), i)
+			v := f.Tag.Get("tmux")
+			if v == "" {
+				if f.Anonymous && f.Type.Kind() == reflect.Struct {
+					walk(f.Type, index)
+				}
+				continue
+			}
+			if _, ok := pos[v]; !ok {
+				pos[v] = len(vars)
+				vars = append(vars, v)
+			}
+			cols = append(cols, column{index: index, value: pos[v]})
+		}
+	}
+	walk(t, nil)
+	return vars, cols
+}
+
+// formatOf returns the format requesting the tagged fields of row.
+func formatOf(row any) string {
+	vars, _ := schemaOf(reflect.TypeOf(row))
+	for i, v := range vars {
+		vars[i] = "#{" + v + "}"
+	}
+	return strings.Join(vars, fieldSep)
+}
+
+// parseRows decodes output printed with formatOf(T{}). Blank lines are
+// skipped; a line with the wrong number of values means the output isn't
+// what was asked for, and is an error.
+func parseRows[T any](output string) ([]T, error) {
+	vars, cols := schemaOf(reflect.TypeFor[T]())
+	var rows []T
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+		values := strings.Split(line, fieldSep)
+		if len(values) != len(vars) {
+			return nil, fmt.Errorf("unexpected tmux output %q: %d values, want %d", line, len(values), len(vars))
+		}
+		var row T
+		v := reflect.ValueOf(&row).Elem()
+		for _, c := range cols {
+			setField(v.FieldByIndex(c.index), values[c.value])
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+func setField(f reflect.Value, value string) {
+	if value == "" {
+		return
+	}
+	switch {
+	case f.Type() == timeType:
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil && n > 0 {
+			f.Set(reflect.ValueOf(time.Unix(n, 0)))
+		}
+	case f.Kind() == reflect.String:
+		f.SetString(value)
+	case f.Kind() == reflect.Int:
+		if n, err := strconv.Atoi(value); err == nil {
+			f.SetInt(int64(n))
+		}
+	case f.Kind() == reflect.Bool:
+		n, err := strconv.Atoi(value)
+		f.SetBool(err == nil && n != 0)
+	case f.Kind() == reflect.Slice && f.Type().Elem().Kind() == reflect.String:
+		f.Set(reflect.ValueOf(strings.Split(value, ",")))
+	default:
+		panic("tmux: cannot decode a format variable into " + f.Type().String())
+	}
+}
@@ -0,0 +1,41 @@
+package tmux
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFormat(t *testing.T) {
+	type row struct {
+		Name     string    `tmux:"session_name"`
+		Windows  int       `tmux:"session_windows"`
+		Attached bool      `tmux:"session_attached"`
+		Created  time.Time `tmux:"session_created"`
+		Sessions []string  `tmux:"window_linked_sessions_list"`
+		Server   string    // not requested
+	}
+	if got, want := formatOf(row{}), "#{session_name}\x1f#{session_windows}\x1f#{session_attached}\x1f#{session_created}\x1f#{window_linked_sessions_list}"; got != want {
+		t.Errorf("formatOf = %q, want %q", got, want)
+	}
+
+	output := "a|b\x1f2\x1f1\x1f1700000000\x1fa|b,c\n" +
+		"\n" +
+		"empty\x1f\x1f\x1f\x1f\n" +
+		"junk\x1fx\x1f2\x1f-1\x1f\n"
+	want := []row{
+		{Name: "a|b", Windows: 2, Attached: true, Created: time.Unix(1700000000, 0), Sessions: []string{"a|b", "c"}},
+		{Name: "empty"},
+		{Name: "junk", Attached: true},
+	}
+	if got, err := parseRows[row](output); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("parseRows = %+v, %v, want %+v", got, err, want)
+	}
+
+	// What tmux prints when it replaces the separator, or a wrong format.
+	for _, bad := range []string{"short\x1f1\n", "long\x1f1\x1f0\x1f0\x1f\x1fextra\n", "a_2_1_0_\n"} {
+		if got, err := parseRows[row](output + bad); err == nil {
+			t.Errorf("parseRows(%q) = %+v, want an error", bad, got)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package tmux
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Record is one tmux invocation and its result, as stored in a recording.
+// A recording is a JSONL file with one Record per line, in call order.
+type Record struct {
+	Server string   `json:"server,omitempty"` // see Tap; empty for the current server
+	Args   []string `json:"args"`
+	Output string   `json:"output"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// tap, when set, wraps the executor of every Client created by NewClient,
+// NewSocketClient and NewRemoteClient.
+var tap func(server string, e Executor) Executor
+
+// Tap makes every Client created from now on run its commands through
+// wrap(server, executor), where server is "" for the current server, the
+// socket for socket clients and "ssh:host" for remote ones. Recorder.Wrap
+// and Replayer.Wrap fit; nil restores the plain executors.
+func Tap(wrap func(server string, e Executor) Executor) {
+	tap = wrap
+}
+
+// tapped applies tap to e, if set.
+func tapped(server string, e Executor) Executor {
+	if tap == nil {
+		return e
+	}
+	return tap(server, e)
+}
+
+// ---------------------------------------------------------------------------
+// Recording
+// ---------------------------------------------------------------------------
+
+// Recorder appends every command run through the executors it wraps to w.
+// It is safe for concurrent use.
+type Recorder struct {
+	// Redact masks pane contents (capture-pane output) in the recording,
+	// keeping only its shape: line lengths and whitespace.
+	Redact bool
+
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewRecorder creates a Recorder that writes JSONL to w.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{enc: json.NewEncoder(w)}
+}
+
+// Wrap returns next, with its commands recorded under server.
+func (r *Recorder) Wrap(server string, next Executor) Executor {
+	return &recordingExecutor{rec: r, server: server, next: next}
+}
+
+func (r *Recorder) write(rec Record) {
+	if r.Redact && len(rec.Args) > 0 && rec.Args[0] == "capture-pane" {
+		rec.Output = redact(rec.Output)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.enc.Encode(rec) // best effort: a broken recording must not break tswitch
+}
+
+// redact replaces every visible character of s with x.
+func redact(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return r
+		}
+		return 'x'
+	}, s)
+}
+
+type recordingExecutor struct {
+	rec    *Recorder
+	server string
+	next   Executor
+}
+
+func (e *recordingExecutor) Run(args ...string) (string, error) {
+	out, err := e.next.Run(args...)
+	rec := Record{Server: e.server, Args: args, Output: out}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	e.rec.write(rec)
+	return out, err
+}
+
+func (e *recordingExecutor) Command(args ...string) []string {
+	if b, ok := e.next.(CommandBuilder); ok {
+		return b.Command(args...)
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Replay
+// ---------------------------------------------------------------------------
+
+// ReadRecords parses a recording.
+func ReadRecords(r io.Reader) ([]Record, error) {
+	var records []Record
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 64<<20) // a capture of a big pane is one long line
+	for n := 1; sc.Scan(); n++ {
+		if len(strings.TrimSpace(sc.Text())) == 0 {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		records = append(records, rec)
+	}
+	return records, sc.Err()
+}
+
+// LoadRecords reads the recording at path.
+func LoadRecords(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	records, err := ReadRecords(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return records, nil
+}
+
+// Replayer answers tmux commands from a recording instead of running them.
+// Each command gets the results recorded for the same server and arguments
+// in order; once they are used up, the last one is repeated, so refreshes
+// keep seeing the final state. Commands that were never recorded fail.
+// It is safe for concurrent use.
+type Replayer struct {
+	mu      sync.Mutex
+	pending map[string][]Record
+	last    map[string]Record
+}
+
+// NewReplayer creates a Replayer for records.
+func NewReplayer(records []Record) *Replayer {
+	p := &Replayer{pending: map[string][]Record{}, last: map[string]Record{}}
+	for _, rec := range records {
+		k := replayKey(rec.Server, rec.Args)
+		p.pending[k] = append(p.pending[k], rec)
+	}
+	return p
+}
+
+// Wrap returns an executor replaying the commands recorded for server. The
+// executor being replaced is never run.
+func (p *Replayer) Wrap(server string, _ Executor) Executor {
+	return &replayExecutor{replayer: p, server: server}
+}
+
+func replayKey(server string, args []string) string {
+	return server + "\x00" + strings.Join(args, "\x00")
+}
+
+func (p *Replayer) next(server string, args []string) (Record, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	k := replayKey(server, args)
+	if q := p.pending[k]; len(q) > 0 {
+		p.pending[k] = q[1:]
+		p.last[k] = q[0]
+		return q[0], true
+	}
+	rec, ok := p.last[k]
+	return rec, ok
+}
+
+type replayExecutor struct {
+	replayer *Replayer
+	server   string
+}
+
+func (e *replayExecutor) Run(args ...string) (string, error) {
+	rec, ok := e.replayer.next(e.server, args)
+	if !ok {
+		return "", fmt.Errorf("no recording of tmux %s", ShellJoin(args))
+	}
+	if rec.Error != "" {
+		return rec.Output, replayedError(rec.Error)
+	}
+	return rec.Output, nil
+}
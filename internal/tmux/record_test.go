@@ -0,0 +1,113 @@
+package tmux
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// scriptExecutor answers commands from a map keyed by the joined args.
+type scriptExecutor map[string]string
+
+func (s scriptExecutor) Run(args ...string) (string, error) {
+	out, ok := s[strings.Join(args, " ")]
+	if !ok {
+		return "", errors.New("unknown command")
+	}
+	return out, nil
+}
+
+func TestRecordReplay(t *testing.T) {
+	var buf bytes.Buffer
+	rec := NewRecorder(&buf)
+	e := rec.Wrap("sock", scriptExecutor{
+		"has-session -t =work": "",
+		"capture-pane -p":      "$ echo secret\nsecret\n",
+	})
+	e.Run("has-session", "-t", "=work")
+	e.Run("capture-pane", "-p")
+	e.Run("has-session", "-t", "=play")
+
+	records, err := ReadRecords(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Record{
+		{Server: "sock", Args: []string{"has-session", "-t", "=work"}},
+		{Server: "sock", Args: []string{"capture-pane", "-p"}, Output: "$ echo secret\nsecret\n"},
+		{Server: "sock", Args: []string{"has-session", "-t", "=play"}, Error: "unknown command"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Fatalf("records = %+v, want %+v", records, want)
+	}
+
+	r := NewReplayer(records).Wrap("sock", nil)
+	for _, w := range want {
+		out, err := r.Run(w.Args...)
+		if out != w.Output || (err == nil) != (w.Error == "") {
+			t.Errorf("Run(%v) = %q, %v, want %q, %q", w.Args, out, err, w.Output, w.Error)
+		}
+	}
+	if out, _ := r.Run("capture-pane", "-p"); out != want[1].Output {
+		t.Errorf("repeated Run = %q, want the last result %q", out, want[1].Output)
+	}
+	if _, err := r.Run("kill-server"); err == nil {
+		t.Error("Run(kill-server) succeeded without a recording")
+	}
+	if _, err := NewReplayer(records).Wrap("other", nil).Run("capture-pane", "-p"); err == nil {
+		t.Error("Run on another server used this server's recording")
+	}
+}
+
+func TestRecordRedact(t *testing.T) {
+	var buf bytes.Buffer
+	rec := NewRecorder(&buf)
+	rec.Redact = true
+	e := rec.Wrap("", scriptExecutor{
+		"capture-pane -p":                "pass: hunter2\n  秘密\n",
+		"display-message -p #{pane_pid}": "42\n",
+	})
+	e.Run("capture-pane", "-p")
+	e.Run("display-message", "-p", "#{pane_pid}")
+
+	records, err := ReadRecords(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := records[0].Output, "xxxxx xxxxxxx\n  xx\n"; got != want {
+		t.Errorf("capture = %q, want %q", got, want)
+	}
+	if got := records[1].Output; got != "42\n" {
+		t.Errorf("display-message = %q, want it kept", got)
+	}
+}
+
+// TestReplayClient records a real server and checks a Client replaying the
+// recording sees the same thing.
+func TestReplayClient(t *testing.T) {
+	s := newTestServer(t)
+	var buf bytes.Buffer
+	live := NewClientWith(NewRecorder(&buf).Wrap("", s.exec))
+	sessions, err := live.ListSessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	windows, err := live.ListWindows("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := ReadRecords(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay := NewClientWith(NewReplayer(records).Wrap("", nil))
+	if got, err := replay.ListSessions(); err != nil || !reflect.DeepEqual(got, sessions) {
+		t.Errorf("replayed ListSessions = %+v, %v, want %+v", got, err, sessions)
+	}
+	if got, err := replay.ListWindows("work"); err != nil || !reflect.DeepEqual(got, windows) {
+		t.Errorf("replayed ListWindows = %+v, %v, want %+v", got, err, windows)
+	}
+}
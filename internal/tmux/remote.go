@@ -0,0 +1,54 @@
+package tmux
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// sshExecutor runs tmux commands on a remote host. All invocations share one
+// multiplexed ssh connection (ControlMaster), so only the first command pays
+// for the handshake and later ones — including other tswitch runs within
+// ControlPersist — reuse it.
+type sshExecutor struct {
+	host string // ssh destination, e.g. "user@devbox" or a Host alias
+}
+
+// sshControlOptions are shared by batch commands and interactive attaches so
+// both ride on the same master connection.
+func sshControlOptions() []string {
+	return []string{
+		"-o", "ControlMaster=auto",
+		"-o", "ControlPath=" + filepath.Join(os.TempDir(), "tswitch-ssh-%C"),
+		"-o", "ControlPersist=10m",
+	}
+}
+
+func (e *sshExecutor) Run(args ...string) (string, error) {
+	argv := append(sshControlOptions(),
+		"-o", "BatchMode=yes", // never prompt from inside the TUI
+		"-o", "ConnectTimeout=5",
+		e.host, "--", ShellJoin(append([]string{"tmux", "-u"}, args...))) // -u: see fieldSep
+	cmd := exec.Command("ssh", argv...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", newError(e.host, args, output, err)
+	}
+	return string(output), nil
+}
+
+// Command returns an interactive ssh command line (with a tty) running tmux
+// with args on the remote host.
+func (e *sshExecutor) Command(args ...string) []string {
+	argv := append([]string{"ssh"}, sshControlOptions()...)
+	return append(argv, "-t", e.host, "--", ShellJoin(append([]string{"tmux"}, args...)))
+}
+
+// NewRemoteClient creates a Client that runs tmux on host over ssh.
+func NewRemoteClient(host string) *Client {
+	return &Client{
+		exec:   tapped("ssh:"+host, &sshExecutor{host: host}),
+		inTmux: os.Getenv("TMUX") != "",
+		remote: true,
+	}
+}
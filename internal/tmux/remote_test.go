@@ -0,0 +1,75 @@
+package tmux
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeSSH puts an ssh on PATH that logs its arguments and runs the remote
+// command locally, and returns the log's path.
+func fakeSSH(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	log := filepath.Join(dir, "ssh.log")
+	script := `#!/bin/sh
+echo "$@" >> "` + log + `"
+while [ "$1" != "--" ]; do shift; done
+shift
+exec sh -c "$*"
+`
+	if err := os.WriteFile(filepath.Join(dir, "ssh"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return log
+}
+
+func TestRemoteClient(t *testing.T) {
+	s := newTestServer(t)
+	log := fakeSSH(t)
+	// The "remote" tmux is the default server under the test's TMUX_TMPDIR.
+	out, err := exec.Command("tmux", "-f", "/dev/null", "new-session", "-d", "-s", "far", "-x", "80", "-y", "24").CombinedOutput()
+	if err != nil {
+		t.Fatalf("starting tmux: %v: %s", err, out)
+	}
+	t.Cleanup(func() { exec.Command("tmux", "kill-server").Run() })
+
+	c := NewRemoteClient("devbox")
+	sessions, err := c.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].Name != "far" || len(sessions[0].Windows) != 1 {
+		t.Fatalf("Snapshot() = %+v, want session far with one window", sessions)
+	}
+	if sessions[0].ActivePanePID != 0 || sessions[0].Windows[0].Panes[0].PID != 0 {
+		t.Errorf("remote PIDs kept: %+v", sessions[0])
+	}
+
+	calls, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"BatchMode=yes", "ConnectTimeout=5", "ControlMaster=auto", "devbox -- tmux -u list-panes -a -F"} {
+		if !strings.Contains(string(calls), want) {
+			t.Errorf("ssh called with %q, want %q in it", calls, want)
+		}
+	}
+
+	err = c.RenameSession("missing", "x")
+	if !errors.Is(err, ErrSessionNotFound) || !strings.Contains(err.Error(), "on devbox") {
+		t.Errorf("RenameSession(missing) error = %v, want ErrSessionNotFound on devbox", err)
+	}
+	if _, err := s.ListSessions(); err != nil {
+		t.Errorf("local server affected: %v", err)
+	}
+
+	argv := c.AttachCommand("far")
+	if argv[0] != "ssh" || !strings.Contains(strings.Join(argv, " "), "-t devbox -- tmux attach-session -t far") {
+		t.Errorf("AttachCommand(far) = %q", argv)
+	}
+}
@@ -0,0 +1,100 @@
+package tmux
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Server is a tmux server reachable through its own socket.
+type Server struct {
+	Name    string  // display label, e.g. the socket basename; empty for the current server
+	Socket  string  // socket path; empty when it could not be resolved
+	Service Service // client bound to this server
+	Remote  bool    // reached over ssh
+}
+
+// ID identifies the server in sessions, marks and saved state: the socket
+// path of a local server, since sockets in different directories may share
+// a name, or the name of a remote host. It is empty for the current server.
+func (s Server) ID() string {
+	if s.Name == "" || s.Remote || s.Socket == "" {
+		return s.Name
+	}
+	return s.Socket
+}
+
+// SocketDir returns the directory tmux creates its sockets in:
+// $TMUX_TMPDIR/tmux-UID, falling back to /tmp/tmux-UID.
+func SocketDir() string {
+	base := os.Getenv("TMUX_TMPDIR")
+	if base == "" {
+		base = "/tmp"
+	}
+	return filepath.Join(base, fmt.Sprintf("tmux-%d", os.Getuid()))
+}
+
+// CurrentSocket returns the socket path of the server tswitch talks to by
+// default: the one in $TMUX when running inside tmux, the default socket
+// otherwise.
+func CurrentSocket() string {
+	if env := os.Getenv("TMUX"); env != "" {
+		if i := strings.IndexByte(env, ','); i > 0 {
+			return env[:i]
+		}
+		return env
+	}
+	return filepath.Join(SocketDir(), "default")
+}
+
+// DiscoverServers returns the current server followed by every other server
+// found in SocketDir and in extra. Entries in extra are socket paths (tmux -S)
+// when they contain a slash and socket names (tmux -L) otherwise. The current
+// server is served by current; the others get their own socket clients.
+func DiscoverServers(current Service, extra []string) []Server {
+	home := CurrentSocket()
+	servers := []Server{{Socket: home, Service: current}}
+	seen := map[string]bool{home: true}
+
+	var sockets []string
+	if entries, err := os.ReadDir(SocketDir()); err == nil {
+		for _, e := range entries {
+			if e.Type()&os.ModeSocket != 0 {
+				sockets = append(sockets, filepath.Join(SocketDir(), e.Name()))
+			}
+		}
+	}
+	sort.Strings(sockets)
+	for _, s := range extra {
+		if !strings.Contains(s, "/") {
+			s = filepath.Join(SocketDir(), s)
+		}
+		sockets = append(sockets, s)
+	}
+
+	for _, path := range sockets {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		servers = append(servers, Server{
+			Name:    filepath.Base(path),
+			Socket:  path,
+			Service: NewSocketClient(path),
+		})
+	}
+
+	// Sockets sharing a name in different directories are labelled by path.
+	count := make(map[string]int)
+	for _, srv := range servers[1:] {
+		count[srv.Name]++
+	}
+	for i := 1; i < len(servers); i++ {
+		if count[servers[i].Name] > 1 {
+			servers[i].Name = servers[i].Socket
+		}
+	}
+	return servers
+}
@@ -0,0 +1,28 @@
+package tmux
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDiscoverServersIdentifiesSocketsByPath(t *testing.T) {
+	t.Setenv("TMUX_TMPDIR", t.TempDir())
+	t.Setenv("TMUX", "")
+
+	servers := DiscoverServers(nil, []string{"/a/x", "/b/x", "y"})
+	y := filepath.Join(SocketDir(), "y")
+	want := []struct{ id, name string }{
+		{"", ""},
+		{"/a/x", "/a/x"}, // same basename: labelled by path
+		{"/b/x", "/b/x"},
+		{y, "y"},
+	}
+	if len(servers) != len(want) {
+		t.Fatalf("got %d servers, want %d", len(servers), len(want))
+	}
+	for i, w := range want {
+		if got := servers[i]; got.ID() != w.id || got.Name != w.name {
+			t.Errorf("server %d = id %q name %q, want id %q name %q", i, got.ID(), got.Name, w.id, w.name)
+		}
+	}
+}
@@ -0,0 +1,1086 @@
+// Package tmuxtest provides an in-memory tmux server for tests.
+package tmuxtest
+
+import (
+	"cmp"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luytbq/tswitch/internal/tmux"
+)
+
+// Compile-time check: Fake must satisfy tmux.Service.
+var _ tmux.Service = (*Fake)(nil)
+
+// Fake is a stateful tmux server in memory. It keeps sessions, windows and
+// panes the way tmux does: windows are linked into sessions at an index and
+// may be linked into several, pane indexes are positions within the window,
+// and killing the last pane or window destroys its parent. One client can
+// be attached; the navigation methods move it.
+//
+// Sessions, windows and panes are set up with AddSession, AddPanes and
+// SetContent, which panic on bad targets. Everything else reports errors
+// worded like tmux's own.
+type Fake struct {
+	mu sync.Mutex
+
+	InTmux          bool   // IsInTmux result; the client can only switch when set
+	Client          string // session the client is attached to; "" = none
+	BaseIndex       int    // base-index option
+	RenumberWindows bool   // renumber-windows option
+	Buffer          string // last SetBuffer text
+	Shell           string // command of new panes
+	Dir             string // directory of new panes without -c
+	Width, Height   int    // size of every window
+
+	sessions []*session // in creation order
+	last     string     // previous client session, for SwitchToLast
+	running  bool       // server running; it exits with its last session
+	clock    time.Time  // advanced by every change of the client
+	nextWin  int        // window ids (@n)
+	nextPane int        // pane ids (%n)
+	nextPID  int
+}
+
+type session struct {
+	name         string
+	dir          string
+	created      time.Time
+	lastAttached time.Time
+	links        []*winlink // sorted by index
+	current      *window
+}
+
+// winlink is a window at an index of one session.
+type winlink struct {
+	index  int
+	window *window
+}
+
+type window struct {
+	id     int
+	name   string
+	panes  []*pane // pane index order
+	active *pane
+	layout string // preset the panes are arranged by
+	zoomed bool
+}
+
+type pane struct {
+	id      int
+	cmd     string
+	dir     string
+	title   string
+	pid     int
+	w, h    int // size, kept by arrange
+	content strings.Builder
+}
+
+// Start is the time of a new server's clock. Each change of the client
+// advances it by a second.
+var Start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// New returns a running server without sessions, with tmux's defaults.
+func New() *Fake {
+	return &Fake{
+		Shell:   "bash",
+		Dir:     "/home/user",
+		Width:   80,
+		Height:  24,
+		running: true,
+		clock:   Start,
+		nextPID: 1000,
+	}
+}
+
+// errorf returns an error worded like tmux's, which matches the tmux.Err*
+// sentinels as the real client's errors do.
+func errorf(format string, a ...any) error {
+	return &tmux.Error{Output: fmt.Sprintf(format, a...)}
+}
+
+// Capabilities reports a current tmux.
+func (f *Fake) Capabilities() tmux.Capabilities {
+	return tmux.ParseVersion("tmux 3.5")
+}
+
+// ---------------------------------------------------------------------------
+// Setup
+// ---------------------------------------------------------------------------
+
+// AddSession creates a session with one single-pane window per name, or a
+// single window named after the shell.
+func (f *Fake) AddSession(name string, windows ...string) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, err := f.newSession(name, "")
+	must(err)
+	for i, w := range windows {
+		if i == 0 {
+			s.links[0].window.name = w
+			continue
+		}
+		f.newWindow(s, w, s.dir)
+	}
+	return f
+}
+
+// AddPanes splits a window n times, as split-window does.
+func (f *Fake) AddPanes(sessionName string, windowIndex, n int) *Fake {
+	for range n {
+		must(f.SplitWindow(sessionName, windowIndex, -1, true))
+	}
+	return f
+}
+
+// SetContent replaces what capture-pane shows for a pane.
+func (f *Fake) SetContent(sessionName string, windowIndex, paneIndex int, text string) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, _, p, err := f.findPane(sessionName, windowIndex, paneIndex)
+	must(err)
+	p.content.Reset()
+	p.content.WriteString(text)
+	return f
+}
+
+// Attach attaches the client to a session from inside tmux.
+func (f *Fake) Attach(sessionName string) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, err := f.findSession(sessionName)
+	must(err)
+	f.InTmux = true
+	f.attach(s)
+	return f
+}
+
+// ClientTarget returns the client's session:window.pane, or "" when no
+// client is attached.
+func (f *Fake) ClientTarget() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, err := f.findSession(f.Client)
+	if err != nil {
+		return ""
+	}
+	w := s.current
+	return fmt.Sprintf("%s:%d.%d", s.name, s.linkOf(w).index, w.paneIndex(w.active))
+}
+
+// Dump describes every session, one per line in name order, as
+// "name index:window[pane ids]...", e.g. "work 0:editor[%0,%2] 1:logs[%1]".
+// Pane ids follow panes when they move between windows.
+func (f *Fake) Dump() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var lines []string
+	for _, s := range f.sorted() {
+		parts := []string{s.name}
+		for _, wl := range s.links {
+			ids := make([]string, len(wl.window.panes))
+			for i, p := range wl.window.panes {
+				ids[i] = fmt.Sprintf("%%%d", p.id)
+			}
+			parts = append(parts, fmt.Sprintf("%d:%s[%s]", wl.index, wl.window.name, strings.Join(ids, ",")))
+		}
+		lines = append(lines, strings.Join(parts, " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func must(err error) {
+	if err != nil {
+		panic("tmuxtest: " + err.Error())
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Queries
+// ---------------------------------------------------------------------------
+
+func (f *Fake) IsInTmux() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.InTmux
+}
+
+// Snapshot returns ListSessions with each session's windows and their panes
+// filled in.
+func (f *Fake) Snapshot() ([]tmux.Session, error) {
+	sessions, err := f.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+	for i, s := range sessions {
+		windows, err := f.ListWindows(s.Name)
+		if err != nil {
+			return nil, err
+		}
+		for j, w := range windows {
+			if windows[j].Panes, err = f.ListPanes(s.Name, w.Index); err != nil {
+				return nil, err
+			}
+		}
+		sessions[i].Windows = windows
+	}
+	return sessions, nil
+}
+
+// ListSessions returns the sessions most recently attached first, like
+// Client.
+func (f *Fake) ListSessions() ([]tmux.Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.running {
+		return nil, errorf("no server running")
+	}
+	var sessions []tmux.Session
+	for _, s := range f.sorted() {
+		w := s.current
+		panes := 0
+		for _, wl := range s.links {
+			panes += len(wl.window.panes)
+		}
+		sessions = append(sessions, tmux.Session{
+			Name:            s.name,
+			WindowCount:     len(s.links),
+			PaneCount:       panes,
+			Attached:        s.name == f.Client,
+			Created:         s.created,
+			LastActive:      s.lastAttached,
+			Width:           f.Width,
+			Height:          f.Height,
+			ActivePaneDir:   w.active.dir,
+			ActivePaneCmd:   w.active.cmd,
+			ActivePaneTitle: w.active.title,
+			ActivePanePID:   w.active.pid,
+		})
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].LastActive.After(sessions[j].LastActive)
+	})
+	return sessions, nil
+}
+
+func (f *Fake) ListWindows(sessionName string) ([]tmux.Window, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, err := f.findSession(sessionName)
+	if err != nil {
+		return nil, err
+	}
+	windows := make([]tmux.Window, len(s.links))
+	for i, wl := range s.links {
+		w := wl.window
+		windows[i] = tmux.Window{
+			Index:           wl.index,
+			Name:            w.name,
+			PaneCount:       len(w.panes),
+			Active:          w == s.current,
+			Layout:          w.layoutString(f.Width, f.Height),
+			WorkingDir:      w.active.dir,
+			ActivePaneCmd:   w.active.cmd,
+			ActivePaneTitle: w.active.title,
+			ActivePanePID:   w.active.pid,
+		}
+		if linked := f.sessionsOf(w); len(linked) > 1 {
+			windows[i].Linked = true
+			windows[i].LinkedSessions = linked
+		}
+	}
+	return windows, nil
+}
+
+func (f *Fake) ListAllWindowNames() (map[string][]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	result := make(map[string][]string)
+	for _, s := range f.sessions {
+		for _, wl := range s.links {
+			result[s.name] = append(result[s.name], wl.window.name)
+		}
+	}
+	return result, nil
+}
+
+func (f *Fake) ListAllPaneCounts() (map[string]int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	result := make(map[string]int)
+	for _, s := range f.sessions {
+		for _, wl := range s.links {
+			result[s.name] += len(wl.window.panes)
+		}
+	}
+	return result, nil
+}
+
+func (f *Fake) ListPanes(sessionName string, windowIndex int) ([]tmux.Pane, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, wl, err := f.findWindow(sessionName, windowIndex)
+	if err != nil {
+		return nil, err
+	}
+	w := wl.window
+	panes := make([]tmux.Pane, len(w.panes))
+	for i, p := range w.panes {
+		panes[i] = tmux.Pane{
+			ID:         fmt.Sprintf("%%%d", p.id),
+			Index:      i,
+			Active:     p == w.active,
+			Width:      p.w,
+			Height:     p.h,
+			Command:    p.cmd,
+			WorkingDir: p.dir,
+			Title:      p.title,
+			PID:        p.pid,
+		}
+	}
+	return panes, nil
+}
+
+// CapturePane returns the text set with SetContent plus everything sent
+// with SendKeys. A negative windowIndex captures the session's active pane.
+func (f *Fake) CapturePane(sessionName string, windowIndex int, paneIndex int) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if windowIndex < 0 {
+		s, err := f.findSession(sessionName)
+		if err != nil {
+			return "", err
+		}
+		return s.current.active.content.String(), nil
+	}
+	_, _, p, err := f.findPane(sessionName, windowIndex, paneIndex)
+	if err != nil {
+		return "", err
+	}
+	return p.content.String(), nil
+}
+
+// ---------------------------------------------------------------------------
+// Navigation
+// ---------------------------------------------------------------------------
+
+func (f *Fake) SwitchToSession(sessionName string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, err := f.switchable(sessionName)
+	if err != nil {
+		return err
+	}
+	f.attach(s)
+	return nil
+}
+
+func (f *Fake) SwitchToLast() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.switchable(f.Client); err != nil {
+		return err
+	}
+	s, err := f.findSession(f.last)
+	if err != nil {
+		return errorf("can't find last session")
+	}
+	f.attach(s)
+	return nil
+}
+
+func (f *Fake) SwitchClient(sessionName string, windowIndex int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.switchable(sessionName); err != nil {
+		return err
+	}
+	s, wl, err := f.findWindow(sessionName, windowIndex)
+	if err != nil {
+		return err
+	}
+	s.current = wl.window
+	f.attach(s)
+	return nil
+}
+
+func (f *Fake) SelectPane(sessionName string, windowIndex int, paneIndex int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.switchable(sessionName); err != nil {
+		return err
+	}
+	s, wl, p, err := f.findPane(sessionName, windowIndex, paneIndex)
+	if err != nil {
+		return err
+	}
+	s.current = wl.window
+	wl.window.active = p
+	f.attach(s)
+	return nil
+}
+
+// AttachSession attaches the client as if tswitch had exec'd tmux.
+func (f *Fake) AttachSession(sessionName string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, err := f.findSession(sessionName)
+	if err != nil {
+		return err
+	}
+	f.attach(s)
+	return nil
+}
+
+func (f *Fake) AttachCommand(target string) []string {
+	return []string{"tmux", "attach-session", "-t", target}
+}
+
+// switchable checks that the client can switch to sessionName.
+func (f *Fake) switchable(sessionName string) (*session, error) {
+	if !f.InTmux || f.Client == "" {
+		return nil, errorf("no current client")
+	}
+	return f.findSession(sessionName)
+}
+
+func (f *Fake) attach(s *session) {
+	if f.Client != s.name {
+		f.last = f.Client
+	}
+	f.Client = s.name
+	s.lastAttached = f.tick()
+}
+
+// ---------------------------------------------------------------------------
+// Session management
+// ---------------------------------------------------------------------------
+
+func (f *Fake) StartServer() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.running = true
+	return nil
+}
+
+func (f *Fake) NewSession(sessionName string) error {
+	return f.NewSessionInDir(sessionName, "")
+}
+
+func (f *Fake) NewSessionInDir(sessionName string, dir string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, err := f.newSession(sessionName, dir)
+	return err
+}
+
+// CreateSession creates the session and its windows; commands are typed
+// into the windows' shells.
+func (f *Fake) CreateSession(spec tmux.SessionSpec) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	windows := spec.Windows
+	if len(windows) == 0 {
+		windows = []tmux.WindowSpec{{}}
+	}
+	s, err := f.newSession(spec.Name, joinDir(spec.Dir, windows[0].Dir))
+	if err != nil {
+		return err
+	}
+	s.dir = cmp.Or(spec.Dir, f.Dir)
+	for i, ws := range windows {
+		w := s.links[0].window
+		if i > 0 {
+			w = f.newWindow(s, "", joinDir(s.dir, ws.Dir))
+		}
+		w.name = cmp.Or(ws.Name, w.name)
+		if ws.Command != "" {
+			w.active.content.WriteString(ws.Command + "\n")
+		}
+	}
+	return nil
+}
+
+func (f *Fake) HasSession(sessionName string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, err := f.findSession(sessionName)
+	return err == nil
+}
+
+func (f *Fake) RenameSession(oldName, newName string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, err := f.findSession(oldName)
+	if err != nil {
+		return err
+	}
+	if err := f.checkName(newName); err != nil {
+		return err
+	}
+	if f.Client == oldName {
+		f.Client = newName
+	}
+	if f.last == oldName {
+		f.last = newName
+	}
+	s.name = newName
+	return nil
+}
+
+// KillSession destroys a session and the windows linked only to it. An
+// attached client moves to another session, or detaches if none is left.
+func (f *Fake) KillSession(sessionName string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, err := f.findSession(sessionName)
+	if err != nil {
+		return err
+	}
+	f.destroySession(s)
+	return nil
+}
+
+func (f *Fake) newSession(name, dir string) (*session, error) {
+	if name == "" {
+		for i := 0; ; i++ {
+			if _, err := f.findSession(strconv.Itoa(i)); err != nil {
+				name = strconv.Itoa(i)
+				break
+			}
+		}
+	}
+	if err := f.checkName(name); err != nil {
+		return nil, err
+	}
+	now := f.tick()
+	s := &session{name: name, dir: cmp.Or(dir, f.Dir), created: now, lastAttached: now}
+	f.sessions = append(f.sessions, s)
+	f.running = true
+	s.current = f.newWindow(s, "", s.dir)
+	return s, nil
+}
+
+func (f *Fake) checkName(name string) error {
+	if strings.ContainsAny(name, ":.") {
+		return errorf("invalid session: %s", name)
+	}
+	if _, err := f.findSession(name); err == nil {
+		return errorf("duplicate session: %s", name)
+	}
+	return nil
+}
+
+func (f *Fake) destroySession(s *session) {
+	f.sessions = slices.DeleteFunc(f.sessions, func(x *session) bool { return x == s })
+	if f.last == s.name {
+		f.last = ""
+	}
+	if f.Client == s.name {
+		f.Client = ""
+		if others := f.sorted(); len(others) > 0 {
+			f.attach(others[0])
+		}
+	}
+	if len(f.sessions) == 0 {
+		f.running = false // exit-empty
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Window management
+// ---------------------------------------------------------------------------
+
+func (f *Fake) NewWindow(sessionName string, windowName string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, err := f.findSession(sessionName)
+	if err != nil {
+		return err
+	}
+	s.current = f.newWindow(s, windowName, f.Dir)
+	return nil
+}
+
+func (f *Fake) CreateWindow(sessionName string, spec tmux.WindowSpec) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, err := f.findSession(sessionName)
+	if err != nil {
+		return 0, err
+	}
+	w := f.newWindow(s, spec.Name, joinDir(s.dir, spec.Dir))
+	if spec.Command != "" {
+		w.active.content.WriteString(spec.Command + "\n")
+	}
+	s.current = w
+	for _, l := range s.links {
+		if l.window == w {
+			return l.index, nil
+		}
+	}
+	return 0, nil
+}
+
+// OpenWindow opens a window in the client's session running command.
+func (f *Fake) OpenWindow(windowName string, command []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, err := f.switchable(f.Client)
+	if err != nil {
+		return err
+	}
+	w := f.newWindow(s, windowName, f.Dir)
+	if len(command) > 0 {
+		w.active.cmd = filepath.Base(command[0])
+	}
+	s.current = w
+	return nil
+}
+
+func (f *Fake) RenameWindow(sessionName string, windowIndex int, newName string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, wl, err := f.findWindow(sessionName, windowIndex)
+	if err != nil {
+		return err
+	}
+	wl.window.name = newName
+	return nil
+}
+
+// KillWindow destroys a window in every session it is linked to.
+func (f *Fake) KillWindow(sessionName string, windowIndex int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, wl, err := f.findWindow(sessionName, windowIndex)
+	if err != nil {
+		return err
+	}
+	f.destroyWindow(wl.window)
+	return nil
+}
+
+// MoveWindow moves a window to the first free index of dstSession.
+func (f *Fake) MoveWindow(srcSession string, srcIndex int, dstSession string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	src, wl, err := f.findWindow(srcSession, srcIndex)
+	if err != nil {
+		return err
+	}
+	dst, err := f.findSession(dstSession)
+	if err != nil {
+		return err
+	}
+	if dst != src && dst.linkOf(wl.window) != nil {
+		return errorf("window is already linked to %s", dst.name)
+	}
+	f.unlink(src, wl)
+	f.link(dst, wl.window)
+	return nil
+}
+
+// SwapWindow swaps the windows at two indexes of a session and makes the
+// one now at dstIndex current, as swap-window without -d does.
+func (f *Fake) SwapWindow(sessionName string, srcIndex, dstIndex int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, src, err := f.findWindow(sessionName, srcIndex)
+	if err != nil {
+		return err
+	}
+	_, dst, err := f.findWindow(sessionName, dstIndex)
+	if err != nil {
+		return err
+	}
+	src.window, dst.window = dst.window, src.window
+	s.current = dst.window
+	return nil
+}
+
+// LinkWindow links a window to the first free index of dstSession too.
+func (f *Fake) LinkWindow(srcSession string, srcIndex int, dstSession string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, wl, err := f.findWindow(srcSession, srcIndex)
+	if err != nil {
+		return err
+	}
+	dst, err := f.findSession(dstSession)
+	if err != nil {
+		return err
+	}
+	if dst.linkOf(wl.window) != nil {
+		return errorf("window is already linked to %s", dst.name)
+	}
+	f.link(dst, wl.window)
+	return nil
+}
+
+func (f *Fake) UnlinkWindow(sessionName string, windowIndex int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, wl, err := f.findWindow(sessionName, windowIndex)
+	if err != nil {
+		return err
+	}
+	if len(f.sessionsOf(wl.window)) < 2 {
+		return errorf("window only linked to one session")
+	}
+	f.unlink(s, wl)
+	return nil
+}
+
+// newWindow adds a single-pane window at the first free index of s.
+func (f *Fake) newWindow(s *session, name, dir string) *window {
+	p := f.newPane(dir)
+	w := &window{id: f.windowID(), name: cmp.Or(name, p.cmd), panes: []*pane{p}, active: p, layout: "even-horizontal"}
+	f.link(s, w)
+	w.arrange(f.Width, f.Height)
+	return w
+}
+
+func (f *Fake) newPane(dir string) *pane {
+	f.nextPID++
+	f.nextPane++
+	return &pane{id: f.nextPane - 1, cmd: f.Shell, dir: cmp.Or(dir, f.Dir), title: "fake", pid: f.nextPID}
+}
+
+func (f *Fake) windowID() int {
+	f.nextWin++
+	return f.nextWin - 1
+}
+
+// link adds w to s at the first free index from base-index.
+func (f *Fake) link(s *session, w *window) {
+	s.links = append(s.links, &winlink{index: f.freeIndex(s), window: w})
+	sort.Slice(s.links, func(i, j int) bool { return s.links[i].index < s.links[j].index })
+}
+
+func (f *Fake) freeIndex(s *session) int {
+	idx := f.BaseIndex
+	for _, wl := range s.links {
+		if wl.index == idx {
+			idx++
+		}
+	}
+	return idx
+}
+
+// unlink removes wl from s, destroying the window if no session has it
+// left and s if it has no windows left.
+func (f *Fake) unlink(s *session, wl *winlink) {
+	s.links = slices.DeleteFunc(s.links, func(x *winlink) bool { return x == wl })
+	if len(s.links) == 0 {
+		f.destroySession(s)
+		return
+	}
+	if s.current == wl.window && s.linkOf(wl.window) == nil {
+		s.current = s.links[0].window
+	}
+	if f.RenumberWindows {
+		for i, l := range s.links {
+			l.index = f.BaseIndex + i
+		}
+	}
+}
+
+func (f *Fake) destroyWindow(w *window) {
+	for _, s := range slices.Clone(f.sessions) {
+		if wl := s.linkOf(w); wl != nil {
+			f.unlink(s, wl)
+		}
+	}
+}
+
+// sessionsOf returns the names of the sessions w is linked to, sorted.
+func (f *Fake) sessionsOf(w *window) []string {
+	var names []string
+	for _, s := range f.sorted() {
+		if s.linkOf(w) != nil {
+			names = append(names, s.name)
+		}
+	}
+	return names
+}
+
+func (s *session) linkOf(w *window) *winlink {
+	for _, wl := range s.links {
+		if wl.window == w {
+			return wl
+		}
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Pane management
+// ---------------------------------------------------------------------------
+
+// JoinPane moves a pane after the destination window's active pane and makes
+// it active there; the source window closes when it loses its last pane.
+func (f *Fake) JoinPane(srcSession string, srcWindow, srcPane int, dstSession string, dstWindow int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, src, p, err := f.findPane(srcSession, srcWindow, srcPane)
+	if err != nil {
+		return err
+	}
+	_, dst, err := f.findWindow(dstSession, dstWindow)
+	if err != nil {
+		return err
+	}
+	if dst.window.active == p {
+		return errorf("source and target panes must be different")
+	}
+	f.removePane(src.window, p)
+	dst.window.insertAfter(dst.window.active, p)
+	dst.window.arrange(f.Width, f.Height)
+	return nil
+}
+
+// SplitWindow adds a pane after the target, in its directory, and makes it
+// active. The window is rearranged side by side (horizontal) or stacked.
+func (f *Fake) SplitWindow(sessionName string, windowIndex, paneIndex int, horizontal bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, err := f.findSession(sessionName)
+	if err != nil {
+		return err
+	}
+	w := s.current
+	if windowIndex >= 0 {
+		_, wl, err := f.findWindow(sessionName, windowIndex)
+		if err != nil {
+			return err
+		}
+		w = wl.window
+	}
+	target := w.active
+	if paneIndex >= 0 {
+		if paneIndex >= len(w.panes) {
+			return errorf("can't find pane: %d", paneIndex)
+		}
+		target = w.panes[paneIndex]
+	}
+	w.insertAfter(target, f.newPane(target.dir))
+	w.layout = "even-vertical"
+	if horizontal {
+		w.layout = "even-horizontal"
+	}
+	w.zoomed = false
+	w.arrange(f.Width, f.Height)
+	return nil
+}
+
+func (f *Fake) KillPane(sessionName string, windowIndex, paneIndex int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, wl, p, err := f.findPane(sessionName, windowIndex, paneIndex)
+	if err != nil {
+		return err
+	}
+	f.removePane(wl.window, p)
+	return nil
+}
+
+// BreakPane moves a pane into a new window of the session, not selected.
+// A window's only pane moves with its window to a new index.
+func (f *Fake) BreakPane(sessionName string, windowIndex, paneIndex int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, wl, p, err := f.findPane(sessionName, windowIndex, paneIndex)
+	if err != nil {
+		return err
+	}
+	if len(wl.window.panes) == 1 {
+		// tmux moves the window instead, to an index free while it still
+		// holds its own.
+		wl.index = f.freeIndex(s)
+		sort.Slice(s.links, func(i, j int) bool { return s.links[i].index < s.links[j].index })
+		return nil
+	}
+	f.removePane(wl.window, p)
+	w := &window{id: f.windowID(), name: p.cmd, panes: []*pane{p}, active: p, layout: "even-horizontal"}
+	f.link(s, w)
+	w.arrange(f.Width, f.Height)
+	return nil
+}
+
+// SwapPane swaps two panes' positions; the active pane doesn't change.
+func (f *Fake) SwapPane(sessionName string, windowIndex, srcPane, dstPane int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, wl, _, err := f.findPane(sessionName, windowIndex, srcPane)
+	if err != nil {
+		return err
+	}
+	if _, _, _, err := f.findPane(sessionName, windowIndex, dstPane); err != nil {
+		return err
+	}
+	w := wl.window
+	w.panes[srcPane], w.panes[dstPane] = w.panes[dstPane], w.panes[srcPane]
+	w.arrange(f.Width, f.Height)
+	return nil
+}
+
+// ResizePane checks the target only: panes keep their preset arrangement.
+func (f *Fake) ResizePane(sessionName string, windowIndex, paneIndex int, dx, dy int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, _, _, err := f.findPane(sessionName, windowIndex, paneIndex)
+	return err
+}
+
+// ZoomPane toggles the window's zoom and makes the pane active.
+func (f *Fake) ZoomPane(sessionName string, windowIndex, paneIndex int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, wl, p, err := f.findPane(sessionName, windowIndex, paneIndex)
+	if err != nil {
+		return err
+	}
+	wl.window.active = p
+	wl.window.zoomed = !wl.window.zoomed
+	return nil
+}
+
+// SelectLayout arranges the panes by a preset; layout strings aren't
+// supported.
+func (f *Fake) SelectLayout(sessionName string, windowIndex int, layout string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, wl, err := f.findWindow(sessionName, windowIndex)
+	if err != nil {
+		return err
+	}
+	switch layout {
+	case "even-horizontal", "even-vertical", "main-horizontal", "main-vertical", "tiled":
+	default:
+		return errorf("can't set layout: %s", layout)
+	}
+	wl.window.layout = layout
+	wl.window.zoomed = false
+	wl.window.arrange(f.Width, f.Height)
+	return nil
+}
+
+// removePane takes p out of w, destroying w when it was the last pane.
+func (f *Fake) removePane(w *window, p *pane) {
+	i := w.paneIndex(p)
+	w.panes = slices.Delete(w.panes, i, i+1)
+	if len(w.panes) == 0 {
+		f.destroyWindow(w)
+		return
+	}
+	if w.active == p {
+		w.active = w.panes[max(i-1, 0)]
+	}
+	w.zoomed = false
+	w.arrange(f.Width, f.Height)
+}
+
+func (w *window) insertAfter(target, p *pane) {
+	i := w.paneIndex(target) + 1
+	w.panes = slices.Insert(w.panes, i, p)
+	w.active = p
+}
+
+func (w *window) paneIndex(p *pane) int {
+	return slices.Index(w.panes, p)
+}
+
+// ---------------------------------------------------------------------------
+// Input
+// ---------------------------------------------------------------------------
+
+// SendKeys appends text to the pane's content, followed by a newline when
+// enter is set.
+func (f *Fake) SendKeys(sessionName string, windowIndex, paneIndex int, text string, enter bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, _, p, err := f.findPane(sessionName, windowIndex, paneIndex)
+	if err != nil {
+		return err
+	}
+	p.content.WriteString(text)
+	if enter {
+		p.content.WriteString("\n")
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Buffers
+// ---------------------------------------------------------------------------
+
+func (f *Fake) SetBuffer(text string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Buffer = text
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Lookup helpers
+// ---------------------------------------------------------------------------
+
+func (f *Fake) findSession(name string) (*session, error) {
+	for _, s := range f.sessions {
+		if s.name == name {
+			return s, nil
+		}
+	}
+	return nil, errorf("can't find session: %s", name)
+}
+
+func (f *Fake) findWindow(sessionName string, index int) (*session, *winlink, error) {
+	s, err := f.findSession(sessionName)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, wl := range s.links {
+		if wl.index == index {
+			return s, wl, nil
+		}
+	}
+	return nil, nil, errorf("can't find window: %d", index)
+}
+
+func (f *Fake) findPane(sessionName string, windowIndex, paneIndex int) (*session, *winlink, *pane, error) {
+	s, wl, err := f.findWindow(sessionName, windowIndex)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if paneIndex < 0 || paneIndex >= len(wl.window.panes) {
+		return nil, nil, nil, errorf("can't find pane: %d", paneIndex)
+	}
+	return s, wl, wl.window.panes[paneIndex], nil
+}
+
+// sorted returns the sessions in name order, as tmux lists them.
+func (f *Fake) sorted() []*session {
+	out := slices.Clone(f.sessions)
+	sort.Slice(out, func(i, j int) bool { return out[i].name < out[j].name })
+	return out
+}
+
+// tick advances the clock by a second, so activity times are ordered.
+func (f *Fake) tick() time.Time {
+	f.clock = f.clock.Add(time.Second)
+	return f.clock
+}
+
+func joinDir(base, dir string) string {
+	if dir == "" || filepath.IsAbs(dir) || base == "" {
+		return cmp.Or(dir, base)
+	}
+	return filepath.Join(base, dir)
+}
@@ -0,0 +1,122 @@
+package tmuxtest
+
+import "testing"
+
+func TestLayoutChecksum(t *testing.T) {
+	// Printed by tmux 3.3a for an 80x24 window split -h, then -v.
+	const layout = "80x24,0,0{40x24,0,0,0,39x24,41,0[39x12,41,0,1,39x11,41,13,2]}"
+	if got := checksum(layout); got != 0xd67e {
+		t.Errorf("checksum = %04x, want d67e", got)
+	}
+}
+
+func TestFake(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(f *Fake) error
+		want string
+	}{
+		{
+			name: "new window takes the first free index",
+			run: func(f *Fake) error {
+				if err := f.KillWindow("work", 0); err != nil {
+					return err
+				}
+				return f.NewWindow("work", "new")
+			},
+			want: "play 0:bash[%2]\nwork 0:new[%4] 1:logs[%1]",
+		},
+		{
+			name: "move window appends and closes an empty session",
+			run:  func(f *Fake) error { return f.MoveWindow("play", 0, "work") },
+			want: "work 0:editor[%0,%3] 1:logs[%1] 2:bash[%2]",
+		},
+		{
+			name: "swap window swaps indexes",
+			run:  func(f *Fake) error { return f.SwapWindow("work", 0, 1) },
+			want: "play 0:bash[%2]\nwork 0:logs[%1] 1:editor[%0,%3]",
+		},
+		{
+			name: "linked window is in both sessions",
+			run:  func(f *Fake) error { return f.LinkWindow("work", 1, "play") },
+			want: "play 0:bash[%2] 1:logs[%1]\nwork 0:editor[%0,%3] 1:logs[%1]",
+		},
+		{
+			name: "join pane goes after the active pane",
+			run:  func(f *Fake) error { return f.JoinPane("work", 0, 0, "play", 0) },
+			want: "play 0:bash[%2,%0]\nwork 0:editor[%3] 1:logs[%1]",
+		},
+		{
+			name: "joining the last pane closes its window",
+			run:  func(f *Fake) error { return f.JoinPane("play", 0, 0, "work", 1) },
+			want: "work 0:editor[%0,%3] 1:logs[%1,%2]",
+		},
+		{
+			name: "swap pane",
+			run:  func(f *Fake) error { return f.SwapPane("work", 0, 0, 1) },
+			want: "play 0:bash[%2]\nwork 0:editor[%3,%0] 1:logs[%1]",
+		},
+		{
+			name: "break pane",
+			run:  func(f *Fake) error { return f.BreakPane("work", 0, 0) },
+			want: "play 0:bash[%2]\nwork 0:editor[%3] 1:logs[%1] 2:bash[%0]",
+		},
+		{
+			name: "breaking the only pane moves its window",
+			run:  func(f *Fake) error { return f.BreakPane("work", 1, 0) },
+			want: "play 0:bash[%2]\nwork 0:editor[%0,%3] 2:logs[%1]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := New().AddSession("work", "editor", "logs").AddSession("play").AddPanes("work", 0, 1)
+			if err := tt.run(f); err != nil {
+				t.Fatal(err)
+			}
+			if got := f.Dump(); got != tt.want {
+				t.Errorf("Dump() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFakeRenumberWindows(t *testing.T) {
+	f := New()
+	f.BaseIndex, f.RenumberWindows = 1, true
+	f.AddSession("work", "a", "b", "c")
+	if err := f.KillWindow("work", 1); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := f.Dump(), "work 1:b[%1] 2:c[%2]"; got != want {
+		t.Errorf("Dump() = %q, want %q", got, want)
+	}
+}
+
+func TestFakeClient(t *testing.T) {
+	f := New().AddSession("work", "editor", "logs").AddSession("play").AddPanes("work", 1, 1).Attach("play")
+	if err := f.SelectPane("work", 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.ClientTarget(); got != "work:1.0" {
+		t.Errorf("after select-pane client is on %s, want work:1.0", got)
+	}
+	if err := f.SwitchToLast(); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.ClientTarget(); got != "play:0.0" {
+		t.Errorf("after switch-client -l client is on %s, want play:0.0", got)
+	}
+	if err := f.KillSession("play"); err != nil {
+		t.Fatal(err)
+	}
+	if f.Client != "work" {
+		t.Errorf("client on %q after its session was killed, want work", f.Client)
+	}
+
+	if err := f.SendKeys("work", 0, 0, "ls", true); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := f.CapturePane("work", 0, 0); got != "ls\n" {
+		t.Errorf("capture-pane = %q, want %q", got, "ls\n")
+	}
+}
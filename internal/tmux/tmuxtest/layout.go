@@ -0,0 +1,148 @@
+package tmuxtest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// cell is a node of a window's layout: a pane, or a row or column of cells.
+type cell struct {
+	x, y, w, h int
+	pane       *pane
+	row        bool // children side by side ({}), else stacked ([])
+	children   []*cell
+}
+
+// arrange sizes the panes by the window's preset in a width×height window.
+func (w *window) arrange(width, height int) {
+	var walk func(c *cell)
+	walk = func(c *cell) {
+		if c.pane != nil {
+			c.pane.w, c.pane.h = c.w, c.h
+		}
+		for _, child := range c.children {
+			walk(child)
+		}
+	}
+	walk(w.tree(width, height))
+}
+
+// layoutString returns the window's layout as tmux prints #{window_layout},
+// e.g. "b25f,80x24,0,0{40x24,0,0,1,39x24,41,0,2}".
+func (w *window) layoutString(width, height int) string {
+	body := w.tree(width, height).String()
+	return fmt.Sprintf("%04x,%s", checksum(body), body)
+}
+
+// tree lays the panes out like tmux's presets. Main panes take half the
+// window.
+func (w *window) tree(width, height int) *cell {
+	root := &cell{w: width, h: height}
+	panes := w.panes
+	if len(panes) == 1 {
+		root.pane = panes[0]
+		return root
+	}
+	switch w.layout {
+	case "even-horizontal":
+		root.split(true, panes)
+	case "even-vertical":
+		root.split(false, panes)
+	case "main-horizontal", "main-vertical":
+		row := w.layout == "main-vertical"
+		size := height
+		if row {
+			size = width
+		}
+		main := spans(size, 2)[0][1]
+		root.row = row
+		first, rest := &cell{x: 0, y: 0, w: width, h: main, pane: panes[0]}, &cell{x: 0, y: main + 1, w: width, h: height - main - 1}
+		if row {
+			first.w, first.h = main, height
+			rest.x, rest.y, rest.w, rest.h = main+1, 0, width-main-1, height
+		}
+		rest.split(!row, panes[1:])
+		root.children = []*cell{first, rest}
+	default: // tiled
+		rows, cols := 1, 1
+		for rows*cols < len(panes) {
+			rows++
+			if rows*cols < len(panes) {
+				cols++
+			}
+		}
+		if rows == 1 {
+			root.split(true, panes)
+			break
+		}
+		for i, r := range spans(height, rows) {
+			c := &cell{x: 0, y: r[0], w: width, h: r[1]}
+			c.split(true, panes[i*cols:min((i+1)*cols, len(panes))])
+			root.children = append(root.children, c)
+		}
+	}
+	return root
+}
+
+// split divides c among panes, side by side when row is set. A single pane
+// fills c.
+func (c *cell) split(row bool, panes []*pane) {
+	if len(panes) == 1 {
+		c.pane = panes[0]
+		return
+	}
+	c.row = row
+	size := c.h
+	if row {
+		size = c.w
+	}
+	for i, s := range spans(size, len(panes)) {
+		child := &cell{x: c.x, y: c.y + s[0], w: c.w, h: s[1], pane: panes[i]}
+		if row {
+			child.x, child.y, child.w, child.h = c.x+s[0], c.y, s[1], c.h
+		}
+		c.children = append(c.children, child)
+	}
+}
+
+func (c *cell) String() string {
+	s := fmt.Sprintf("%dx%d,%d,%d", c.w, c.h, c.x, c.y)
+	if c.pane != nil {
+		return fmt.Sprintf("%s,%d", s, c.pane.id)
+	}
+	parts := make([]string, len(c.children))
+	for i, child := range c.children {
+		parts[i] = child.String()
+	}
+	if c.row {
+		return s + "{" + strings.Join(parts, ",") + "}"
+	}
+	return s + "[" + strings.Join(parts, ",") + "]"
+}
+
+// spans divides size cells into n parts separated by one-cell borders,
+// returning each part's start and length.
+func spans(size, n int) [][2]int {
+	avail := max(size-(n-1), n)
+	out := make([][2]int, n)
+	pos := 0
+	for i := range out {
+		l := avail / n
+		if i < avail%n {
+			l++
+		}
+		out[i] = [2]int{pos, l}
+		pos += l + 1
+	}
+	return out
+}
+
+// checksum is tmux's layout_checksum.
+func checksum(layout string) uint16 {
+	var csum uint16
+	for i := 0; i < len(layout); i++ {
+		csum = (csum >> 1) + ((csum & 1) << 15)
+		csum += uint16(layout[i])
+	}
+	return csum
+}
@@ -4,16 +4,18 @@ import "time"
 
 // Service defines the operations the TUI needs from tmux.
 // All tmux interactions go through this interface, making the TUI
-// testable with a mock implementation.
+// testable with an in-memory server (tmuxtest.Fake).
 type Service interface {
 	// Queries
 	IsInTmux() bool
+	Capabilities() Capabilities
+	Snapshot() ([]Session, error) // every session with its windows and panes
 	ListSessions() ([]Session, error)
 	ListWindows(sessionName string) ([]Window, error)
 	ListAllWindowNames() (map[string][]string, error)  // session -> window names
 	ListAllPaneCounts() (map[string]int, error)        // session -> total pane count
 	ListPanes(sessionName string, windowIndex int) ([]Pane, error)
-	CapturePane(sessionName string, windowIndex int, paneIndex int) (string, error)
+	CapturePane(sessionName string, windowIndex int, paneIndex int) (string, error) // with SGR colors if tmux can
 
 	// Navigation
 	SwitchToSession(sessionName string) error
@@ -21,64 +23,107 @@ type Service interface {
 	SwitchClient(sessionName string, windowIndex int) error
 	SelectPane(sessionName string, windowIndex int, paneIndex int) error
 	AttachSession(sessionName string) error
+	AttachCommand(target string) []string // argv attaching a new client to target
 
 	// Session management
+	StartServer() error
 	NewSession(sessionName string) error
 	NewSessionInDir(sessionName string, dir string) error
+	CreateSession(spec SessionSpec) error
 	HasSession(sessionName string) bool
 	RenameSession(oldName, newName string) error
 	KillSession(sessionName string) error
 
 	// Window management
 	NewWindow(sessionName string, windowName string) error
+	CreateWindow(sessionName string, spec WindowSpec) (int, error) // returns the window index
+	OpenWindow(windowName string, command []string) error
 	RenameWindow(sessionName string, windowIndex int, newName string) error
 	KillWindow(sessionName string, windowIndex int) error
 	MoveWindow(srcSession string, srcIndex int, dstSession string) error
 	SwapWindow(sessionName string, srcIndex, dstIndex int) error
+	LinkWindow(srcSession string, srcIndex int, dstSession string) error
+	UnlinkWindow(sessionName string, windowIndex int) error
 
 	// Pane management
 	JoinPane(srcSession string, srcWindow, srcPane int, dstSession string, dstWindow int) error
+	SplitWindow(sessionName string, windowIndex, paneIndex int, horizontal bool) error
+	KillPane(sessionName string, windowIndex, paneIndex int) error
+	BreakPane(sessionName string, windowIndex, paneIndex int) error
+	SwapPane(sessionName string, windowIndex, srcPane, dstPane int) error
+	ResizePane(sessionName string, windowIndex, paneIndex int, dx, dy int) error
+	ZoomPane(sessionName string, windowIndex, paneIndex int) error
+	SelectLayout(sessionName string, windowIndex int, layout string) error
+
+	// Input
+	SendKeys(sessionName string, windowIndex, paneIndex int, text string, enter bool) error
+
+	// Buffers
+	SetBuffer(text string) error
 }
 
 // Session represents a TMUX session.
 type Session struct {
-	Name        string
-	WindowCount int
+	Server      string // server the session lives on; empty for the current server
+	Name        string `tmux:"session_name"`
+	WindowCount int    `tmux:"session_windows"`
 	PaneCount   int
-	Attached    bool
-	Created     time.Time
-	LastActive  time.Time
-	Width       int
-	Height      int
+	Attached    bool      `tmux:"session_attached"`
+	Created     time.Time `tmux:"session_created"`
+	LastActive  time.Time `tmux:"session_last_attached"`
+	Width       int       `tmux:"window_width"`
+	Height      int       `tmux:"window_height"`
 	// Active pane state in the active window (populated from list-sessions).
-	ActivePaneDir   string
-	ActivePaneCmd   string
-	ActivePaneTitle string
-	ActivePanePID   int
+	ActivePaneDir   string `tmux:"pane_current_path"`
+	ActivePaneCmd   string `tmux:"pane_current_command"`
+	ActivePaneTitle string `tmux:"pane_title"`
+	ActivePanePID   int    `tmux:"pane_pid"`
+
+	Windows []Window // filled by Snapshot only
 }
 
 // Window represents a TMUX window.
 type Window struct {
-	Index      int
-	Name       string
-	PaneCount  int
-	Active     bool
-	Layout     string
-	WorkingDir string // CWD of the active pane (pane_current_path)
+	Index      int    `tmux:"window_index"`
+	Name       string `tmux:"window_name"`
+	PaneCount  int    `tmux:"window_panes"`
+	Active     bool   `tmux:"window_active"`
+	Layout     string `tmux:"window_layout"`
+	WorkingDir string `tmux:"pane_current_path"` // CWD of the active pane
+	// Linked windows are shared by several sessions (link-window).
+	Linked         bool     `tmux:"window_linked"`
+	LinkedSessions []string `tmux:"window_linked_sessions_list"` // every session the window is in, this one included
 	// Active pane state (populated from list-windows).
-	ActivePaneCmd   string
-	ActivePaneTitle string
-	ActivePanePID   int
+	ActivePaneCmd   string `tmux:"pane_current_command"`
+	ActivePaneTitle string `tmux:"pane_title"`
+	ActivePanePID   int    `tmux:"pane_pid"`
+
+	Panes []Pane // filled by Snapshot only
+}
+
+// SessionSpec describes a session to create.
+type SessionSpec struct {
+	Name    string
+	Dir     string       // start directory; empty = tmux's default
+	Windows []WindowSpec // the first is the initial window; may be empty
+}
+
+// WindowSpec describes a window to create.
+type WindowSpec struct {
+	Name    string // empty = named after the running command
+	Dir     string // start directory, relative to the session's; empty = the session's
+	Command string // typed into the window's shell once it starts; may be empty
 }
 
 // Pane represents a TMUX pane.
 type Pane struct {
-	Index      int
-	Active     bool
-	Width      int
-	Height     int
-	Command    string
-	WorkingDir string
-	Title      string // pane_title — used for SSH/FTP connection detection
-	PID        int    // pane_pid — used to read SSH process args via ps
+	ID         string `tmux:"pane_id"` // "%3"; the same in every window link
+	Index      int    `tmux:"pane_index"`
+	Active     bool   `tmux:"pane_active"`
+	Width      int    `tmux:"pane_width"`
+	Height     int    `tmux:"pane_height"`
+	Command    string `tmux:"pane_current_command"`
+	WorkingDir string `tmux:"pane_current_path"`
+	Title      string `tmux:"pane_title"` // used for SSH/FTP connection detection
+	PID        int    `tmux:"pane_pid"`   // used to read SSH process args via ps
 }
@@ -0,0 +1,244 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/luytbq/tswitch/internal/tmux"
+)
+
+// PaneTarget is a pane that broadcast input is sent to.
+type PaneTarget struct {
+	Server  string // "" for the current server
+	Session string
+	Window  int
+	Pane    int
+	ID      string // tmux pane id, the same for every link of a window
+	Command string // running in the pane, shown before sending
+}
+
+// String returns the tmux target, prefixed with the server when not local.
+func (t PaneTarget) String() string {
+	s := fmt.Sprintf("%s:%d.%d", t.Session, t.Window, t.Pane)
+	if t.Server != "" {
+		s = t.Server + "/" + s
+	}
+	return s
+}
+
+// WindowTargets returns every pane of a window.
+func WindowTargets(svc tmux.Service, server, session string, window int) ([]PaneTarget, error) {
+	panes, err := svc.ListPanes(session, window)
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]PaneTarget, len(panes))
+	for i, p := range panes {
+		targets[i] = PaneTarget{Server: server, Session: session, Window: window, Pane: p.Index, ID: p.ID, Command: p.Command}
+	}
+	return targets, nil
+}
+
+// SessionTargets returns every pane of every window of a session.
+func SessionTargets(svc tmux.Service, server, session string) ([]PaneTarget, error) {
+	windows, err := svc.ListWindows(session)
+	if err != nil {
+		return nil, err
+	}
+	var targets []PaneTarget
+	for _, w := range windows {
+		t, err := WindowTargets(svc, server, session, w.Index)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, t...)
+	}
+	return UniqueTargets(targets), nil
+}
+
+// UniqueTargets drops the targets naming a pane already in targets: a
+// window linked into several sessions, or twice into one, has the same
+// panes in each.
+func UniqueTargets(targets []PaneTarget) []PaneTarget {
+	type pane struct{ server, id string }
+	seen := map[pane]bool{}
+	out := targets[:0:0]
+	for _, t := range targets {
+		if t.ID != "" {
+			if seen[pane{t.Server, t.ID}] {
+				continue
+			}
+			seen[pane{t.Server, t.ID}] = true
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+// Broadcast types text into each target and presses enter. A pane that
+// fails doesn't stop the others; the errors are returned together.
+func Broadcast(serviceFor func(server string) tmux.Service, targets []PaneTarget, text string) error {
+	var errs []error
+	for _, t := range targets {
+		if err := serviceFor(t.Server).SendKeys(t.Session, t.Window, t.Pane, text, true); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", t, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ---------------------------------------------------------------------------
+// Broadcast dialogs: command → targets menu → confirmation
+// ---------------------------------------------------------------------------
+
+// broadcast is a command waiting for confirmation.
+type broadcast struct {
+	text    string
+	targets []PaneTarget
+}
+
+// maxBroadcastLines caps the targets listed in the confirmation.
+const maxBroadcastLines = 8
+
+// handleBroadcast asks for the command to broadcast.
+func (m *Model) handleBroadcast() (tea.Model, tea.Cmd) {
+	m.openInputDialog(dialogBroadcast, "Broadcast", "Command to send:", "")
+	return m, nil
+}
+
+// openBroadcastMenu offers the targets for text that fit the focused card:
+// the pane, its window, its session, and sessions by tag or by the filter.
+func (m *Model) openBroadcastMenu(text string) {
+	var items []menuItem
+	add := func(label string, resolve func() ([]PaneTarget, error)) {
+		items = append(items, menuItem{label: label, run: func() (tea.Model, tea.Cmd) {
+			targets, err := resolve()
+			if err != nil {
+				m.setStatusError(err.Error())
+				return m, nil
+			}
+			return m.confirmBroadcast(text, targets)
+		}})
+	}
+	svc, server := m.svc(), m.currentServer
+
+	switch card := m.activeGrid().GetFocused().(type) {
+	case PaneCard:
+		p := card.pane
+		add(fmt.Sprintf("This pane (%s:%d.%d)", m.currentSess, m.currentWin, p.Index), func() ([]PaneTarget, error) {
+			return []PaneTarget{{Server: server, Session: m.currentSess, Window: m.currentWin, Pane: p.Index, ID: p.ID, Command: p.Command}}, nil
+		})
+		add(fmt.Sprintf("All panes of window %s:%d", m.currentSess, m.currentWin), func() ([]PaneTarget, error) {
+			return WindowTargets(svc, server, m.currentSess, m.currentWin)
+		})
+	case WindowCard:
+		w := card.window
+		add(fmt.Sprintf("All panes of window %d: %s", w.Index, w.Name), func() ([]PaneTarget, error) {
+			return WindowTargets(svc, server, m.currentSess, w.Index)
+		})
+		add("All panes of session "+m.currentSess, func() ([]PaneTarget, error) {
+			return SessionTargets(svc, server, m.currentSess)
+		})
+	case SessionCard:
+		s := card.session
+		add("All panes of session "+s.Name, func() ([]PaneTarget, error) {
+			return SessionTargets(m.serviceFor(s.Server), s.Server, s.Name)
+		})
+		if m.filterQuery != "" {
+			matches := FilterSessions(m.sessions, m.filterQuery, m.windowsBySession)
+			add(fmt.Sprintf("Sessions matching %q (%d)", m.filterQuery, len(matches)), func() ([]PaneTarget, error) {
+				return m.sessionTargets(matches)
+			})
+		}
+	}
+
+	tags := make([]string, 0, len(m.config.Tags))
+	for tag := range m.config.Tags {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		tagged := m.taggedSessions(tag)
+		if len(tagged) == 0 {
+			continue
+		}
+		add(fmt.Sprintf("Sessions tagged %s (%d)", tag, len(tagged)), func() ([]PaneTarget, error) {
+			return m.sessionTargets(tagged)
+		})
+	}
+
+	if len(items) == 0 {
+		m.setStatusError("nothing to send to here")
+		return
+	}
+	m.openMenu("Send to", items)
+}
+
+// taggedSessions returns the loaded sessions carrying tag on their server.
+func (m *Model) taggedSessions(tag string) []tmux.Session {
+	var out []tmux.Session
+	for _, s := range m.sessions {
+		for _, t := range m.config.GetSessionTags(s.Server, s.Name) {
+			if t == tag {
+				out = append(out, s)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// sessionTargets returns every pane of sessions, each on its own server.
+func (m *Model) sessionTargets(sessions []tmux.Session) ([]PaneTarget, error) {
+	var targets []PaneTarget
+	for _, s := range sessions {
+		t, err := SessionTargets(m.serviceFor(s.Server), s.Server, s.Name)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, t...)
+	}
+	return UniqueTargets(targets), nil
+}
+
+// confirmBroadcast lists the targets of text and asks before sending.
+func (m *Model) confirmBroadcast(text string, targets []PaneTarget) (tea.Model, tea.Cmd) {
+	if len(targets) == 0 {
+		m.setStatusError("no panes to send to")
+		return m, nil
+	}
+	const innerW = 40 // dialog text width
+	lines := []string{
+		truncateWidth("Send: "+text, innerW),
+		fmt.Sprintf("to %d pane(s):", len(targets)),
+	}
+	for i, t := range targets {
+		if i == maxBroadcastLines && len(targets) > maxBroadcastLines+1 {
+			lines = append(lines, fmt.Sprintf("  … and %d more", len(targets)-i))
+			break
+		}
+		lines = append(lines, truncateWidth(fmt.Sprintf("  %s  %s", t, t.Command), innerW))
+	}
+	m.dialog = NewConfirmDialog("Broadcast", strings.Join(lines, "\n"), m.styles)
+	m.pendingAction = dialogBroadcastConfirm
+	m.broadcast = &broadcast{text: text, targets: targets}
+	return m, nil
+}
+
+// sendBroadcast sends the confirmed broadcast.
+func (m *Model) sendBroadcast() (tea.Model, tea.Cmd) {
+	b := m.broadcast
+	m.broadcast = nil
+	if b == nil {
+		return m, nil
+	}
+	if err := Broadcast(m.serviceFor, b.targets, b.text); err != nil {
+		m.setStatusError(err.Error())
+		return m, nil
+	}
+	m.setStatus(fmt.Sprintf("Sent to %d pane(s)", len(b.targets)))
+	return m, m.syncPreview()
+}
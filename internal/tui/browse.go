@@ -59,7 +59,11 @@ func (m *Model) handleFzfResult(msg fzfResultMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	if err := SwitchOrCreateSession(m.tmux, msg.path); err != nil {
+	name, err := EnsureSessionForDir(m.tmux, msg.path)
+	if err == nil {
+		err = m.switchTo("", name, -1, -1)
+	}
+	if err != nil {
 		m.setStatusError(err.Error())
 		return m, nil
 	}
@@ -109,28 +113,37 @@ func BuildBrowseCommand(appCfg *config.AppConfig) (shellCmd string, tmpPath stri
 
 // SwitchOrCreateSession creates a new tmux session in the given directory
 // (if one doesn't already exist) and switches to it.
+func SwitchOrCreateSession(svc tmux.Service, dir string) error {
+	name, err := EnsureSessionForDir(svc, dir)
+	if err != nil {
+		return err
+	}
+	return svc.SwitchToSession(name)
+}
+
+// EnsureSessionForDir returns the session for dir, creating it if needed.
 // It first checks if a session with the raw directory basename exists (to
 // handle names containing dots/spaces that NormalizeSessionName would alter).
-func SwitchOrCreateSession(svc tmux.Service, dir string) error {
+func EnsureSessionForDir(svc tmux.Service, dir string) (string, error) {
 	rawName := filepath.Base(dir)
 
 	// Prefer exact basename match with existing session.
 	if rawName != "" && svc.HasSession(rawName) {
-		return svc.SwitchToSession(rawName)
+		return rawName, nil
 	}
 
 	// Fall back to normalized name.
 	name := NormalizeSessionName(dir)
 	if name == "" {
-		return fmt.Errorf("could not derive session name from path")
+		return "", fmt.Errorf("could not derive session name from path")
 	}
 
 	if !svc.HasSession(name) {
 		if err := svc.NewSessionInDir(name, dir); err != nil {
-			return fmt.Errorf("failed to create session: %w", err)
+			return "", fmt.Errorf("failed to create session: %w", err)
 		}
 	}
-	return svc.SwitchToSession(name)
+	return name, nil
 }
 
 // NormalizeSessionName derives a valid tmux session name from a directory path.
@@ -5,20 +5,36 @@ import (
 	"strings"
 	"time"
 
+	"github.com/luytbq/tswitch/internal/config"
 	"github.com/luytbq/tswitch/internal/tmux"
 )
 
 // SessionCard wraps a tmux.Session for grid display.
 type SessionCard struct {
 	session tmux.Session
+	server  string // label of the session's server; empty for the current one
+	section string // grid section when the grid is grouped
 }
 
 func (c SessionCard) Title() string {
 	return c.session.Name
 }
 
+// Key tells same-named sessions on different servers apart.
+func (c SessionCard) Key() string {
+	return config.SessionKey(c.session.Server, c.session.Name)
+}
+
 func (c SessionCard) Subtitle() string {
-	return fmt.Sprintf("%d wins · %d panes · %s", c.session.WindowCount, c.session.PaneCount, formatTimeSince(c.session.LastActive))
+	info := fmt.Sprintf("%d wins · %d panes · %s", c.session.WindowCount, c.session.PaneCount, formatTimeSince(c.session.LastActive))
+	if c.server != "" {
+		info = "@" + c.server + " · " + info
+	}
+	return info
+}
+
+func (c SessionCard) Section() string {
+	return c.section
 }
 
 func (c SessionCard) Indicator() string {
@@ -28,6 +44,24 @@ func (c SessionCard) Indicator() string {
 	return ""
 }
 
+// NewSessionCard is the "new session here" entry offered first in the
+// session grid when tswitch runs outside tmux.
+type NewSessionCard struct {
+	dir string // directory tswitch was launched from
+}
+
+func (c NewSessionCard) Title() string {
+	return "+ new session"
+}
+
+func (c NewSessionCard) Subtitle() string {
+	return c.dir
+}
+
+func (c NewSessionCard) Indicator() string {
+	return ""
+}
+
 // WindowCard wraps a tmux.Window for grid display.
 type WindowCard struct {
 	window tmux.Window
@@ -50,10 +84,14 @@ func (c WindowCard) Subtitle() string {
 }
 
 func (c WindowCard) Indicator() string {
+	var s string
 	if c.window.Active {
-		return "●"
+		s = "●"
 	}
-	return ""
+	if c.window.Linked {
+		s += "⇄" // shared with other sessions
+	}
+	return s
 }
 
 // PaneCard wraps a tmux.Pane for grid display.
@@ -80,12 +118,15 @@ func (c PaneCard) Indicator() string {
 	return ""
 }
 
+// now is the clock relative times are measured against; tests stop it.
+var now = time.Now
+
 // formatTimeSince returns a human-readable relative time string.
 func formatTimeSince(t time.Time) string {
 	if t.IsZero() {
 		return "?"
 	}
-	d := time.Since(t)
+	d := now().Sub(t)
 	switch {
 	case d < time.Minute:
 		return "now"
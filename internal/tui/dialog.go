@@ -1,23 +1,54 @@
 package tui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"strings"
 
-// DialogKind distinguishes confirmation from input dialogs.
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sahilm/fuzzy"
+)
+
+// DialogKind distinguishes confirmation, input, menu and form dialogs.
 type DialogKind int
 
 const (
 	DialogConfirm DialogKind = iota
 	DialogInput
+	DialogMenu
+	DialogForm
 )
 
-// Dialog represents a modal overlay (confirm or text-input).
+// Choice is one entry of a menu dialog.
+type Choice struct {
+	Label string
+	Key   string // key bound to the same action, shown dimmed; may be empty
+}
+
+// Field is one labelled input of a form dialog.
+type Field struct {
+	Label string
+	Input LineEditor
+}
+
+// menuBodyTop is the line of the first choice in a rendered menu dialog:
+// border(1) + padding(1) + title(1) + blank(1) + filter(1) + blank(1).
+const menuBodyTop = 6
+
+// formBodyTop is the line of the first field in a rendered form dialog:
+// border(1) + padding(1) + title(1) + blank(1).
+const formBodyTop = 4
+
+// Dialog represents a modal overlay (confirm, text-input, menu or form).
 type Dialog struct {
 	Kind        DialogKind
 	Title       string
 	Message     string
-	Input       string
+	Input       LineEditor // text of input dialogs; filter term of menus
 	Options     []string
-	SelectedIdx int
+	Choices     []Choice // DialogMenu entries
+	Fields      []Field  // DialogForm inputs
+	SelectedIdx int      // index into Options, Visible() for menus, or Fields
+	scroll      int      // first menu choice shown when they don't all fit
+	Hint        string   // key hints shown under the body; set by the model
 	styles      Styles
 }
 
@@ -39,14 +70,134 @@ func NewInputDialog(title, message, defaultValue string, styles Styles) *Dialog
 		Kind:    DialogInput,
 		Title:   title,
 		Message: message,
-		Input:   defaultValue,
+		Input:   NewLineEditor(defaultValue),
 		styles:  styles,
 	}
 }
 
-// Render returns the dialog overlay string.
-func (d *Dialog) Render(_, _ int) string {
-	const dialogWidth = 44
+// NewMenuDialog creates a dialog that picks one of choices.
+func NewMenuDialog(title string, choices []Choice, styles Styles) *Dialog {
+	return &Dialog{
+		Kind:    DialogMenu,
+		Title:   title,
+		Choices: choices,
+		styles:  styles,
+	}
+}
+
+// NewFormDialog creates a dialog with several labelled inputs.
+func NewFormDialog(title string, fields []Field, styles Styles) *Dialog {
+	return &Dialog{
+		Kind:   DialogForm,
+		Title:  title,
+		Fields: fields,
+		styles: styles,
+	}
+}
+
+// Editor returns the line editor keys go to: the focused field of a form,
+// the input of the other kinds.
+func (d *Dialog) Editor() *LineEditor {
+	if d.Kind == DialogForm && d.SelectedIdx < len(d.Fields) {
+		return &d.Fields[d.SelectedIdx].Input
+	}
+	return &d.Input
+}
+
+// FieldValue returns the trimmed value of the form field labelled label,
+// or "" if the form has no such field.
+func (d *Dialog) FieldValue(label string) string {
+	for _, f := range d.Fields {
+		if f.Label == label {
+			return strings.TrimSpace(f.Input.Value())
+		}
+	}
+	return ""
+}
+
+// FieldAt returns the form field drawn on line y of the rendered dialog, or
+// -1. The focused field may be followed by a line of completions.
+func (d *Dialog) FieldAt(y int) int {
+	line := formBodyTop
+	for i, f := range d.Fields {
+		if y == line {
+			return i
+		}
+		line++
+		if i == d.SelectedIdx && len(f.Input.Completions()) > 0 {
+			line++
+		}
+	}
+	return -1
+}
+
+// Visible returns the indexes of the menu choices matching the filter term,
+// best match first.
+func (d *Dialog) Visible() []int {
+	idx := make([]int, 0, len(d.Choices))
+	if d.Input.Value() == "" {
+		for i := range d.Choices {
+			idx = append(idx, i)
+		}
+		return idx
+	}
+	labels := make([]string, len(d.Choices))
+	for i, c := range d.Choices {
+		labels[i] = c.Label
+	}
+	for _, match := range fuzzy.Find(d.Input.Value(), labels) {
+		idx = append(idx, match.Index)
+	}
+	return idx
+}
+
+// Selected returns the index of the selected menu choice, or -1 if the
+// filter matches nothing.
+func (d *Dialog) Selected() int {
+	if visible := d.Visible(); d.SelectedIdx < len(visible) {
+		return visible[d.SelectedIdx]
+	}
+	return -1
+}
+
+// MoveSelection moves the menu selection or the focused form field by dy,
+// wrapping around.
+func (d *Dialog) MoveSelection(dy int) {
+	n := len(d.Fields)
+	if d.Kind == DialogMenu {
+		n = len(d.Visible())
+	}
+	if n > 0 {
+		d.SelectedIdx = ((d.SelectedIdx+dy)%n + n) % n
+	}
+}
+
+// ChoiceAt returns the menu choice drawn on line y of the rendered dialog,
+// or -1.
+func (d *Dialog) ChoiceAt(y int) int {
+	if visible, i := d.Visible(), y-menuBodyTop; i >= 0 && d.scroll+i < len(visible) {
+		i += d.scroll
+		return visible[i]
+	}
+	return -1
+}
+
+// Render returns the dialog overlay string, fitted to a termW×termH
+// terminal: narrower when the terminal is, and with a long menu scrolled to
+// keep the selection in view.
+func (d *Dialog) Render(termW, termH int) string {
+	dialogWidth := min(44, termW-2) // border
+	innerW := dialogWidth - 4       // padding
+	frame := func(body string) string {
+		return lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("39")).
+			Padding(1, 2).
+			Width(dialogWidth).
+			Render(body)
+	}
+	cursor := lipgloss.NewStyle().Foreground(lipgloss.Color("33")).Reverse(true)
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
 
 	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("33")).Render(d.Title)
 
@@ -61,19 +212,72 @@ func (d *Dialog) Render(_, _ int) string {
 			}
 			opts += "  " + s.Render(opt)
 		}
-		hint := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("  (y/n · ←/→ · enter)")
+		hint := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("  " + d.Hint)
 		body = title + "\n\n" + d.Message + "\n\n" + opts + "\n" + hint
 
 	case DialogInput:
-		cursor := lipgloss.NewStyle().Foreground(lipgloss.Color("33")).Render("█")
-		hint := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("enter: confirm · esc: cancel")
-		body = title + "\n\n" + d.Message + "\n\n> " + d.Input + cursor + "\n\n" + hint
+		input := "> " + d.Input.View(innerW-2, cursor)
+		if matches := d.Input.Completions(); len(matches) > 0 {
+			input += "\n" + dim.Render(truncateWidth(strings.Join(matches, "  "), innerW))
+		}
+		body = title + "\n\n" + d.Message + "\n\n" + input + "\n\n" + dim.Render(d.Hint)
+
+	case DialogMenu:
+		filter := "/ " + d.Input.View(innerW-2, cursor)
+		if d.Input.Value() == "" {
+			filter += dim.Render(" type to filter")
+		}
+		visible := d.Visible()
+		lines := make([]string, len(visible))
+		if len(visible) == 0 {
+			lines = []string{dim.Render("  no match")}
+		}
+		for i, ci := range visible {
+			c := d.Choices[ci]
+			label := "  " + c.Label
+			style := lipgloss.NewStyle().Foreground(lipgloss.Color("250"))
+			if i == d.SelectedIdx {
+				label = "› " + c.Label
+				style = lipgloss.NewStyle().Foreground(lipgloss.Color("33")).Bold(true)
+			}
+			gap := max(innerW-lipgloss.Width(label)-lipgloss.Width(c.Key), 1)
+			lines[i] = style.Render(label) + strings.Repeat(" ", gap) + dim.Render(c.Key)
+		}
+		chrome := lipgloss.Height(frame(title+"\n\n"+filter+"\n\n\n\n"+dim.Render(d.Hint))) - 1
+		if rows := max(termH-chrome, 1); len(lines) > rows {
+			if d.SelectedIdx < d.scroll {
+				d.scroll = d.SelectedIdx
+			} else if d.SelectedIdx >= d.scroll+rows {
+				d.scroll = d.SelectedIdx - rows + 1
+			}
+			d.scroll = clamp(d.scroll, 0, len(lines)-rows)
+			lines = lines[d.scroll : d.scroll+rows]
+		} else {
+			d.scroll = 0
+		}
+		body = title + "\n\n" + filter + "\n\n" + strings.Join(lines, "\n") + "\n\n" + dim.Render(d.Hint)
+
+	case DialogForm:
+		labelW := 0
+		for _, f := range d.Fields {
+			labelW = max(labelW, lipgloss.Width(f.Label))
+		}
+		valueW := innerW - labelW - 1
+		focused := lipgloss.NewStyle().Foreground(lipgloss.Color("33")).Bold(true)
+		var lines []string
+		for i, f := range d.Fields {
+			label := f.Label + strings.Repeat(" ", labelW-lipgloss.Width(f.Label))
+			if i != d.SelectedIdx {
+				lines = append(lines, dim.Render(label)+" "+truncateWidth(f.Input.Value(), valueW))
+				continue
+			}
+			lines = append(lines, focused.Render(label)+" "+f.Input.View(valueW, cursor))
+			if matches := f.Input.Completions(); len(matches) > 0 {
+				lines = append(lines, strings.Repeat(" ", labelW+1)+dim.Render(truncateWidth(strings.Join(matches, "  "), valueW)))
+			}
+		}
+		body = title + "\n\n" + strings.Join(lines, "\n") + "\n\n" + dim.Render(d.Hint)
 	}
 
-	return lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("39")).
-		Padding(1, 2).
-		Width(dialogWidth).
-		Render(body)
+	return frame(body)
 }
@@ -2,11 +2,13 @@ package tui
 
 import (
 	"github.com/sahilm/fuzzy"
+	"github.com/luytbq/tswitch/internal/config"
 	"github.com/luytbq/tswitch/internal/tmux"
 )
 
 // FilterSessions returns sessions whose names, or any of their window names,
-// fuzzy-match term. windowsBySession may be nil (session-name-only match).
+// fuzzy-match term. windowsBySession is keyed by config.SessionKey and may be
+// nil (session-name-only match).
 func FilterSessions(sessions []tmux.Session, term string, windowsBySession map[string][]string) []tmux.Session {
 	if term == "" {
 		return sessions
@@ -18,7 +20,7 @@ func FilterSessions(sessions []tmux.Session, term string, windowsBySession map[s
 	searchStrings := make([]string, len(sessions))
 	for i, s := range sessions {
 		combined := s.Name
-		for _, wn := range windowsBySession[s.Name] {
+		for _, wn := range windowsBySession[config.SessionKey(s.Server, s.Name)] {
 			combined += " " + wn
 		}
 		searchStrings[i] = combined
@@ -27,6 +27,33 @@ type GridItem interface {
 	Indicator() string
 }
 
+// KeyedItem is implemented by grid items whose title doesn't identify them,
+// e.g. same-named sessions on different servers. Marks are looked up by key.
+type KeyedItem interface {
+	Key() string
+}
+
+// itemKey returns the key item's mark is looked up by: its Key, or its title.
+func itemKey(item GridItem) string {
+	if k, ok := item.(KeyedItem); ok {
+		return k.Key()
+	}
+	return item.Title()
+}
+
+// SectionedItem is implemented by grid items that belong to a named section.
+// When the grid is grouped, each section starts on a new row under a header.
+type SectionedItem interface {
+	Section() string
+}
+
+// gridRow is one line of the grid layout: either a section header or a row
+// of cards referencing item indices.
+type gridRow struct {
+	header string
+	items  []int
+}
+
 // Grid manages a grid layout with auto-fit columns and keyboard focus.
 type Grid struct {
 	items        []GridItem
@@ -35,6 +62,8 @@ type Grid struct {
 	focusIndex   int
 	columns      int
 	rows         int
+	layout       []gridRow      // header and card rows, in render order
+	grouped      bool           // split items into sections with headers
 	cardContentW int            // computed per-card content width
 	usedWidth    int            // actual width used by card columns
 	scrollOffset int
@@ -76,7 +105,15 @@ func (g *Grid) SetMinCardWidth(w int) {
 	g.recalculate()
 }
 
-// SetMarks provides a mapping from item titles to mark key labels.
+// SetGrouped enables or disables section headers. Items must already be
+// ordered so that each section is contiguous.
+func (g *Grid) SetGrouped(grouped bool) {
+	g.grouped = grouped
+	g.recalculate()
+	g.ensureVisible()
+}
+
+// SetMarks provides a mapping from item keys (see itemKey) to mark key labels.
 func (g *Grid) SetMarks(marks map[string]string) {
 	g.markMap = marks
 }
@@ -87,19 +124,13 @@ func (g *Grid) MoveFocus(dx, dy int) {
 		return
 	}
 
-	row := g.focusIndex / g.columns
-	col := g.focusIndex % g.columns
-
-	newRow := clamp(row+dy, 0, g.rows-1)
-	newCol := clamp(col+dx, 0, g.columns-1)
+	row, col := g.position(g.focusIndex)
+	newRow := g.stepRow(row, dy)
+	cells := g.layout[newRow].items
 
-	newIndex := newRow*g.columns + newCol
-	if newIndex >= len(g.items) {
-		// Target cell doesn't exist (incomplete last row).
-		// Clamp to the last item on that row.
-		newIndex = len(g.items) - 1
-	}
-	if newIndex >= 0 && newIndex != g.focusIndex {
+	// Clamp to the last item on the target row (incomplete rows).
+	newIndex := cells[clamp(col+dx, 0, len(cells)-1)]
+	if newIndex != g.focusIndex {
 		g.focusIndex = newIndex
 		g.ensureVisible()
 	}
@@ -107,29 +138,29 @@ func (g *Grid) MoveFocus(dx, dy int) {
 
 // MoveItem swaps the focused item with its neighbor at offset (dx, dy)
 // and moves focus to the new position. Returns true if a swap occurred.
+// Items never move across sections.
 func (g *Grid) MoveItem(dx, dy int) bool {
 	if len(g.items) == 0 {
 		return false
 	}
 
-	row := g.focusIndex / g.columns
-	col := g.focusIndex % g.columns
-
-	newRow := row + dy
+	row, col := g.position(g.focusIndex)
 	newCol := col + dx
-	if newRow < 0 || newRow >= g.rows || newCol < 0 || newCol >= g.columns {
+	if newCol < 0 || newCol >= g.columns {
 		return false
 	}
-
-	newIndex := newRow*g.columns + newCol
-	if newIndex < 0 {
+	newRow := g.stepRow(row, dy)
+	if dy != 0 && newRow == row {
 		return false
 	}
-	if newIndex >= len(g.items) {
-		// Target cell doesn't exist (incomplete last row); clamp to last item.
-		newIndex = len(g.items) - 1
+	cells := g.layout[newRow].items
+	if newCol >= len(cells) {
+		// Target cell doesn't exist (incomplete row); clamp to its last item.
+		newCol = len(cells) - 1
 	}
-	if newIndex == g.focusIndex {
+
+	newIndex := cells[newCol]
+	if newIndex == g.focusIndex || sectionOf(g.items[newIndex]) != sectionOf(g.items[g.focusIndex]) {
 		return false
 	}
 
@@ -172,6 +203,52 @@ func (g *Grid) FocusFirstWhere(match func(GridItem) bool) {
 	}
 }
 
+// SetFocus moves focus to idx, clamped to the item range, and scrolls it
+// into view.
+func (g *Grid) SetFocus(idx int) {
+	if len(g.items) == 0 {
+		return
+	}
+	g.focusIndex = clamp(idx, 0, len(g.items)-1)
+	g.ensureVisible()
+}
+
+// ItemAt returns the index of the item whose card is drawn at (x, y),
+// relative to the grid's top-left corner, or -1 if there is none there.
+func (g *Grid) ItemAt(x, y int) int {
+	if x < 0 || y < 0 {
+		return -1
+	}
+	top := 0
+	for row := g.scrollOffset; row < g.visibleEnd(); row++ {
+		h := g.rowHeight(row)
+		if y < top+h {
+			r := g.layout[row]
+			if r.header != "" || y-top >= cardRenderedHeight {
+				return -1 // header or gap below the cards
+			}
+			if col := x / (g.cardContentW + cardBorderPadding); col < len(r.items) {
+				return r.items[col]
+			}
+			return -1
+		}
+		top += h
+	}
+	return -1
+}
+
+// ScrollBy scrolls the viewport by dy layout rows without moving focus.
+// Moving focus scrolls it back into view.
+func (g *Grid) ScrollBy(dy int) {
+	// The last offset that still fills the viewport.
+	maxOffset, used := len(g.layout), 0
+	for maxOffset > 0 && used+g.rowHeight(maxOffset-1) <= g.height {
+		maxOffset--
+		used += g.rowHeight(maxOffset)
+	}
+	g.scrollOffset = clamp(g.scrollOffset+dy, 0, max(maxOffset, 0))
+}
+
 // GetFocused returns the currently focused item, or nil.
 func (g *Grid) GetFocused() GridItem {
 	if g.focusIndex < len(g.items) {
@@ -188,28 +265,20 @@ func (g *Grid) Render() string {
 
 	g.recalculate()
 
-	effectiveRowH := cardRenderedHeight + cardRowGap
-	visibleRows := g.height / effectiveRowH
-	if visibleRows < 1 {
-		visibleRows = 1
-	}
-	endRow := min(g.scrollOffset+visibleRows, g.rows)
-
 	var lines []string
-	for row := g.scrollOffset; row < endRow; row++ {
+	for row := g.scrollOffset; row < g.visibleEnd(); row++ {
+		r := g.layout[row]
+		if r.header != "" {
+			lines = append(lines, g.styles.SectionHeader.Render(r.header))
+			continue
+		}
 		var rowCards []string
-		for col := 0; col < g.columns; col++ {
-			idx := row*g.columns + col
-			if idx >= len(g.items) {
-				break
-			}
+		for _, idx := range r.items {
 			rowCards = append(rowCards, g.renderCard(g.items[idx], idx == g.focusIndex))
 		}
-		lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Top, rowCards...))
+		lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Top, rowCards...)+strings.Repeat("\n", cardRowGap))
 	}
-
-	rowSep := "\n" + strings.Repeat("\n", cardRowGap)
-	return strings.Join(lines, rowSep)
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
 }
 
 // ---------------------------------------------------------------------------
@@ -229,29 +298,114 @@ func (g *Grid) recalculate() {
 		g.cardContentW = minW
 	}
 	g.usedWidth = g.columns * (g.cardContentW + cardBorderPadding + cardGap)
-	if len(g.items) > 0 {
-		g.rows = (len(g.items) + g.columns - 1) / g.columns
-	} else {
-		g.rows = 0
+
+	g.layout = g.layout[:0]
+	section := ""
+	for i, item := range g.items {
+		last := len(g.layout) - 1
+		if g.grouped {
+			if sec := sectionOf(item); i == 0 || sec != section {
+				section = sec
+				g.layout = append(g.layout, gridRow{header: sec})
+				last = -1
+			}
+		}
+		if last < 0 || g.layout[last].header != "" || len(g.layout[last].items) == g.columns {
+			g.layout = append(g.layout, gridRow{})
+			last = len(g.layout) - 1
+		}
+		g.layout[last].items = append(g.layout[last].items, i)
+	}
+	g.rows = len(g.layout)
+	if g.scrollOffset >= g.rows {
+		g.scrollOffset = max(0, g.rows-1)
+	}
+}
+
+// position returns the layout row and column of item idx.
+func (g *Grid) position(idx int) (row, col int) {
+	for r, gr := range g.layout {
+		for c, i := range gr.items {
+			if i == idx {
+				return r, c
+			}
+		}
+	}
+	return 0, 0
+}
+
+// stepRow moves |dy| card rows up or down from row, skipping section
+// headers and stopping at the first or last card row.
+func (g *Grid) stepRow(row, dy int) int {
+	step := 1
+	if dy < 0 {
+		step, dy = -1, -dy
+	}
+	for ; dy > 0; dy-- {
+		next := row + step
+		for next >= 0 && next < len(g.layout) && g.layout[next].header != "" {
+			next += step
+		}
+		if next < 0 || next >= len(g.layout) {
+			break
+		}
+		row = next
 	}
+	return row
+}
+
+// rowHeight returns the rendered height of a layout row, including the gap
+// below card rows.
+func (g *Grid) rowHeight(row int) int {
+	if g.layout[row].header != "" {
+		return 1
+	}
+	return cardRenderedHeight + cardRowGap
+}
+
+// visibleEnd returns the layout row just past the last one that fits in the
+// viewport when rendering from scrollOffset. At least one row is visible.
+func (g *Grid) visibleEnd() int {
+	used := 0
+	end := g.scrollOffset
+	for end < len(g.layout) {
+		h := g.rowHeight(end)
+		if used+h > g.height && end > g.scrollOffset {
+			break
+		}
+		used += h
+		end++
+	}
+	return end
 }
 
 func (g *Grid) ensureVisible() {
-	focusRow := g.focusIndex / g.columns
-	effectiveRowH := cardRenderedHeight + cardRowGap
-	visibleRows := g.height / effectiveRowH
-	if visibleRows < 1 {
-		visibleRows = 1
+	if len(g.layout) == 0 {
+		g.scrollOffset = 0
+		return
 	}
+	focusRow, _ := g.position(g.focusIndex)
 
-	if focusRow < g.scrollOffset {
+	if focusRow <= g.scrollOffset {
 		g.scrollOffset = focusRow
+		// Keep the section header above the first visible card row.
+		if focusRow > 0 && g.layout[focusRow-1].header != "" {
+			g.scrollOffset = focusRow - 1
+		}
 	}
-	if focusRow >= g.scrollOffset+visibleRows {
-		g.scrollOffset = focusRow - visibleRows + 1
+	for focusRow >= g.visibleEnd() && g.scrollOffset < focusRow {
+		g.scrollOffset++
 	}
 }
 
+// sectionOf returns the section an item belongs to, or "" if it has none.
+func sectionOf(item GridItem) string {
+	if s, ok := item.(SectionedItem); ok {
+		return s.Section()
+	}
+	return ""
+}
+
 func (g *Grid) renderCard(item GridItem, focused bool) string {
 	title := item.Title()
 	subtitle := item.Subtitle()
@@ -262,25 +416,21 @@ func (g *Grid) renderCard(item GridItem, focused bool) string {
 		contentW = minCardContentW
 	}
 
-	// Look up mark using the full title before truncation.
-	markKey, hasMark := g.markMap[title]
+	markKey, hasMark := g.markMap[itemKey(item)]
 
 	// Truncate long titles so cards stay uniform.
 	maxTitleLen := contentW - 2 // leave room for indicator
 	if hasMark {
-		maxTitleLen = contentW - 4 - len(markKey) // room for " [x]"
+		maxTitleLen = contentW - 4 - lipgloss.Width(markKey) // room for " [x]"
 	}
 	if indicator != "" {
-		maxTitleLen -= 2 // room for "● "
+		maxTitleLen -= lipgloss.Width(indicator) + 1 // room for "● "
 	}
 	if maxTitleLen < 6 {
 		maxTitleLen = 6
 	}
 
-	displayTitle := title
-	if len(displayTitle) > maxTitleLen {
-		displayTitle = displayTitle[:maxTitleLen-3] + "..."
-	}
+	displayTitle := truncateWidth(title, maxTitleLen)
 
 	// Select styles; focused cards carry the card background on all elements
 	// so the highlight is continuous.
@@ -318,7 +468,7 @@ func (g *Grid) renderCard(item GridItem, focused bool) string {
 		titleRendered = titleRendered + spacer + badge
 	}
 
-	subtitleRendered := subtitleStyle.Render(subtitle)
+	subtitleRendered := subtitleStyle.Render(truncateWidth(subtitle, contentW))
 	content := titleRendered + "\n" + subtitleRendered
 
 	// Apply dynamic width: border(2) + padding(2) + contentW.
@@ -337,6 +487,19 @@ func (g *Grid) renderCard(item GridItem, focused bool) string {
 	return style.Render(content)
 }
 
+// truncateWidth shortens s to at most w display cells, marking the cut
+// with an ellipsis.
+func truncateWidth(s string, w int) string {
+	if lipgloss.Width(s) <= w {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > w {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
 // clamp restricts v to [lo, hi].
 func clamp(v, lo, hi int) int {
 	if v < lo {
@@ -1,7 +1,10 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -17,29 +20,190 @@ import (
 type dialogAction int
 
 const (
-	dialogNone          dialogAction = iota
-	dialogNewSession                 // input → tmux new-session
-	dialogRenameSession              // input → tmux rename-session
-	dialogKillSession                // confirm → tmux kill-session
-	dialogNewWindow                  // input → tmux new-window
-	dialogRenameWindow               // input → tmux rename-window
-	dialogKillWindow                 // confirm → tmux kill-window
+	dialogNone             dialogAction = iota
+	dialogNewSession                    // form → tmux new-session
+	dialogRenameSession                 // input → tmux rename-session
+	dialogKillSession                   // confirm → tmux kill-session
+	dialogNewWindow                     // form → tmux new-window
+	dialogRenameWindow                  // input → tmux rename-window
+	dialogKillWindow                    // confirm → tmux kill-window
+	dialogKillPane                      // confirm → tmux kill-pane
+	dialogTagSession                    // input → add / remove a session tag
+	dialogCardMenu                      // menu → action for the focused card
+	dialogBroadcast                     // input → command to broadcast, then its targets
+	dialogBroadcastConfirm              // confirm → tmux send-keys to every target
 )
 
+// historyNames names the input histories kept in state.yaml per dialog.
+var historyNames = map[dialogAction]string{
+	dialogRenameSession: "rename_session",
+	dialogRenameWindow:  "rename_window",
+	dialogTagSession:    "tag",
+	dialogBroadcast:     "broadcast",
+}
+
+// formHistoryNames prefixes the per-field histories of the forms; each field
+// keeps its own, e.g. "new_session.directory".
+var formHistoryNames = map[dialogAction]string{
+	dialogNewSession: "new_session",
+	dialogNewWindow:  "new_window",
+}
+
+// fieldHistory names the history of the form field label.
+func fieldHistory(form, label string) string {
+	return form + "." + strings.ToLower(label)
+}
+
+// openFormDialog opens the form for action, each field with its history.
+func (m *Model) openFormDialog(action dialogAction, title string, fields []Field) {
+	for i := range fields {
+		fields[i].Input.SetHistory(m.config.History[fieldHistory(formHistoryNames[action], fields[i].Label)])
+	}
+	m.dialog = NewFormDialog(title, fields, m.styles)
+	m.pendingAction = action
+}
+
+// openInputDialog opens the input dialog for action, with its history and
+// tab completion.
+func (m *Model) openInputDialog(action dialogAction, title, message, value string) {
+	d := NewInputDialog(title, message, value, m.styles)
+	d.Input.SetHistory(m.config.History[historyNames[action]])
+	if complete := m.completer(action); complete != nil {
+		d.Input.SetCompleter(complete)
+	}
+	m.dialog = d
+	m.pendingAction = action
+}
+
+// completer returns the tab completion for the input of action, or nil.
+func (m *Model) completer(action dialogAction) func(string) []string {
+	switch action {
+	case dialogTagSession:
+		var tags, removable []string
+		for tag := range m.config.Tags {
+			tags = append(tags, tag)
+		}
+		if card, ok := m.sessionGrid.GetFocused().(SessionCard); ok {
+			for _, tag := range m.config.GetSessionTags(card.session.Server, card.session.Name) {
+				removable = append(removable, "-"+tag)
+			}
+		}
+		return func(s string) []string {
+			if strings.HasPrefix(s, "-") {
+				return completeWords(removable)(s)
+			}
+			return completeWords(tags)(s)
+		}
+	}
+	return nil
+}
+
+// Form field labels.
+const (
+	fieldName     = "Name"
+	fieldDir      = "Directory"
+	fieldCommand  = "Command"
+	fieldTemplate = "Template"
+)
+
+// handleNew opens the form creating a session or window. The directory
+// defaults to the one of the focused card.
 func (m *Model) handleNew() (tea.Model, tea.Cmd) {
+	dir := Field{Label: fieldDir, Input: NewLineEditor(m.focusedDir())}
+	dir.Input.SetCompleter(completeDir)
+	fields := []Field{{Label: fieldName}, dir, {Label: fieldCommand}}
+
 	switch m.currentMode {
 	case ModeSessionGrid:
-		m.dialog = NewInputDialog("New Session", "Session name:", "", m.styles)
-		m.pendingAction = dialogNewSession
+		if len(m.appConfig.Templates) > 0 {
+			names := make([]string, 0, len(m.appConfig.Templates))
+			for name := range m.appConfig.Templates {
+				names = append(names, name)
+			}
+			tmpl := Field{Label: fieldTemplate}
+			tmpl.Input.SetCompleter(completeWords(names))
+			fields = append(fields, tmpl)
+		}
+		m.openFormDialog(dialogNewSession, "New Session", fields)
 	case ModeWindowGrid:
-		m.dialog = NewInputDialog("New Window", "Window name:", "", m.styles)
-		m.pendingAction = dialogNewWindow
+		m.openFormDialog(dialogNewWindow, "New Window", fields)
 	case ModePaneGrid:
 		// No-op: pane creation is not supported.
 	}
 	return m, nil
 }
 
+// handleNewSessionHere creates a session in the directory tswitch was
+// launched from and switches (or attaches) to it.
+func (m *Model) handleNewSessionHere(dir string) (tea.Model, tea.Cmd) {
+	name := m.sessionNameFor(dir)
+	if err := m.tmux.NewSessionInDir(name, dir); err != nil {
+		m.setStatusError(err.Error())
+		return m, nil
+	}
+	if err := m.switchTo("", name, -1, -1); err != nil {
+		m.setStatusError(err.Error())
+		return m, nil
+	}
+	return m, tea.Quit
+}
+
+// sessionError describes a failure to create or rename session name.
+func sessionError(err error, name string) string {
+	if errors.Is(err, tmux.ErrDuplicateSession) {
+		return fmt.Sprintf("a session named %q already exists", name)
+	}
+	return err.Error()
+}
+
+// sessionNameFor derives a session name from dir. A numeric suffix keeps
+// the name unique.
+func (m *Model) sessionNameFor(dir string) string {
+	base := NormalizeSessionName(dir)
+	if base == "" {
+		base = "main"
+	}
+	name := base
+	for i := 2; m.tmux.HasSession(name); i++ {
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+	return name
+}
+
+// focusedDir returns the working directory of the focused card, or
+// tswitch's own if it has none.
+func (m *Model) focusedDir() string {
+	var dir string
+	switch card := m.activeGrid().GetFocused().(type) {
+	case SessionCard:
+		dir = card.session.ActivePaneDir
+	case NewSessionCard:
+		dir = card.dir
+	case WindowCard:
+		dir = card.window.WorkingDir
+	case PaneCard:
+		dir = card.pane.WorkingDir
+	}
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	return dir
+}
+
+// formDir returns the expanded directory field of d, or an error if it
+// doesn't name a directory. An empty field gives "".
+func formDir(d *Dialog) (string, error) {
+	dir := d.FieldValue(fieldDir)
+	if dir == "" {
+		return "", nil
+	}
+	dir = filepath.Clean(expandHome(dir))
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("not a directory: %s", dir)
+	}
+	return dir, nil
+}
+
 func (m *Model) handleRename() (tea.Model, tea.Cmd) {
 	switch m.currentMode {
 	case ModeSessionGrid:
@@ -47,15 +211,13 @@ func (m *Model) handleRename() (tea.Model, tea.Cmd) {
 		if !ok {
 			return m, nil
 		}
-		m.dialog = NewInputDialog("Rename Session", "New name:", card.session.Name, m.styles)
-		m.pendingAction = dialogRenameSession
+		m.openInputDialog(dialogRenameSession, "Rename Session", "New name:", card.session.Name)
 	case ModeWindowGrid:
 		card, ok := m.windowGrid.GetFocused().(WindowCard)
 		if !ok {
 			return m, nil
 		}
-		m.dialog = NewInputDialog("Rename Window", "New name:", card.window.Name, m.styles)
-		m.pendingAction = dialogRenameWindow
+		m.openInputDialog(dialogRenameWindow, "Rename Window", "New name:", card.window.Name)
 	case ModePaneGrid:
 		// No-op: pane renaming is not supported.
 	}
@@ -77,80 +239,188 @@ func (m *Model) handleKill() (tea.Model, tea.Cmd) {
 		if !ok {
 			return m, nil
 		}
-		m.dialog = NewConfirmDialog("Kill Window",
-			fmt.Sprintf("Kill window %q?", card.window.Name), m.styles)
+		msg := fmt.Sprintf("Kill window %q?", card.window.Name)
+		if card.window.Linked {
+			msg += "\nIt is linked; this kills it in " + strings.Join(card.window.LinkedSessions, ", ") + "."
+		}
+		m.dialog = NewConfirmDialog("Kill Window", msg, m.styles)
 		m.pendingAction = dialogKillWindow
 	case ModePaneGrid:
-		// No-op: pane killing is not supported.
+		card, ok := m.paneGrid.GetFocused().(PaneCard)
+		if !ok {
+			return m, nil
+		}
+		m.dialog = NewConfirmDialog("Kill Pane",
+			fmt.Sprintf("Kill pane %d (%s)?", card.pane.Index, card.pane.Command), m.styles)
+		m.pendingAction = dialogKillPane
 	}
 	return m, nil
 }
 
+// handleTag asks for a tag to add to the focused session; "-tag" removes it.
+func (m *Model) handleTag() (tea.Model, tea.Cmd) {
+	card, ok := m.sessionGrid.GetFocused().(SessionCard)
+	if m.currentMode != ModeSessionGrid || !ok {
+		return m, nil
+	}
+	msg := "Tag (-tag removes):"
+	if tags := m.config.GetSessionTags(card.session.Server, card.session.Name); len(tags) > 0 {
+		msg = "Tags: " + strings.Join(tags, ", ") + "\n" + msg
+	}
+	m.openInputDialog(dialogTagSession, "Tag Session", msg, "")
+	return m, nil
+}
+
 // ---------------------------------------------------------------------------
 // Dialog key handling
 // ---------------------------------------------------------------------------
 
-func (m *Model) handleDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+// handleDialogAction performs a dialog-scope action. Text-input dialogs only
+// react to accept and cancel; their printable keys go to handleTextKey. Menus
+// also move their selection; typing filters them.
+func (m *Model) handleDialogAction(action keys.Action) (tea.Model, tea.Cmd) {
 	d := m.dialog
-	switch d.Kind {
-	case DialogInput:
-		switch msg.String() {
-		case "esc":
-			m.dialog = nil
-		case "enter":
-			return m.submitDialog()
-		case "backspace":
-			if len(d.Input) > 0 {
-				runes := []rune(d.Input)
-				d.Input = string(runes[:len(runes)-1])
-			}
-		default:
-			if msg.Type == tea.KeyRunes {
-				d.Input += msg.String()
+	switch action {
+	case keys.ActionCancel:
+		m.dialog = nil
+	case keys.ActionAccept:
+		if d.Kind == DialogMenu {
+			if i := d.Selected(); i >= 0 {
+				return m.runMenuChoice(i)
 			}
+			return m, nil
 		}
-
-	case DialogConfirm:
-		switch msg.String() {
-		case "esc":
-			m.dialog = nil
-		case "y":
+		return m.submitDialog()
+	case keys.ActionMoveUp:
+		d.MoveSelection(-1)
+	case keys.ActionMoveDown:
+		d.MoveSelection(1)
+	case keys.ActionYes:
+		if d.Kind == DialogConfirm {
 			d.SelectedIdx = 0
 			return m.submitDialog()
-		case "n":
+		}
+	case keys.ActionNo:
+		if d.Kind == DialogConfirm {
 			m.dialog = nil
-		case "enter":
-			return m.submitDialog()
-		case "left", "h", "right", "l":
-			if len(d.Options) == 2 {
-				d.SelectedIdx = 1 - d.SelectedIdx
-			}
+		}
+	case keys.ActionMoveLeft, keys.ActionMoveRight:
+		if d.Kind == DialogConfirm && len(d.Options) == 2 {
+			d.SelectedIdx = 1 - d.SelectedIdx
 		}
 	}
 	return m, nil
 }
 
+// handleTextKey applies an editing key to the dialog's line editor (the
+// focused field of a form) or a printable key or backspace to the filter
+// term. ok is false for keys that aren't editing keys there.
+func (m *Model) handleTextKey(msg tea.KeyMsg) (model tea.Model, cmd tea.Cmd, ok bool) {
+	switch {
+	case m.dialog != nil:
+		if m.dialog.Kind == DialogConfirm || !m.dialog.Editor().HandleKey(msg) {
+			return m, nil, false
+		}
+		if m.dialog.Kind == DialogMenu {
+			m.dialog.SelectedIdx = 0 // select the best match
+		}
+		return m, nil, true
+	case m.filterMode:
+		switch msg.Type {
+		case tea.KeyRunes, tea.KeySpace, tea.KeyBackspace:
+			m.filterQuery = editText(m.filterQuery, msg)
+			m.applyFilter()
+			return m, m.syncPreview(), true
+		}
+	}
+	return m, nil, false
+}
+
+func editText(s string, msg tea.KeyMsg) string {
+	switch msg.Type {
+	case tea.KeyBackspace:
+		if runes := []rune(s); len(runes) > 0 {
+			return string(runes[:len(runes)-1])
+		}
+		return s
+	case tea.KeySpace:
+		return s + " "
+	}
+	return s + string(msg.Runes)
+}
+
 func (m *Model) submitDialog() (tea.Model, tea.Cmd) {
 	d := m.dialog
 	action := m.pendingAction
 	m.dialog = nil
 	m.pendingAction = dialogNone
 
+	added := false
+	if name, ok := historyNames[action]; ok {
+		if input := strings.TrimSpace(d.Input.Value()); input != "" {
+			m.config.AddHistory(name, input)
+			added = true
+		}
+	}
+	if form, ok := formHistoryNames[action]; ok {
+		for _, f := range d.Fields {
+			if input := strings.TrimSpace(f.Input.Value()); input != "" {
+				m.config.AddHistory(fieldHistory(form, f.Label), input)
+				added = true
+			}
+		}
+	}
+	if added {
+		if err := config.SaveState(m.config); err != nil {
+			m.setStatusError(fmt.Sprintf("Failed to save: %v", err))
+		}
+	}
+
 	switch action {
 	case dialogNewSession:
-		name := strings.TrimSpace(d.Input)
-		if name == "" {
-			m.setStatusError("session name cannot be empty")
+		dir, err := formDir(d)
+		if err != nil {
+			m.setStatusError(err.Error())
 			return m, nil
 		}
-		if err := m.tmux.NewSession(name); err != nil {
-			m.setStatusError(err.Error())
+		name := d.FieldValue(fieldName)
+		if name == "" {
+			if dir == "" {
+				m.setStatusError("session name cannot be empty")
+				return m, nil
+			}
+			name = m.sessionNameFor(dir)
+		}
+		spec := tmux.SessionSpec{Name: name, Dir: dir}
+		if t := d.FieldValue(fieldTemplate); t != "" {
+			tmpl, ok := m.appConfig.Templates[t]
+			if !ok {
+				m.setStatusError(fmt.Sprintf("unknown template %q", t))
+				return m, nil
+			}
+			for _, w := range tmpl.Windows {
+				spec.Windows = append(spec.Windows, tmux.WindowSpec{Name: w.Name, Dir: expandHome(w.Dir), Command: w.Command})
+			}
+		}
+		if cmd := d.FieldValue(fieldCommand); cmd != "" {
+			if len(spec.Windows) == 0 {
+				spec.Windows = []tmux.WindowSpec{{}}
+			}
+			spec.Windows[0].Command = cmd
+		}
+		if err := m.tmux.CreateSession(spec); err != nil {
+			m.setStatusError(sessionError(err, name))
 			return m, nil
 		}
 		m.setStatus("Created: " + name)
 		_ = m.loadSessions()
 		m.applyFilter()
-		m.sessionGrid.FocusFirstWhere(func(item GridItem) bool { return item.Title() == name })
+		// The session is on the current server; a same-named one on another
+		// server mustn't take the focus.
+		m.sessionGrid.FocusFirstWhere(func(item GridItem) bool {
+			card, ok := item.(SessionCard)
+			return ok && card.session.Server == "" && card.session.Name == name
+		})
 		return m, m.syncPreview()
 
 	case dialogRenameSession:
@@ -158,13 +428,13 @@ func (m *Model) submitDialog() (tea.Model, tea.Cmd) {
 		if !ok {
 			return m, nil
 		}
-		name := strings.TrimSpace(d.Input)
+		name := strings.TrimSpace(d.Input.Value())
 		if name == "" {
 			m.setStatusError("session name cannot be empty")
 			return m, nil
 		}
-		if err := m.tmux.RenameSession(card.session.Name, name); err != nil {
-			m.setStatusError(err.Error())
+		if err := m.serviceFor(card.session.Server).RenameSession(card.session.Name, name); err != nil {
+			m.setStatusError(sessionError(err, name))
 			return m, nil
 		}
 		m.setStatus("Renamed to: " + name)
@@ -179,27 +449,59 @@ func (m *Model) submitDialog() (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		name := card.session.Name
-		if err := m.tmux.KillSession(name); err != nil {
+		if err := m.serviceFor(card.session.Server).KillSession(name); err != nil {
 			m.setStatusError(err.Error())
 			return m, nil
 		}
 		m.setStatus("Killed: " + name)
 		return m.refreshSessions()
 
+	case dialogTagSession:
+		card, ok := m.sessionGrid.GetFocused().(SessionCard)
+		if !ok {
+			return m, nil
+		}
+		tag := strings.TrimSpace(d.Input.Value())
+		if remove := strings.HasPrefix(tag, "-"); remove {
+			tag = strings.TrimSpace(tag[1:])
+			if tag == "" {
+				return m, nil
+			}
+			m.config.RemoveSessionTag(card.session.Server, card.session.Name, tag)
+			m.setStatus(fmt.Sprintf("Untagged %s: %s", card.session.Name, tag))
+		} else {
+			if tag == "" {
+				m.setStatusError("tag cannot be empty")
+				return m, nil
+			}
+			m.config.AddSessionTag(card.session.Server, card.session.Name, tag)
+			m.setStatus(fmt.Sprintf("Tagged %s: %s", card.session.Name, tag))
+		}
+		if err := config.SaveState(m.config); err != nil {
+			m.setStatusError(fmt.Sprintf("Failed to save: %v", err))
+		}
+		return m, nil
+
 	case dialogNewWindow:
-		name := strings.TrimSpace(d.Input)
-		if name == "" {
-			m.setStatusError("window name cannot be empty")
+		dir, err := formDir(d)
+		if err != nil {
+			m.setStatusError(err.Error())
 			return m, nil
 		}
-		if err := m.tmux.NewWindow(m.currentSess, name); err != nil {
+		spec := tmux.WindowSpec{Name: d.FieldValue(fieldName), Dir: dir, Command: d.FieldValue(fieldCommand)}
+		index, err := m.svc().CreateWindow(m.currentSess, spec)
+		if err != nil {
 			m.setStatusError(err.Error())
 			return m, nil
 		}
-		m.setStatus("Created: " + name)
-		_ = m.loadWindows(m.currentSess)
+		m.setStatus("Created window in " + m.currentSess)
+		_ = m.snapshot()
+		_ = m.loadWindows(m.currentServer, m.currentSess)
 		m.applyFilter()
-		m.windowGrid.FocusFirstWhere(func(item GridItem) bool { return item.Title() == name })
+		m.windowGrid.FocusFirstWhere(func(item GridItem) bool {
+			card, ok := item.(WindowCard)
+			return ok && card.window.Index == index
+		})
 		return m, m.syncPreview()
 
 	case dialogRenameWindow:
@@ -207,12 +509,12 @@ func (m *Model) submitDialog() (tea.Model, tea.Cmd) {
 		if !ok {
 			return m, nil
 		}
-		name := strings.TrimSpace(d.Input)
+		name := strings.TrimSpace(d.Input.Value())
 		if name == "" {
 			m.setStatusError("window name cannot be empty")
 			return m, nil
 		}
-		if err := m.tmux.RenameWindow(m.currentSess, card.window.Index, name); err != nil {
+		if err := m.svc().RenameWindow(m.currentSess, card.window.Index, name); err != nil {
 			m.setStatusError(err.Error())
 			return m, nil
 		}
@@ -228,12 +530,49 @@ func (m *Model) submitDialog() (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		name := card.window.Name
-		if err := m.tmux.KillWindow(m.currentSess, card.window.Index); err != nil {
+		if err := m.svc().KillWindow(m.currentSess, card.window.Index); err != nil {
 			m.setStatusError(err.Error())
 			return m, nil
 		}
 		m.setStatus("Killed: " + name)
 		return m.refreshWindows()
+
+	case dialogBroadcast:
+		text := d.Input.Value()
+		if strings.TrimSpace(text) == "" {
+			m.setStatusError("command cannot be empty")
+			return m, nil
+		}
+		m.openBroadcastMenu(text)
+		return m, nil
+
+	case dialogBroadcastConfirm:
+		if d.SelectedIdx != 0 {
+			m.broadcast = nil
+			return m, nil // "No" selected
+		}
+		return m.sendBroadcast()
+
+	case dialogKillPane:
+		if d.SelectedIdx != 0 {
+			return m, nil // "No" selected
+		}
+		card, ok := m.paneGrid.GetFocused().(PaneCard)
+		if !ok {
+			return m, nil
+		}
+		if err := m.svc().KillPane(m.currentSess, m.currentWin, card.pane.Index); err != nil {
+			m.setStatusError(err.Error())
+			return m, nil
+		}
+		m.setStatus(fmt.Sprintf("Killed: pane %d", card.pane.Index))
+		if len(m.panes) == 1 {
+			// That was the window's last pane, so the window is gone too.
+			m.currentMode = ModeWindowGrid
+			m.applyLayout()
+			return m.refreshWindows()
+		}
+		return m.refreshCurrent()
 	}
 	return m, nil
 }
@@ -249,30 +588,21 @@ func (m *Model) enterFilterMode() tea.Cmd {
 	return m.syncPreview()
 }
 
-func (m *Model) handleFilterKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "esc":
+// handleFilterAction performs a filter-scope action. Actions other than
+// accept and cancel are not handled (ok is false) and fall through to the
+// grid, so e.g. arrow keys move focus while typing.
+func (m *Model) handleFilterAction(action keys.Action) (model tea.Model, cmd tea.Cmd, ok bool) {
+	switch action {
+	case keys.ActionCancel:
 		m.filterMode = false
 		m.filterQuery = ""
 		m.applyFilter()
-		return m, m.syncPreview()
-	case "enter":
+		return m, m.syncPreview(), true
+	case keys.ActionAccept:
 		m.filterMode = false
-	case "backspace":
-		if len(m.filterQuery) > 0 {
-			runes := []rune(m.filterQuery)
-			m.filterQuery = string(runes[:len(runes)-1])
-			m.applyFilter()
-			return m, m.syncPreview()
-		}
-	default:
-		if msg.Type == tea.KeyRunes {
-			m.filterQuery += msg.String()
-			m.applyFilter()
-			return m, m.syncPreview()
-		}
+		return m, nil, true
 	}
-	return m, nil
+	return m, nil, false
 }
 
 // ---------------------------------------------------------------------------
@@ -309,13 +639,16 @@ func (m *Model) handleBack() (tea.Model, tea.Cmd) {
 func (m *Model) handleConfirm() (tea.Model, tea.Cmd) {
 	switch m.currentMode {
 	case ModeSessionGrid:
+		if card, ok := m.sessionGrid.GetFocused().(NewSessionCard); ok {
+			return m.handleNewSessionHere(card.dir)
+		}
 		card, ok := m.sessionGrid.GetFocused().(SessionCard)
 		if !ok {
 			return m, nil
 		}
 		// Single-window session: skip window grid, drill into panes directly.
 		if card.session.WindowCount == 1 {
-			if err := m.loadWindows(card.session.Name); err != nil {
+			if err := m.loadWindows(card.session.Server, card.session.Name); err != nil {
 				m.setStatusError(err.Error())
 				return m, nil
 			}
@@ -324,7 +657,7 @@ func (m *Model) handleConfirm() (tea.Model, tea.Cmd) {
 				w := m.windows[0]
 				if w.PaneCount <= 1 {
 					// Single window, single pane: switch immediately.
-					if err := m.tmux.SelectPane(card.session.Name, w.Index, 0); err != nil {
+					if err := m.switchTo(card.session.Server, card.session.Name, w.Index, 0); err != nil {
 						m.setStatusError(err.Error())
 						return m, nil
 					}
@@ -341,7 +674,7 @@ func (m *Model) handleConfirm() (tea.Model, tea.Cmd) {
 				return m, m.syncPreview()
 			}
 		}
-		if err := m.loadWindows(card.session.Name); err != nil {
+		if err := m.loadWindows(card.session.Server, card.session.Name); err != nil {
 			m.setStatusError(err.Error())
 		} else {
 			m.resetFilter()
@@ -357,7 +690,7 @@ func (m *Model) handleConfirm() (tea.Model, tea.Cmd) {
 		}
 		// Single-pane window: switch immediately.
 		if card.window.PaneCount <= 1 {
-			if err := m.tmux.SelectPane(m.currentSess, card.window.Index, 0); err != nil {
+			if err := m.switchTo(m.currentServer, m.currentSess, card.window.Index, 0); err != nil {
 				m.setStatusError(err.Error())
 			} else {
 				return m, tea.Quit
@@ -385,25 +718,28 @@ func (m *Model) handleQuickSwap() (tea.Model, tea.Cmd) {
 	var err error
 	switch m.currentMode {
 	case ModeSessionGrid:
+		if card, ok := m.sessionGrid.GetFocused().(NewSessionCard); ok {
+			return m.handleNewSessionHere(card.dir)
+		}
 		card, ok := m.sessionGrid.GetFocused().(SessionCard)
 		if !ok || card.session.WindowCount == 0 {
 			return m, nil
 		}
-		err = m.tmux.SwitchToSession(card.session.Name)
+		err = m.switchTo(card.session.Server, card.session.Name, -1, -1)
 
 	case ModeWindowGrid:
 		card, ok := m.windowGrid.GetFocused().(WindowCard)
 		if !ok {
 			return m, nil
 		}
-		err = m.tmux.SwitchClient(m.currentSess, card.window.Index)
+		err = m.switchTo(m.currentServer, m.currentSess, card.window.Index, -1)
 
 	case ModePaneGrid:
 		card, ok := m.paneGrid.GetFocused().(PaneCard)
 		if !ok {
 			return m, nil
 		}
-		err = m.tmux.SelectPane(m.currentSess, m.currentWin, card.pane.Index)
+		err = m.switchTo(m.currentServer, m.currentSess, m.currentWin, card.pane.Index)
 	}
 
 	if err != nil {
@@ -413,6 +749,77 @@ func (m *Model) handleQuickSwap() (tea.Model, tea.Cmd) {
 	return m, tea.Quit
 }
 
+// switchTo switches the client to a target on one of the model's servers.
+// Outside tmux the attach command is recorded for main to exec once the TUI
+// has exited.
+func (m *Model) switchTo(server, sessionName string, windowIndex, paneIndex int) error {
+	argv, err := SwitchTo(m.servers, server, sessionName, windowIndex, paneIndex)
+	if err != nil {
+		return err
+	}
+	m.attachArgv = argv
+	return nil
+}
+
+// SwitchTo switches the client to a session (windowIndex < 0), window
+// (paneIndex < 0) or pane on server, a tmux.Server ID; servers[0] must be the
+// current server. A client can't switch across servers, so targets on another server
+// are attached from a new window. Outside tmux there is no client to switch:
+// the attach command line is returned for the caller to exec instead.
+func SwitchTo(servers []tmux.Server, server, sessionName string, windowIndex, paneIndex int) ([]string, error) {
+	current := servers[0].Service
+	if server == "" && current.IsInTmux() {
+		switch {
+		case windowIndex < 0:
+			return nil, current.SwitchToSession(sessionName)
+		case paneIndex < 0:
+			return nil, current.SwitchClient(sessionName, windowIndex)
+		default:
+			return nil, current.SelectPane(sessionName, windowIndex, paneIndex)
+		}
+	}
+
+	var svc tmux.Service
+	for _, srv := range servers {
+		if srv.ID() == server {
+			svc = srv.Service
+		}
+	}
+	if svc == nil {
+		return nil, fmt.Errorf("unknown tmux server %q", server)
+	}
+	target := sessionName
+	if windowIndex >= 0 {
+		target = fmt.Sprintf("%s:%d", sessionName, windowIndex)
+		if paneIndex >= 0 {
+			target += fmt.Sprintf(".%d", paneIndex)
+		}
+	}
+	argv := svc.AttachCommand(target)
+	if argv == nil {
+		return nil, fmt.Errorf("cannot attach to %s", target)
+	}
+	if !current.IsInTmux() {
+		return argv, nil
+	}
+	return nil, current.OpenWindow(sessionName+"@"+serverLabel(servers, server), argv)
+}
+
+// handleToggleGroup switches the session grid between the flat view and one
+// section per server, and persists the choice.
+func (m *Model) handleToggleGroup() (tea.Model, tea.Cmd) {
+	if len(m.servers) < 2 {
+		m.setStatusError("only one tmux server")
+		return m, nil
+	}
+	m.config.Settings.GroupByServer = !m.config.Settings.GroupByServer
+	if err := config.SaveState(m.config); err != nil {
+		m.setStatusError(fmt.Sprintf("Failed to save: %v", err))
+	}
+	m.applyFilter()
+	return m, m.syncPreview()
+}
+
 // ---------------------------------------------------------------------------
 // Marks
 // ---------------------------------------------------------------------------
@@ -452,8 +859,8 @@ func (m *Model) handleMarkAssignment(keyStr string) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		// Remove any existing mark pointing to the same target before setting new one.
-		m.config.RemoveMarksForTarget(card.session.Name, -1)
-		m.config.SetMark(keyStr, card.session.Name, -1, 0)
+		m.config.RemoveMarksForTarget(card.session.Server, card.session.Name, -1)
+		m.config.SetMark(keyStr, card.session.Server, card.session.Name, -1, -1)
 		if err := config.SaveState(m.config); err != nil {
 			m.setStatusError(fmt.Sprintf("Failed to save: %v", err))
 		} else {
@@ -465,8 +872,8 @@ func (m *Model) handleMarkAssignment(keyStr string) (tea.Model, tea.Cmd) {
 		if !ok {
 			return m, nil
 		}
-		m.config.RemoveMarksForTarget(m.currentSess, card.window.Index)
-		m.config.SetMark(keyStr, m.currentSess, card.window.Index, 0)
+		m.config.RemoveMarksForTarget(m.currentServer, m.currentSess, card.window.Index)
+		m.config.SetMark(keyStr, m.currentServer, m.currentSess, card.window.Index, -1)
 		if err := config.SaveState(m.config); err != nil {
 			m.setStatusError(fmt.Sprintf("Failed to save: %v", err))
 		} else {
@@ -478,7 +885,7 @@ func (m *Model) handleMarkAssignment(keyStr string) (tea.Model, tea.Cmd) {
 		if !ok {
 			return m, nil
 		}
-		m.config.SetMark(keyStr, m.currentSess, m.currentWin, card.pane.Index)
+		m.config.SetMark(keyStr, m.currentServer, m.currentSess, m.currentWin, card.pane.Index)
 		if err := config.SaveState(m.config); err != nil {
 			m.setStatusError(fmt.Sprintf("Failed to save: %v", err))
 		} else {
@@ -497,17 +904,9 @@ func (m *Model) handleJumpToMark(keyStr string) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	var err error
-	if mark.PaneIndex > 0 {
-		// Pane-level mark: switch to exact pane.
-		err = m.tmux.SelectPane(mark.SessionName, mark.WindowIndex, mark.PaneIndex)
-	} else if mark.WindowIndex < 0 {
-		// Session-level mark: let tmux pick the active window.
-		err = m.tmux.SwitchToSession(mark.SessionName)
-	} else {
-		err = m.tmux.SwitchClient(mark.SessionName, mark.WindowIndex)
-	}
-	if err != nil {
+	// Pane-level marks switch to the exact pane; session-level marks let tmux
+	// pick the active window.
+	if err := m.switchTo(mark.Server, mark.SessionName, mark.WindowIndex, mark.PaneIndex); err != nil {
 		m.setStatusError(err.Error())
 		return m, nil
 	}
@@ -523,6 +922,10 @@ func (m *Model) handleJumpToMark(keyStr string) (tea.Model, tea.Cmd) {
 // the pane capture asynchronously; in metadata mode it updates synchronously
 // and returns nil.
 func (m *Model) syncPreview() tea.Cmd {
+	if m.cyclingLayout && m.currentMode == ModePaneGrid {
+		m.previewLayoutPick()
+		return nil
+	}
 	if m.previewPanel.IsCapture() {
 		m.previewPanel.SetCaptureContent("") // clear stale content
 		return m.fetchCapture()
@@ -538,7 +941,8 @@ func (m *Model) syncPreview() tea.Cmd {
 		}
 	case ModePaneGrid:
 		if card, ok := m.paneGrid.GetFocused().(PaneCard); ok {
-			m.previewPanel.SetPaneMetadata(card.pane)
+			layout, _ := m.currentLayout()
+			m.previewPanel.SetPaneMetadata(card.pane, layout, m.panePos(card.pane.Index))
 		}
 	}
 	return nil
@@ -548,6 +952,7 @@ func (m *Model) syncPreview() tea.Cmd {
 // in a goroutine and delivers the result as a captureResultMsg.
 func (m *Model) fetchCapture() tea.Cmd {
 	var sessName string
+	svc := m.svc()
 	winIdx := -1 // -1 = active window of the session
 
 	paneIdx := 0
@@ -559,6 +964,7 @@ func (m *Model) fetchCapture() tea.Cmd {
 			return nil
 		}
 		sessName = card.session.Name
+		svc = m.serviceFor(card.session.Server)
 	case ModeWindowGrid:
 		card, ok := m.windowGrid.GetFocused().(WindowCard)
 		if !ok {
@@ -579,7 +985,7 @@ func (m *Model) fetchCapture() tea.Cmd {
 	}
 
 	return func() tea.Msg {
-		content, err := m.tmux.CapturePane(sessName, winIdx, paneIdx)
+		content, err := svc.CapturePane(sessName, winIdx, paneIdx)
 		if err != nil {
 			return captureResultMsg{"(capture error: " + err.Error() + ")"}
 		}
@@ -601,7 +1007,7 @@ func (m *Model) moveFocus(dx, dy int) tea.Cmd {
 }
 
 // ---------------------------------------------------------------------------
-// Cut / Paste (move window / pane)
+// Cut / Copy / Paste (move or link window, move pane)
 // ---------------------------------------------------------------------------
 
 // handleCut captures the focused window or pane onto m.clipboard. A second
@@ -620,10 +1026,11 @@ func (m *Model) handleCut() (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		m.clipboard = &clipboard{
-			kind:    "window",
-			srcSess: m.currentSess,
-			srcWin:  card.window.Index,
-			label:   fmt.Sprintf("window %q from %s", card.window.Name, m.currentSess),
+			kind:      "window",
+			srcServer: m.currentServer,
+			srcSess:   m.currentSess,
+			srcWin:    card.window.Index,
+			label:     fmt.Sprintf("window %q from %s", card.window.Name, m.currentSess),
 		}
 		m.setStatus("cut: " + m.clipboard.label)
 
@@ -633,11 +1040,12 @@ func (m *Model) handleCut() (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		m.clipboard = &clipboard{
-			kind:    "pane",
-			srcSess: m.currentSess,
-			srcWin:  m.currentWin,
-			srcPane: card.pane.Index,
-			label:   fmt.Sprintf("pane %d from %s:%d", card.pane.Index, m.currentSess, m.currentWin),
+			kind:      "pane",
+			srcServer: m.currentServer,
+			srcSess:   m.currentSess,
+			srcWin:    m.currentWin,
+			srcPane:   card.pane.Index,
+			label:     fmt.Sprintf("pane %d from %s:%d", card.pane.Index, m.currentSess, m.currentWin),
 		}
 		m.setStatus("cut: " + m.clipboard.label)
 
@@ -647,6 +1055,32 @@ func (m *Model) handleCut() (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// handleCopy puts the focused window onto m.clipboard; pasting it on a
+// session links the window there, so both sessions show it. Like cut, a
+// second press clears the clipboard.
+func (m *Model) handleCopy() (tea.Model, tea.Cmd) {
+	if m.clipboard != nil {
+		m.clipboard = nil
+		m.setStatus("clipboard cleared")
+		return m, nil
+	}
+	card, ok := m.windowGrid.GetFocused().(WindowCard)
+	if m.currentMode != ModeWindowGrid || !ok {
+		m.setStatusError("only windows can be copied")
+		return m, nil
+	}
+	m.clipboard = &clipboard{
+		kind:      "window",
+		copy:      true,
+		srcServer: m.currentServer,
+		srcSess:   m.currentSess,
+		srcWin:    card.window.Index,
+		label:     fmt.Sprintf("window %q from %s", card.window.Name, m.currentSess),
+	}
+	m.setStatus("copied: " + m.clipboard.label)
+	return m, nil
+}
+
 // handlePaste commits the clipboard onto the focused destination, validating
 // that the current mode matches the clipboard kind.
 func (m *Model) handlePaste() (tea.Model, tea.Cmd) {
@@ -666,15 +1100,28 @@ func (m *Model) handlePaste() (tea.Model, tea.Cmd) {
 		if !ok {
 			return m, nil
 		}
+		if card.session.Server != cb.srcServer {
+			m.setStatusError("cannot move or link a window to another server")
+			return m, nil
+		}
 		if card.session.Name == cb.srcSess {
 			m.setStatusError("already in this session")
 			return m, nil
 		}
-		if err := m.tmux.MoveWindow(cb.srcSess, cb.srcWin, card.session.Name); err != nil {
-			m.setStatusError(err.Error())
-			return m, nil
+		svc := m.serviceFor(cb.srcServer)
+		if cb.copy {
+			if err := svc.LinkWindow(cb.srcSess, cb.srcWin, card.session.Name); err != nil {
+				m.setStatusError(err.Error())
+				return m, nil
+			}
+			m.setStatus(fmt.Sprintf("linked %s → %s", cb.label, card.session.Name))
+		} else {
+			if err := svc.MoveWindow(cb.srcSess, cb.srcWin, card.session.Name); err != nil {
+				m.setStatusError(err.Error())
+				return m, nil
+			}
+			m.setStatus(fmt.Sprintf("moved %s → %s", cb.label, card.session.Name))
 		}
-		m.setStatus(fmt.Sprintf("moved %s → %s", cb.label, card.session.Name))
 		m.clipboard = nil
 		_ = m.loadSessions()
 		m.applyFilter()
@@ -694,17 +1141,22 @@ func (m *Model) handlePaste() (tea.Model, tea.Cmd) {
 		if !ok {
 			return m, nil
 		}
+		if m.currentServer != cb.srcServer {
+			m.setStatusError("cannot move a pane to another server")
+			return m, nil
+		}
 		if m.currentSess == cb.srcSess && card.window.Index == cb.srcWin {
 			m.setStatusError("already in this window")
 			return m, nil
 		}
-		if err := m.tmux.JoinPane(cb.srcSess, cb.srcWin, cb.srcPane, m.currentSess, card.window.Index); err != nil {
+		if err := m.svc().JoinPane(cb.srcSess, cb.srcWin, cb.srcPane, m.currentSess, card.window.Index); err != nil {
 			m.setStatusError(err.Error())
 			return m, nil
 		}
 		m.setStatus(fmt.Sprintf("moved %s → %s:%d", cb.label, m.currentSess, card.window.Index))
 		m.clipboard = nil
-		_ = m.loadWindows(m.currentSess)
+		_ = m.snapshot()
+		_ = m.loadWindows(m.currentServer, m.currentSess)
 		m.applyFilter()
 		return m, m.syncPreview()
 	}
@@ -713,10 +1165,6 @@ func (m *Model) handlePaste() (tea.Model, tea.Cmd) {
 
 // handleReorder swaps the focused item with its neighbor and persists the new order.
 func (m *Model) handleReorder(dx, dy int) (tea.Model, tea.Cmd) {
-	if m.currentMode == ModePaneGrid {
-		return m, nil // reordering panes is not supported
-	}
-
 	grid := m.activeGrid()
 
 	// Extract and persist the new order.
@@ -725,17 +1173,16 @@ func (m *Model) handleReorder(dx, dy int) (tea.Model, tea.Cmd) {
 		if !grid.MoveItem(dx, dy) {
 			return m, nil
 		}
-		items := grid.Items()
-		order := make([]string, len(items))
-		for i, item := range items {
-			order[i] = item.(SessionCard).session.Name
+		// Extract the new order, skipping the "new session here" card.
+		var order []string
+		m.sessions = m.sessions[:0]
+		for _, item := range grid.Items() {
+			if card, ok := item.(SessionCard); ok {
+				order = append(order, config.SessionKey(card.session.Server, card.session.Name))
+				m.sessions = append(m.sessions, card.session)
+			}
 		}
 		m.config.SetSessionOrder(order)
-		// Update m.sessions to match new order.
-		m.sessions = make([]tmux.Session, len(items))
-		for i, item := range items {
-			m.sessions[i] = item.(SessionCard).session
-		}
 
 	case ModeWindowGrid:
 		// Capture the two windows before the visual swap so we can call swap-window.
@@ -750,7 +1197,7 @@ func (m *Model) handleReorder(dx, dy int) (tea.Model, tea.Cmd) {
 		dstCard := grid.Items()[oldFocusPos].(WindowCard)
 
 		// Swap the windows in TMUX to keep display order in sync with actual order.
-		if err := m.tmux.SwapWindow(m.currentSess, srcCard.window.Index, dstCard.window.Index); err != nil {
+		if err := m.svc().SwapWindow(m.currentSess, srcCard.window.Index, dstCard.window.Index); err != nil {
 			m.setStatusError(err.Error())
 			// Undo the visual swap so the grid stays consistent with TMUX.
 			grid.MoveItem(-dx, -dy)
@@ -773,7 +1220,34 @@ func (m *Model) handleReorder(dx, dy int) (tea.Model, tea.Cmd) {
 		}
 
 		// TMUX is now the source of truth; clear any saved visual override.
-		m.config.ClearWindowOrder(m.currentSess)
+		m.config.ClearWindowOrder(m.sessionKey())
+		_ = m.snapshot() // the windows' panes moved with them
+
+	case ModePaneGrid:
+		oldFocusPos := grid.FocusIndex()
+		srcCard := grid.Items()[oldFocusPos].(PaneCard)
+
+		if !grid.MoveItem(dx, dy) {
+			return m, nil
+		}
+
+		dstCard := grid.Items()[oldFocusPos].(PaneCard)
+		if err := m.svc().SwapPane(m.currentSess, m.currentWin, srcCard.pane.Index, dstCard.pane.Index); err != nil {
+			m.setStatusError(err.Error())
+			grid.MoveItem(-dx, -dy)
+			return m, nil
+		}
+
+		// Pane indexes are positions, so the moved pane now has dst's index.
+		// Panes have no saved order; reload and keep the focus on it.
+		_ = m.snapshot()
+		_ = m.loadPanes(m.currentSess, m.currentWin)
+		m.applyFilter()
+		grid.FocusFirstWhere(func(item GridItem) bool {
+			card, ok := item.(PaneCard)
+			return ok && card.pane.Index == dstCard.pane.Index
+		})
+		return m, m.syncPreview()
 	}
 
 	if err := config.SaveState(m.config); err != nil {
@@ -781,3 +1255,149 @@ func (m *Model) handleReorder(dx, dy int) (tea.Model, tea.Cmd) {
 	}
 	return m, m.syncPreview()
 }
+
+// ---------------------------------------------------------------------------
+// Pane management
+// ---------------------------------------------------------------------------
+
+// focusedPane returns the focused pane of the pane grid.
+func (m *Model) focusedPane() (tmux.Pane, bool) {
+	card, ok := m.paneGrid.GetFocused().(PaneCard)
+	if m.currentMode != ModePaneGrid || !ok {
+		return tmux.Pane{}, false
+	}
+	return card.pane, true
+}
+
+// handleSplitPane splits the focused pane, side by side when horizontal.
+func (m *Model) handleSplitPane(horizontal bool) (tea.Model, tea.Cmd) {
+	p, ok := m.focusedPane()
+	if !ok {
+		return m, nil
+	}
+	return m.splitPane(m.currentServer, m.currentSess, m.currentWin, p.Index, horizontal)
+}
+
+// handleBreakPane moves the focused pane out into a window of its own.
+func (m *Model) handleBreakPane() (tea.Model, tea.Cmd) {
+	p, ok := m.focusedPane()
+	if !ok {
+		return m, nil
+	}
+	if err := m.svc().BreakPane(m.currentSess, m.currentWin, p.Index); err != nil {
+		m.setStatusError(err.Error())
+		return m, nil
+	}
+	m.setStatus(fmt.Sprintf("Moved pane %d to a new window", p.Index))
+	return m.refreshCurrent()
+}
+
+// handleZoomPane toggles zoom on the focused pane.
+func (m *Model) handleZoomPane() (tea.Model, tea.Cmd) {
+	p, ok := m.focusedPane()
+	if !ok {
+		return m, nil
+	}
+	if err := m.svc().ZoomPane(m.currentSess, m.currentWin, p.Index); err != nil {
+		m.setStatusError(err.Error())
+		return m, nil
+	}
+	m.setStatus(fmt.Sprintf("Toggled zoom on pane %d", p.Index))
+	return m.refreshCurrent()
+}
+
+// resizeStep is how many cells one resize key moves a pane border.
+const resizeStep = 5
+
+// handleResizePane moves the focused pane's border in direction dx, dy.
+func (m *Model) handleResizePane(dx, dy int) (tea.Model, tea.Cmd) {
+	p, ok := m.focusedPane()
+	if !ok {
+		return m, nil
+	}
+	if err := m.svc().ResizePane(m.currentSess, m.currentWin, p.Index, dx*resizeStep, dy*resizeStep); err != nil {
+		m.setStatusError(err.Error())
+		return m, nil
+	}
+	return m.refreshCurrent()
+}
+
+// currentLayout returns the layout of the window whose panes are shown,
+// labelled with the pane indexes.
+func (m *Model) currentLayout() (layoutDiagram, bool) {
+	for _, w := range m.windows {
+		if w.Index != m.currentWin {
+			continue
+		}
+		d, ok := parseLayout(w.Layout)
+		if !ok || len(d.panes) != len(m.panes) {
+			return layoutDiagram{}, false
+		}
+		for i := range d.panes {
+			d.panes[i].label = fmt.Sprint(m.panes[i].Index)
+		}
+		return d, true
+	}
+	return layoutDiagram{}, false
+}
+
+// panePos returns the position of the pane with index in the window's
+// layout, or -1.
+func (m *Model) panePos(index int) int {
+	for i, p := range m.panes {
+		if p.Index == index {
+			return i
+		}
+	}
+	return -1
+}
+
+// handleCycleLayout starts the layout cycler: the preview shows each preset
+// layout in turn and enter applies the one shown.
+func (m *Model) handleCycleLayout() (tea.Model, tea.Cmd) {
+	if m.currentMode != ModePaneGrid || len(m.panes) == 0 {
+		return m, nil
+	}
+	m.cyclingLayout, m.layoutPick = true, 0
+	return m, m.syncPreview()
+}
+
+// handleLayoutCycle handles an action while the layout cycler is open.
+// Other actions close the cycler and then run as usual (ok is false).
+func (m *Model) handleLayoutCycle(action keys.Action) (model tea.Model, cmd tea.Cmd, ok bool) {
+	n := len(layoutPresets)
+	switch action {
+	case keys.ActionCycleLayout, keys.ActionMoveRight, keys.ActionMoveDown:
+		m.layoutPick = (m.layoutPick + 1) % n
+		return m, m.syncPreview(), true
+	case keys.ActionMoveLeft, keys.ActionMoveUp:
+		m.layoutPick = (m.layoutPick - 1 + n) % n
+		return m, m.syncPreview(), true
+	case keys.ActionDirectSwitch, keys.ActionQuickSwap, keys.ActionConfirm:
+		m.cyclingLayout = false
+		layout := layoutPresets[m.layoutPick]
+		if err := m.svc().SelectLayout(m.currentSess, m.currentWin, layout); err != nil {
+			m.setStatusError(err.Error())
+			return m, m.syncPreview(), true
+		}
+		m.setStatus("Layout: " + layout)
+		model, cmd = m.refreshCurrent()
+		return model, cmd, true
+	case keys.ActionBack:
+		m.cyclingLayout = false
+		return m, m.syncPreview(), true
+	}
+	m.cyclingLayout = false
+	return m, nil, false
+}
+
+// previewLayoutPick shows the layout cycler's current preset.
+func (m *Model) previewLayoutPick() {
+	w, h := 80, 24
+	if d, ok := m.currentLayout(); ok {
+		w, h = d.w, d.h
+	}
+	name := layoutPresets[m.layoutPick]
+	m.previewPanel.SetLayoutPreview(name, m.layoutPick+1, len(layoutPresets),
+		presetLayout(name, len(m.panes), w, h))
+}
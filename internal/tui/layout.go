@@ -0,0 +1,288 @@
+package tui
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// layoutPresets are tmux's preset layouts, in the order the cycler shows them.
+var layoutPresets = []string{"even-horizontal", "even-vertical", "main-horizontal", "main-vertical", "tiled"}
+
+// paneRect is a pane's cells within its window.
+type paneRect struct {
+	x, y, w, h int
+	label      string
+}
+
+// layoutDiagram is a window's pane arrangement, drawn in the preview.
+type layoutDiagram struct {
+	w, h  int // window size in cells
+	panes []paneRect
+}
+
+// ---------------------------------------------------------------------------
+// Geometry
+// ---------------------------------------------------------------------------
+
+// parseLayout reads a tmux layout string such as
+// "b25f,80x24,0,0{40x24,0,0,1,39x24,41,0,2}". Panes come out in layout
+// order, which is also their index order.
+func parseLayout(layout string) (layoutDiagram, bool) {
+	i := strings.IndexByte(layout, ',') // skip the checksum
+	if i < 0 {
+		return layoutDiagram{}, false
+	}
+	p := layoutParser{s: layout[i+1:]}
+	root, ok := p.cell()
+	if !ok || p.i != len(p.s) || len(p.panes) == 0 {
+		return layoutDiagram{}, false
+	}
+	return layoutDiagram{w: root.w, h: root.h, panes: p.panes}, true
+}
+
+type layoutParser struct {
+	s     string
+	i     int
+	panes []paneRect
+}
+
+// cell parses "WxH,X,Y" followed by a pane id or a {row} / [column] of
+// cells, collecting the panes.
+func (p *layoutParser) cell() (paneRect, bool) {
+	var r paneRect
+	ok := p.num(&r.w) && p.eat('x') && p.num(&r.h) && p.eat(',') &&
+		p.num(&r.x) && p.eat(',') && p.num(&r.y)
+	if !ok || p.i >= len(p.s) {
+		return r, false
+	}
+	switch open := p.s[p.i]; open {
+	case '{', '[':
+		p.i++
+		for {
+			if _, ok := p.cell(); !ok {
+				return r, false
+			}
+			if !p.eat(',') {
+				break
+			}
+		}
+		closer := byte('}')
+		if open == '[' {
+			closer = ']'
+		}
+		return r, p.eat(closer)
+	case ',':
+		p.i++
+		var id int
+		if !p.num(&id) {
+			return r, false
+		}
+		r.label = strconv.Itoa(len(p.panes))
+		p.panes = append(p.panes, r)
+		return r, true
+	}
+	return r, false
+}
+
+func (p *layoutParser) num(n *int) bool {
+	start := p.i
+	for p.i < len(p.s) && p.s[p.i] >= '0' && p.s[p.i] <= '9' {
+		p.i++
+	}
+	v, err := strconv.Atoi(p.s[start:p.i])
+	*n = v
+	return err == nil
+}
+
+func (p *layoutParser) eat(c byte) bool {
+	if p.i < len(p.s) && p.s[p.i] == c {
+		p.i++
+		return true
+	}
+	return false
+}
+
+// presetLayout arranges n panes in a w×h window the way the named preset
+// does, close enough for a preview. Main panes take half the window.
+func presetLayout(name string, n, w, h int) layoutDiagram {
+	d := layoutDiagram{w: w, h: h}
+	add := func(x, y, pw, ph int) {
+		d.panes = append(d.panes, paneRect{x: x, y: y, w: pw, h: ph, label: strconv.Itoa(len(d.panes))})
+	}
+	if n < 1 {
+		return d
+	}
+	switch name {
+	case "even-horizontal":
+		for _, c := range spans(w, n) {
+			add(c[0], 0, c[1], h)
+		}
+	case "even-vertical":
+		for _, r := range spans(h, n) {
+			add(0, r[0], w, r[1])
+		}
+	case "main-horizontal", "main-vertical":
+		if n == 1 {
+			add(0, 0, w, h)
+			break
+		}
+		vertical := name == "main-vertical"
+		size := h
+		if vertical {
+			size = w
+		}
+		main := spans(size, 2)[0][1]
+		rest := size - main - 1
+		if vertical {
+			add(0, 0, main, h)
+			for _, r := range spans(h, n-1) {
+				add(main+1, r[0], rest, r[1])
+			}
+		} else {
+			add(0, 0, w, main)
+			for _, c := range spans(w, n-1) {
+				add(c[0], main+1, c[1], rest)
+			}
+		}
+	default: // tiled
+		rows, cols := 1, 1
+		for rows*cols < n {
+			rows++
+			if rows*cols < n {
+				cols++
+			}
+		}
+		for i, r := range spans(h, rows) {
+			inRow := min(cols, n-i*cols)
+			for _, c := range spans(w, inRow) {
+				add(c[0], r[0], c[1], r[1])
+			}
+		}
+	}
+	return d
+}
+
+// spans divides size cells into n parts separated by one-cell borders,
+// returning each part's start and length.
+func spans(size, n int) [][2]int {
+	avail := max(size-(n-1), n)
+	out := make([][2]int, n)
+	pos := 0
+	for i := range out {
+		l := avail / n
+		if i < avail%n {
+			l++
+		}
+		out[i] = [2]int{pos, l}
+		pos += l + 1
+	}
+	return out
+}
+
+// ---------------------------------------------------------------------------
+// Drawing
+// ---------------------------------------------------------------------------
+
+// Box-drawing characters by the directions their lines run in.
+const (
+	lineUp = 1 << iota
+	lineDown
+	lineLeft
+	lineRight
+)
+
+var boxChars = map[int]rune{
+	lineUp: '│', lineDown: '│', lineUp | lineDown: '│',
+	lineLeft: '─', lineRight: '─', lineLeft | lineRight: '─',
+	lineDown | lineRight: '┌', lineDown | lineLeft: '┐',
+	lineUp | lineRight: '└', lineUp | lineLeft: '┘',
+	lineUp | lineDown | lineRight: '├', lineUp | lineDown | lineLeft: '┤',
+	lineDown | lineLeft | lineRight: '┬', lineUp | lineLeft | lineRight: '┴',
+	lineUp | lineDown | lineLeft | lineRight: '┼',
+}
+
+// Render draws the panes as boxes scaled to fit maxW×maxH cells, keeping
+// the window's proportions. The pane at index focus has its label drawn
+// with focusStyle; -1 highlights none.
+func (d layoutDiagram) Render(maxW, maxH, focus int, focusStyle lipgloss.Style) string {
+	if d.w < 1 || d.h < 1 || maxW < 3 || maxH < 3 {
+		return ""
+	}
+	cw := maxW
+	ch := clamp(cw*d.h/d.w, 3, maxH)
+	if ch == maxH {
+		cw = clamp(ch*d.w/d.h, 3, maxW)
+	}
+
+	// A pane's borders lie just outside its cells, shared with neighbours;
+	// the window's own edges are at -1 and w (or h).
+	scale := func(c, size, cells int) int { return (c + 1) * (cells - 1) / (size + 1) }
+
+	lines := make([][]int, ch)
+	for y := range lines {
+		lines[y] = make([]int, cw)
+	}
+	labels := make([][2]int, len(d.panes)) // row and column of each label
+	for i, p := range d.panes {
+		x0, x1 := scale(p.x-1, d.w, cw), scale(p.x+p.w, d.w, cw)
+		y0, y1 := scale(p.y-1, d.h, ch), scale(p.y+p.h, d.h, ch)
+		for x := x0; x <= x1; x++ {
+			for _, y := range []int{y0, y1} {
+				if x > x0 {
+					lines[y][x] |= lineLeft
+				}
+				if x < x1 {
+					lines[y][x] |= lineRight
+				}
+			}
+		}
+		for y := y0; y <= y1; y++ {
+			for _, x := range []int{x0, x1} {
+				if y > y0 {
+					lines[y][x] |= lineUp
+				}
+				if y < y1 {
+					lines[y][x] |= lineDown
+				}
+			}
+		}
+		labels[i] = [2]int{-1, -1} // no room
+		if x1-x0-1 >= len(p.label) && y1-y0 >= 2 {
+			labels[i] = [2]int{(y0 + y1) / 2, (x0+x1+1)/2 - len(p.label)/2}
+		}
+	}
+
+	rows := make([]string, ch)
+	for y, row := range lines {
+		var b strings.Builder
+		for x := 0; x < cw; x++ {
+			if i := labelAt(labels, y, x); i >= 0 {
+				text := d.panes[i].label
+				if i == focus {
+					text = focusStyle.Render(text)
+				}
+				b.WriteString(text)
+				x += len(d.panes[i].label) - 1
+				continue
+			}
+			if r, ok := boxChars[row[x]]; ok {
+				b.WriteRune(r)
+			} else {
+				b.WriteByte(' ')
+			}
+		}
+		rows[y] = b.String()
+	}
+	return strings.Join(rows, "\n")
+}
+
+func labelAt(labels [][2]int, y, x int) int {
+	for i, l := range labels {
+		if l == [2]int{y, x} {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,161 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestParseLayout(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout string
+		want   []paneRect // nil = rejected
+	}{
+		{"single pane", "b25f,80x24,0,0,1", []paneRect{{0, 0, 80, 24, "0"}}},
+		{
+			"row", "b25f,80x24,0,0{40x24,0,0,1,39x24,41,0,2}",
+			[]paneRect{{0, 0, 40, 24, "0"}, {41, 0, 39, 24, "1"}},
+		},
+		{
+			"column in a row", "c0de,80x24,0,0{40x24,0,0,1,39x24,41,0[39x12,41,0,2,39x11,41,13,3]}",
+			[]paneRect{{0, 0, 40, 24, "0"}, {41, 0, 39, 12, "1"}, {41, 13, 39, 11, "2"}},
+		},
+		{"empty", "", nil},
+		{"no checksum", "80x24,0,0,1", nil}, // the size is taken for the checksum
+		{"only a checksum", "b25f", nil},
+		{"no pane id", "b25f,80x24,0,0", nil},
+		{"bad pane id", "b25f,80x24,0,0,x", nil},
+		{"bad size", "b25f,80y24,0,0,1", nil},
+		{"missing offset", "b25f,80x24,0,1", nil},
+		{"negative offset", "b25f,80x24,-1,0,1", nil},
+		{"empty row", "b25f,80x24,0,0{}", nil},
+		{"unclosed row", "b25f,80x24,0,0{40x24,0,0,1,39x24,41,0,2", nil},
+		{"wrong closer", "b25f,80x24,0,0{40x24,0,0,1,39x24,41,0,2]", nil},
+		{"trailing comma in row", "b25f,80x24,0,0{40x24,0,0,1,}", nil},
+		{"trailing text", "b25f,80x24,0,0,1 junk", nil},
+		{"two roots", "b25f,80x24,0,0,1,80x24,0,0,2", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, ok := parseLayout(tt.layout)
+			if ok != (tt.want != nil) {
+				t.Fatalf("parseLayout(%q) ok = %v, want %v", tt.layout, ok, tt.want != nil)
+			}
+			if !ok {
+				if d.w != 0 || d.h != 0 || d.panes != nil {
+					t.Errorf("rejected layout left %+v, want the zero diagram", d)
+				}
+				return
+			}
+			if d.w != 80 || d.h != 24 {
+				t.Errorf("window = %dx%d, want 80x24", d.w, d.h)
+			}
+			if !reflect.DeepEqual(d.panes, tt.want) {
+				t.Errorf("panes = %+v, want %+v", d.panes, tt.want)
+			}
+		})
+	}
+}
+
+func TestPresetLayout(t *testing.T) {
+	for _, name := range layoutPresets {
+		for n := 1; n <= 7; n++ {
+			d := presetLayout(name, n, 80, 24)
+			if len(d.panes) != n {
+				t.Errorf("%s with %d panes: got %d", name, n, len(d.panes))
+				continue
+			}
+			checkTiling(t, d, name)
+		}
+	}
+
+	// Names tmux doesn't have draw as tiled.
+	for _, name := range []string{"", "nope", "Tiled"} {
+		if got, want := presetLayout(name, 5, 80, 24), presetLayout("tiled", 5, 80, 24); !reflect.DeepEqual(got, want) {
+			t.Errorf("presetLayout(%q) = %+v, want tiled %+v", name, got, want)
+		}
+	}
+
+	for _, n := range []int{0, -1} {
+		if d := presetLayout("tiled", n, 80, 24); len(d.panes) != 0 || d.w != 80 || d.h != 24 {
+			t.Errorf("presetLayout with %d panes = %+v, want an empty 80x24 window", n, d)
+		}
+	}
+	if d := presetLayout("main-vertical", 1, 80, 24); !reflect.DeepEqual(d.panes, []paneRect{{0, 0, 80, 24, "0"}}) {
+		t.Errorf("main-vertical with one pane = %+v, want the whole window", d.panes)
+	}
+}
+
+// checkTiling reports panes of d that leave the window or overlap.
+func checkTiling(t *testing.T, d layoutDiagram, name string) {
+	t.Helper()
+	cells := make([][]int, d.h)
+	for y := range cells {
+		cells[y] = make([]int, d.w)
+	}
+	for i, p := range d.panes {
+		if p.x < 0 || p.y < 0 || p.w < 1 || p.h < 1 || p.x+p.w > d.w || p.y+p.h > d.h {
+			t.Errorf("%s with %d panes: pane %d %+v outside the window", name, len(d.panes), i, p)
+			return
+		}
+		for y := p.y; y < p.y+p.h; y++ {
+			for x := p.x; x < p.x+p.w; x++ {
+				if cells[y][x]++; cells[y][x] > 1 {
+					t.Errorf("%s with %d panes: pane %d overlaps at %d,%d", name, len(d.panes), i, x, y)
+					return
+				}
+			}
+		}
+	}
+}
+
+func TestSpans(t *testing.T) {
+	tests := []struct {
+		size, n int
+		want    [][2]int
+	}{
+		{80, 1, [][2]int{{0, 80}}},
+		{80, 2, [][2]int{{0, 40}, {41, 39}}},
+		{10, 3, [][2]int{{0, 3}, {4, 3}, {8, 2}}},
+		{2, 3, [][2]int{{0, 1}, {2, 1}, {4, 1}}}, // too small: every part keeps a cell
+	}
+	for _, tt := range tests {
+		if got := spans(tt.size, tt.n); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("spans(%d, %d) = %v, want %v", tt.size, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestLayoutRender(t *testing.T) {
+	d, ok := parseLayout("b25f,80x24,0,0{40x24,0,0,1,39x24,41,0,2}")
+	if !ok {
+		t.Fatal("layout rejected")
+	}
+	// 12 cells wide keeps 80x24's proportions at 3 rows.
+	want := strings.Join([]string{
+		"┌────┬─────┐",
+		"│  0 │  1  │",
+		"└────┴─────┘",
+	}, "\n")
+	if got := d.Render(12, 5, -1, lipgloss.NewStyle()); got != want {
+		t.Errorf("Render =\n%s\nwant\n%s", got, want)
+	}
+
+	// Nothing is drawn where it can't be.
+	for _, tt := range []struct {
+		d          layoutDiagram
+		maxW, maxH int
+	}{
+		{d, 2, 5},
+		{d, 12, 2},
+		{layoutDiagram{}, 12, 5},
+		{layoutDiagram{w: 80}, 12, 5},
+	} {
+		if got := tt.d.Render(tt.maxW, tt.maxH, 0, lipgloss.NewStyle()); got != "" {
+			t.Errorf("Render(%d, %d) of %dx%d =\n%s\nwant nothing", tt.maxW, tt.maxH, tt.d.w, tt.d.h, got)
+		}
+	}
+}
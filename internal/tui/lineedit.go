@@ -0,0 +1,332 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// LineEditor is a single-line text editor with a cursor, readline-style
+// motions, history and tab completion. The zero value is an empty editor
+// without history or completion.
+type LineEditor struct {
+	text []rune
+	pos  int // cursor, 0..len(text)
+
+	history []string // oldest first; browsed with up/down
+	histPos int      // index into history; len(history) is the line being typed
+	draft   string   // the line being typed while browsing history
+
+	complete func(string) []string // candidates for the current value; nil disables tab
+	matches  []string              // candidates of the completion in progress
+	matchPos int                   // candidate shown, -1 before cycling starts
+}
+
+// NewLineEditor returns an editor holding value with the cursor at the end.
+func NewLineEditor(value string) LineEditor {
+	var e LineEditor
+	e.SetValue(value)
+	return e
+}
+
+// Value returns the edited text.
+func (e *LineEditor) Value() string {
+	return string(e.text)
+}
+
+// SetValue replaces the text and puts the cursor at the end.
+func (e *LineEditor) SetValue(s string) {
+	e.text = []rune(s)
+	e.pos = len(e.text)
+}
+
+// SetHistory enables up/down through history, oldest entry first.
+func (e *LineEditor) SetHistory(history []string) {
+	e.history = history
+	e.histPos = len(history)
+}
+
+// SetCompleter enables tab completion; complete returns the candidates for
+// the whole current value.
+func (e *LineEditor) SetCompleter(complete func(string) []string) {
+	e.complete = complete
+}
+
+// Completions returns the candidates of the completion in progress.
+func (e *LineEditor) Completions() []string {
+	return e.matches
+}
+
+// HandleKey applies an editing key and reports whether msg was one. Keys
+// the editor doesn't use (enter, esc, up/down without history, tab with
+// nothing to complete) are left to the caller.
+func (e *LineEditor) HandleKey(msg tea.KeyMsg) bool {
+	if msg.Type == tea.KeyTab || msg.Type == tea.KeyShiftTab {
+		return e.complete != nil && e.cycleCompletion(msg.Type == tea.KeyShiftTab)
+	}
+	if msg.Type == tea.KeyUp || msg.Type == tea.KeyDown {
+		if e.history == nil {
+			return false
+		}
+		e.browseHistory(msg.Type == tea.KeyUp)
+		return true
+	}
+
+	// Alt+letter and alt+backspace are word commands, not the plain keys.
+	switch {
+	case msg.Type == tea.KeyRunes && !msg.Alt:
+		runes := msg.Runes
+		if msg.Paste {
+			runes = []rune(strings.Join(strings.Fields(string(runes)), " ")) // one line
+		}
+		e.insert(runes)
+	case msg.Type == tea.KeySpace:
+		e.insert([]rune{' '})
+	case (msg.Type == tea.KeyBackspace || msg.Type == tea.KeyCtrlH) && !msg.Alt:
+		if e.pos > 0 {
+			e.text = append(e.text[:e.pos-1], e.text[e.pos:]...)
+			e.pos--
+		}
+	case msg.Type == tea.KeyDelete || msg.Type == tea.KeyCtrlD:
+		if e.pos < len(e.text) {
+			e.text = append(e.text[:e.pos], e.text[e.pos+1:]...)
+		}
+	case msg.Type == tea.KeyLeft || msg.Type == tea.KeyCtrlB:
+		e.pos = max(e.pos-1, 0)
+	case msg.Type == tea.KeyRight || msg.Type == tea.KeyCtrlF:
+		e.pos = min(e.pos+1, len(e.text))
+	case msg.Type == tea.KeyHome || msg.Type == tea.KeyCtrlA:
+		e.pos = 0
+	case msg.Type == tea.KeyEnd || msg.Type == tea.KeyCtrlE:
+		e.pos = len(e.text)
+	case msg.Type == tea.KeyCtrlLeft || msg.String() == "alt+b":
+		e.pos = e.wordStart()
+	case msg.Type == tea.KeyCtrlRight || msg.String() == "alt+f":
+		e.pos = e.wordEnd()
+	case msg.Type == tea.KeyCtrlW || msg.String() == "alt+backspace":
+		start := e.wordStart()
+		e.text = append(e.text[:start], e.text[e.pos:]...)
+		e.pos = start
+	case msg.String() == "alt+d":
+		end := e.wordEnd()
+		e.text = append(e.text[:e.pos], e.text[end:]...)
+	case msg.Type == tea.KeyCtrlU:
+		e.text = e.text[e.pos:]
+		e.pos = 0
+	case msg.Type == tea.KeyCtrlK:
+		e.text = e.text[:e.pos]
+	default:
+		return false
+	}
+	e.matches = nil
+	return true
+}
+
+func (e *LineEditor) insert(runes []rune) {
+	text := make([]rune, 0, len(e.text)+len(runes))
+	text = append(append(append(text, e.text[:e.pos]...), runes...), e.text[e.pos:]...)
+	e.text = text
+	e.pos += len(runes)
+}
+
+// wordStart returns the start of the word before the cursor. Words are runs
+// of letters and digits, so ctrl-w stops at "/", "-" and friends.
+func (e *LineEditor) wordStart() int {
+	i := e.pos
+	for i > 0 && !isWordRune(e.text[i-1]) {
+		i--
+	}
+	for i > 0 && isWordRune(e.text[i-1]) {
+		i--
+	}
+	return i
+}
+
+// wordEnd returns the end of the word after the cursor.
+func (e *LineEditor) wordEnd() int {
+	i := e.pos
+	for i < len(e.text) && !isWordRune(e.text[i]) {
+		i++
+	}
+	for i < len(e.text) && isWordRune(e.text[i]) {
+		i++
+	}
+	return i
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// browseHistory replaces the text with the previous (up) or next entry. Going
+// past the newest entry restores the line being typed.
+func (e *LineEditor) browseHistory(up bool) {
+	if e.histPos == len(e.history) {
+		e.draft = e.Value()
+	}
+	switch {
+	case up && e.histPos > 0:
+		e.histPos--
+	case !up && e.histPos < len(e.history):
+		e.histPos++
+	default:
+		return
+	}
+	if e.histPos == len(e.history) {
+		e.SetValue(e.draft)
+	} else {
+		e.SetValue(e.history[e.histPos])
+	}
+	e.matches = nil
+}
+
+// cycleCompletion completes the value. The first tab extends it to the
+// longest prefix shared by all candidates; when that adds nothing, tabs
+// cycle through the candidates (shift-tab backwards). It reports false if
+// there is nothing to complete.
+func (e *LineEditor) cycleCompletion(back bool) bool {
+	if e.matches == nil {
+		e.matches = e.complete(e.Value())
+		e.matchPos = -1
+		if len(e.matches) == 0 {
+			e.matches = nil
+			return false
+		}
+		if prefix := commonPrefix(e.matches); len(prefix) > len(e.Value()) {
+			e.SetValue(prefix)
+			if len(e.matches) == 1 {
+				e.matches = nil
+			}
+			return true
+		}
+		if len(e.matches) == 1 {
+			e.matches = nil // already complete
+			return false
+		}
+	}
+	n := len(e.matches)
+	switch {
+	case !back:
+		e.matchPos = (e.matchPos + 1) % n
+	case e.matchPos < 0:
+		e.matchPos = n - 1
+	default:
+		e.matchPos = (e.matchPos - 1 + n) % n
+	}
+	e.text = []rune(e.matches[e.matchPos])
+	e.pos = len(e.text)
+	return true
+}
+
+func commonPrefix(ss []string) string {
+	prefix := ss[0]
+	for _, s := range ss[1:] {
+		for !strings.HasPrefix(s, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
+
+// View renders the text with the cursor, scrolled horizontally so the
+// cursor stays within width cells.
+func (e *LineEditor) View(width int, cursor lipgloss.Style) string {
+	start := 0
+	for lipgloss.Width(string(e.text[start:e.pos])) >= width && start < e.pos {
+		start++
+	}
+	var b strings.Builder
+	used := 0
+	for i := start; i <= len(e.text); i++ {
+		if i == len(e.text) {
+			if i == e.pos {
+				b.WriteString(cursor.Render(" "))
+			}
+			break
+		}
+		w := lipgloss.Width(string(e.text[i]))
+		if used+w > width {
+			break
+		}
+		used += w
+		if i == e.pos {
+			b.WriteString(cursor.Render(string(e.text[i])))
+		} else {
+			b.WriteRune(e.text[i])
+		}
+	}
+	return b.String()
+}
+
+// ---------------------------------------------------------------------------
+// Completers
+// ---------------------------------------------------------------------------
+
+// completeWords returns the words starting with s, sorted.
+func completeWords(words []string) func(string) []string {
+	return func(s string) []string {
+		var out []string
+		for _, w := range words {
+			if strings.HasPrefix(w, s) {
+				out = append(out, w)
+			}
+		}
+		sort.Strings(out)
+		return out
+	}
+}
+
+// completeDir returns the directories whose path starts with s, each with a
+// trailing slash. A leading "~" is kept as typed. Hidden directories are
+// only offered once the name being completed starts with a dot.
+func completeDir(s string) []string {
+	if s == "" {
+		return nil
+	}
+	typed := s
+	if s == "~" {
+		typed, s = "~/", expandHome("~/")
+	}
+	dir, base := filepath.Split(expandHome(s))
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	prefix := typed[:len(typed)-len(base)]
+	var out []string
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, base) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".")) {
+			continue
+		}
+		if e.IsDir() || isDir(filepath.Join(dir, name)) {
+			out = append(out, prefix+name+"/")
+		}
+	}
+	return out
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// expandHome replaces a leading "~" in path with the home directory.
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return home + path[1:]
+		}
+	}
+	return path
+}
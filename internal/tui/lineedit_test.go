@@ -0,0 +1,244 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// editor returns an editor holding s, with the cursor at the "|" in it.
+func editor(s string) LineEditor {
+	pos := strings.Index(s, "|")
+	e := NewLineEditor(strings.Replace(s, "|", "", 1))
+	e.pos = len([]rune(s[:pos]))
+	return e
+}
+
+// state shows e's text with a "|" at the cursor.
+func (e *LineEditor) state() string {
+	return string(e.text[:e.pos]) + "|" + string(e.text[e.pos:])
+}
+
+var (
+	keyTab      = tea.KeyMsg{Type: tea.KeyTab}
+	keyShiftTab = tea.KeyMsg{Type: tea.KeyShiftTab}
+	keyUp       = tea.KeyMsg{Type: tea.KeyUp}
+	keyDown     = tea.KeyMsg{Type: tea.KeyDown}
+)
+
+func runes(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+func alt(r rune) tea.KeyMsg     { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}, Alt: true} }
+func key(t tea.KeyType) tea.KeyMsg {
+	return tea.KeyMsg{Type: t}
+}
+
+func TestLineEditorKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		start string
+		keys  []tea.KeyMsg
+		want  string
+	}{
+		{"insert", "fo|o", []tea.KeyMsg{runes("x"), key(tea.KeySpace)}, "fox |o"},
+		{"paste is flattened", "a|", []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune(" b\n  c\td \n"), Paste: true}}, "ab c d|"},
+		{"backspace", "ab|c", []tea.KeyMsg{key(tea.KeyBackspace)}, "a|c"},
+		{"backspace at the start", "|abc", []tea.KeyMsg{key(tea.KeyBackspace)}, "|abc"},
+		{"delete", "a|bc", []tea.KeyMsg{key(tea.KeyCtrlD)}, "a|c"},
+		{"delete at the end", "abc|", []tea.KeyMsg{key(tea.KeyDelete)}, "abc|"},
+		{"left stops at the start", "a|b", []tea.KeyMsg{key(tea.KeyLeft), key(tea.KeyCtrlB)}, "|ab"},
+		{"right stops at the end", "a|b", []tea.KeyMsg{key(tea.KeyRight), key(tea.KeyCtrlF)}, "ab|"},
+		{"home and end", "a|b", []tea.KeyMsg{key(tea.KeyCtrlA), runes("x"), key(tea.KeyEnd)}, "xab|"},
+
+		// Words are runs of letters and digits.
+		{"word left", "cd ~/src/proj|", []tea.KeyMsg{key(tea.KeyCtrlLeft)}, "cd ~/src/|proj"},
+		{"word left twice", "cd ~/src/proj|", []tea.KeyMsg{alt('b'), alt('b')}, "cd ~/|src/proj"},
+		{"word left at the start", "|  ab", []tea.KeyMsg{key(tea.KeyCtrlLeft)}, "|  ab"},
+		{"word right", "|cd ~/src", []tea.KeyMsg{key(tea.KeyCtrlRight), alt('f')}, "cd ~/src|"},
+		{"word right at the end", "ab  |", []tea.KeyMsg{alt('f')}, "ab  |"},
+		{"ctrl+w", "cd ~/src/proj|", []tea.KeyMsg{key(tea.KeyCtrlW)}, "cd ~/src/|"},
+		{"ctrl+w over trailing spaces", "cd foo  |", []tea.KeyMsg{key(tea.KeyCtrlW)}, "cd |"},
+		{"ctrl+w mid-word", "cd fo|o", []tea.KeyMsg{key(tea.KeyCtrlW)}, "cd |o"},
+		{"alt+backspace", "cd foo|", []tea.KeyMsg{{Type: tea.KeyBackspace, Alt: true}}, "cd |"},
+		{"alt+d", "cd| ~/src/proj", []tea.KeyMsg{alt('d')}, "cd|/proj"},
+		{"ctrl+u", "cd |src", []tea.KeyMsg{key(tea.KeyCtrlU)}, "|src"},
+		{"ctrl+k", "cd |src", []tea.KeyMsg{key(tea.KeyCtrlK)}, "cd |"},
+		{"multibyte", "né|e", []tea.KeyMsg{key(tea.KeyBackspace), runes("ï")}, "nï|e"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := editor(tt.start)
+			for _, k := range tt.keys {
+				if !e.HandleKey(k) {
+					t.Fatalf("%s not handled", k)
+				}
+			}
+			if got := e.state(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLineEditorUnhandled(t *testing.T) {
+	e := NewLineEditor("x")
+	for _, k := range []tea.KeyMsg{key(tea.KeyEnter), key(tea.KeyEsc), keyUp, keyDown, keyTab} {
+		if e.HandleKey(k) {
+			t.Errorf("%s handled without history or completion", k)
+		}
+	}
+	if e.Value() != "x" {
+		t.Errorf("value = %q, want it left alone", e.Value())
+	}
+}
+
+func TestLineEditorHistory(t *testing.T) {
+	e := NewLineEditor("")
+	e.SetHistory([]string{"old", "new"})
+	e.HandleKey(runes("draft"))
+	for _, step := range []struct {
+		key  tea.KeyMsg
+		want string
+	}{
+		{keyUp, "new"},
+		{keyUp, "old"},
+		{keyUp, "old"}, // oldest entry
+		{keyDown, "new"},
+		{keyDown, "draft"}, // past the newest: the line being typed
+		{keyDown, "draft"},
+		{keyUp, "new"},
+	} {
+		if !e.HandleKey(step.key) {
+			t.Fatalf("%s not handled with history", step.key)
+		}
+		if got := e.state(); got != step.want+"|" {
+			t.Fatalf("after %s: %q, want %q", step.key, got, step.want+"|")
+		}
+	}
+
+}
+
+func TestLineEditorCompletion(t *testing.T) {
+	words := completeWords([]string{"alpine", "alpha", "beta", "été"})
+	tests := []struct {
+		name    string
+		start   string
+		keys    []tea.KeyMsg
+		want    []string // value after each key; "!" = not handled
+		matches []string // candidates left at the end
+	}{
+		{
+			name: "common prefix then cycle", start: "a",
+			keys:    []tea.KeyMsg{keyTab, keyTab, keyTab, keyTab},
+			want:    []string{"alp", "alpha", "alpine", "alpha"},
+			matches: []string{"alpha", "alpine"},
+		},
+		{
+			name: "shift+tab cycles backwards", start: "alp",
+			keys: []tea.KeyMsg{keyShiftTab, keyShiftTab, keyTab},
+			want: []string{"alpine", "alpha", "alpine"},
+		},
+		{
+			name: "single candidate completes", start: "b",
+			keys: []tea.KeyMsg{keyTab, keyTab},
+			want: []string{"beta", "!"},
+		},
+		{name: "nothing to complete", start: "z", keys: []tea.KeyMsg{keyTab}, want: []string{"!"}},
+		{name: "multibyte prefix", start: "é", keys: []tea.KeyMsg{keyTab}, want: []string{"été"}},
+		{
+			name: "typing starts over", start: "a",
+			keys: []tea.KeyMsg{keyTab, keyTab, key(tea.KeyBackspace), keyTab},
+			want: []string{"alp", "alpha", "alph", "alpha"},
+		},
+		{
+			name: "cycling from an empty value", start: "",
+			keys:    []tea.KeyMsg{keyTab, keyTab},
+			want:    []string{"alpha", "alpine"},
+			matches: []string{"alpha", "alpine", "beta", "été"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewLineEditor(tt.start)
+			e.SetCompleter(words)
+			for i, k := range tt.keys {
+				got := "!"
+				if e.HandleKey(k) {
+					got = e.Value()
+				}
+				if got != tt.want[i] {
+					t.Fatalf("after key %d (%s): %q, want %q", i+1, k, got, tt.want[i])
+				}
+			}
+			if tt.matches != nil && !slices.Equal(e.Completions(), tt.matches) {
+				t.Errorf("completions = %q, want %q", e.Completions(), tt.matches)
+			}
+		})
+	}
+}
+
+func TestCommonPrefix(t *testing.T) {
+	tests := []struct {
+		in   []string
+		want string
+	}{
+		{[]string{"abc"}, "abc"},
+		{[]string{"abc", "abd"}, "ab"},
+		{[]string{"ab", "abc", "a"}, "a"},
+		{[]string{"abc", "xyz"}, ""},
+		{[]string{"~/src/", "~/srv/"}, "~/sr"},
+		{[]string{"é1", "é2"}, "é"},
+		{[]string{"éa", "èa"}, ""}, // same first byte, different rune
+	}
+	for _, tt := range tests {
+		if got := commonPrefix(tt.in); got != tt.want {
+			t.Errorf("commonPrefix(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCompleteDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, d := range []string{"projects/api", "proto", ".config", "ünï"} {
+		if err := os.MkdirAll(filepath.Join(home, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(home, "profile"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(home, "proto"), filepath.Join(home, "link")); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(home)
+
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"~", []string{"~/link/", "~/projects/", "~/proto/", "~/ünï/"}},
+		{"~/", []string{"~/link/", "~/projects/", "~/proto/", "~/ünï/"}},
+		{"~/pro", []string{"~/projects/", "~/proto/"}}, // not the file
+		{"~/projects/", []string{"~/projects/api/"}},
+		{"~/projects/a", []string{"~/projects/api/"}},
+		{"~/.", []string{"~/.config/"}}, // hidden only when asked for
+		{"~/.c", []string{"~/.config/"}},
+		{"~/l", []string{"~/link/"}}, // symlinks to directories count
+		{"~/ü", []string{"~/ünï/"}},
+		{"~/nope/", nil},
+		{home + "/pro", []string{home + "/projects/", home + "/proto/"}},
+		{"pro", []string{"projects/", "proto/"}},
+		{"./pro", []string{"./projects/", "./proto/"}},
+		{"projects/../pro", []string{"projects/../projects/", "projects/../proto/"}},
+	}
+	for _, tt := range tests {
+		if got := completeDir(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("completeDir(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package tui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/luytbq/tswitch/internal/keys"
+	"github.com/luytbq/tswitch/internal/tmux"
+)
+
+// menuItem is one entry of the card menu. It either performs a key action
+// on the focused card or runs its own function.
+type menuItem struct {
+	label  string
+	action keys.Action                 // shown with its key; performed when run is nil
+	run    func() (tea.Model, tea.Cmd) // entries without a key of their own
+}
+
+// cardMenu returns the actions that apply to the focused card.
+func (m *Model) cardMenu() []menuItem {
+	var items []menuItem
+	add := func(label string, action keys.Action) {
+		items = append(items, menuItem{label: label, action: action})
+	}
+	addFunc := func(label string, run func() (tea.Model, tea.Cmd)) {
+		items = append(items, menuItem{label: label, run: run})
+	}
+	cut := func() {
+		if m.clipboard != nil {
+			add("Clear cut", keys.ActionCut)
+		} else {
+			add("Cut", keys.ActionCut)
+		}
+	}
+
+	switch card := m.activeGrid().GetFocused().(type) {
+	case NewSessionCard:
+		add("Create session here", keys.ActionQuickSwap)
+		addFunc("Copy path", func() (tea.Model, tea.Cmd) { return m.copyPath(card.dir) })
+
+	case SessionCard:
+		s := card.session
+		add("Switch", keys.ActionQuickSwap)
+		add("Open windows", keys.ActionConfirm)
+		add("Rename", keys.ActionRename)
+		add("Kill", keys.ActionKill)
+		add("Mark", keys.ActionStartMark)
+		add("Tag", keys.ActionTag)
+		if m.clipboard != nil && m.clipboard.kind == "window" {
+			if m.clipboard.copy {
+				add("Link "+m.clipboard.label, keys.ActionPaste)
+			} else {
+				add("Paste "+m.clipboard.label, keys.ActionPaste)
+			}
+		}
+		addFunc("New window here", func() (tea.Model, tea.Cmd) {
+			return m.newWindowIn(s.Server, s.Name, s.ActivePaneDir)
+		})
+		addFunc("Split pane right", func() (tea.Model, tea.Cmd) { return m.splitPane(s.Server, s.Name, -1, -1, true) })
+		addFunc("Split pane below", func() (tea.Model, tea.Cmd) { return m.splitPane(s.Server, s.Name, -1, -1, false) })
+		add("Broadcast command…", keys.ActionBroadcast)
+		addFunc("Copy path", func() (tea.Model, tea.Cmd) { return m.copyPath(s.ActivePaneDir) })
+
+	case WindowCard:
+		w := card.window
+		add("Switch", keys.ActionQuickSwap)
+		add("Open panes", keys.ActionConfirm)
+		add("Rename", keys.ActionRename)
+		add("Kill", keys.ActionKill)
+		add("Mark", keys.ActionStartMark)
+		cut()
+		if m.clipboard == nil {
+			add("Copy (link into another session)", keys.ActionCopy)
+		}
+		if w.Linked {
+			addFunc("Unlink from this session", func() (tea.Model, tea.Cmd) { return m.unlinkWindow(w) })
+		}
+		if m.clipboard != nil && m.clipboard.kind == "pane" {
+			add("Paste "+m.clipboard.label, keys.ActionPaste)
+		}
+		addFunc("Move to session…", func() (tea.Model, tea.Cmd) { return m.openMoveWindowMenu(w) })
+		addFunc("New window here", func() (tea.Model, tea.Cmd) {
+			return m.newWindowIn(m.currentServer, m.currentSess, w.WorkingDir)
+		})
+		addFunc("Split pane right", func() (tea.Model, tea.Cmd) {
+			return m.splitPane(m.currentServer, m.currentSess, w.Index, -1, true)
+		})
+		addFunc("Split pane below", func() (tea.Model, tea.Cmd) {
+			return m.splitPane(m.currentServer, m.currentSess, w.Index, -1, false)
+		})
+		add("Broadcast command…", keys.ActionBroadcast)
+		addFunc("Copy path", func() (tea.Model, tea.Cmd) { return m.copyPath(w.WorkingDir) })
+
+	case PaneCard:
+		p := card.pane
+		add("Switch", keys.ActionQuickSwap)
+		add("Kill", keys.ActionKill)
+		add("Mark", keys.ActionStartMark)
+		cut()
+		add("Split pane right", keys.ActionSplitRight)
+		add("Split pane below", keys.ActionSplitDown)
+		add("Break out to new window", keys.ActionBreakPane)
+		add("Toggle zoom", keys.ActionZoomPane)
+		add("Cycle layouts", keys.ActionCycleLayout)
+		addFunc("New window here", func() (tea.Model, tea.Cmd) {
+			return m.newWindowIn(m.currentServer, m.currentSess, p.WorkingDir)
+		})
+		add("Broadcast command…", keys.ActionBroadcast)
+		addFunc("Copy path", func() (tea.Model, tea.Cmd) { return m.copyPath(p.WorkingDir) })
+	}
+	return items
+}
+
+// openCardMenu opens the action menu for the focused card.
+func (m *Model) openCardMenu() {
+	items := m.cardMenu()
+	if len(items) == 0 {
+		return
+	}
+	m.openMenu(m.activeGrid().GetFocused().Title(), items)
+}
+
+// openMenu shows items in a menu dialog.
+func (m *Model) openMenu(title string, items []menuItem) {
+	scope := m.keyScope()
+	choices := make([]Choice, len(items))
+	for i, item := range items {
+		choices[i] = Choice{Label: item.label}
+		if item.run == nil {
+			choices[i].Key = keys.KeyFor(scope, item.action)
+		}
+	}
+	m.dialog = NewMenuDialog(title, choices, m.styles)
+	m.pendingAction = dialogCardMenu
+	m.menu = items
+}
+
+// runMenuChoice closes the menu and performs entry i on the focused card.
+func (m *Model) runMenuChoice(i int) (tea.Model, tea.Cmd) {
+	items := m.menu
+	m.dialog, m.menu, m.pendingAction = nil, nil, dialogNone
+	if i < 0 || i >= len(items) {
+		return m, nil
+	}
+	if items[i].run != nil {
+		return items[i].run()
+	}
+	return m.dispatch(m.keyScope(), items[i].action)
+}
+
+// ---------------------------------------------------------------------------
+// Menu-only actions
+// ---------------------------------------------------------------------------
+
+// openMoveWindowMenu lists the other sessions on the window's server as
+// destinations for w.
+func (m *Model) openMoveWindowMenu(w tmux.Window) (tea.Model, tea.Cmd) {
+	var items []menuItem
+	for _, s := range m.sessions {
+		if s.Server != m.currentServer || s.Name == m.currentSess {
+			continue
+		}
+		dst := s.Name
+		items = append(items, menuItem{label: dst, run: func() (tea.Model, tea.Cmd) {
+			if err := m.svc().MoveWindow(m.currentSess, w.Index, dst); err != nil {
+				m.setStatusError(err.Error())
+				return m, nil
+			}
+			m.setStatus(fmt.Sprintf("Moved %q to %s", w.Name, dst))
+			return m.refreshWindows()
+		}})
+	}
+	if len(items) == 0 {
+		m.setStatusError("no other session to move to")
+		return m, nil
+	}
+	m.openMenu(fmt.Sprintf("Move %q to", w.Name), items)
+	return m, nil
+}
+
+// unlinkWindow removes the linked window w from the current session; the
+// other sessions keep it.
+func (m *Model) unlinkWindow(w tmux.Window) (tea.Model, tea.Cmd) {
+	if err := m.svc().UnlinkWindow(m.currentSess, w.Index); err != nil {
+		m.setStatusError(err.Error())
+		return m, nil
+	}
+	m.setStatus(fmt.Sprintf("Unlinked %q from %s", w.Name, m.currentSess))
+	return m.refreshWindows()
+}
+
+// newWindowIn creates a window in session starting in dir.
+func (m *Model) newWindowIn(server, session, dir string) (tea.Model, tea.Cmd) {
+	if _, err := m.serviceFor(server).CreateWindow(session, tmux.WindowSpec{Dir: dir}); err != nil {
+		m.setStatusError(err.Error())
+		return m, nil
+	}
+	m.setStatus("New window in " + session)
+	return m.refreshCurrent()
+}
+
+// splitPane splits a pane of session; negative window and pane indexes pick
+// the active ones.
+func (m *Model) splitPane(server, session string, window, pane int, horizontal bool) (tea.Model, tea.Cmd) {
+	if err := m.serviceFor(server).SplitWindow(session, window, pane, horizontal); err != nil {
+		m.setStatusError(err.Error())
+		return m, nil
+	}
+	m.setStatus("Split pane in " + session)
+	return m.refreshCurrent()
+}
+
+// copyPath puts path into the tmux paste buffer (and the system clipboard
+// when running inside tmux).
+func (m *Model) copyPath(path string) (tea.Model, tea.Cmd) {
+	if path == "" {
+		m.setStatusError("no path to copy")
+		return m, nil
+	}
+	if err := m.tmux.SetBuffer(path); err != nil {
+		m.setStatusError(err.Error())
+		return m, nil
+	}
+	m.setStatus("Copied: " + path)
+	return m, nil
+}
+
+// refreshCurrent reloads the items of the current grid, keeping the focus
+// on the same card.
+func (m *Model) refreshCurrent() (tea.Model, tea.Cmd) {
+	focused := m.activeGrid().GetFocused()
+	switch m.currentMode {
+	case ModeWindowGrid:
+		_ = m.snapshot()
+		_ = m.loadWindows(m.currentServer, m.currentSess)
+	case ModePaneGrid:
+		// Pane changes also show on the window card: pane count, layout.
+		_ = m.snapshot()
+		if w := m.windowGrid.GetFocused(); w != nil {
+			_ = m.loadWindows(m.currentServer, m.currentSess)
+			m.windowGrid.FocusFirstWhere(func(item GridItem) bool { return sameCard(item, w) })
+		}
+		_ = m.loadPanes(m.currentSess, m.currentWin)
+	default:
+		_ = m.loadSessions()
+	}
+	m.applyFilter()
+	m.activeGrid().FocusFirstWhere(func(item GridItem) bool { return sameCard(item, focused) })
+	return m, m.syncPreview()
+}
@@ -1,6 +1,12 @@
 package tui
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"sync"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -10,10 +16,12 @@ import (
 	"github.com/luytbq/tswitch/internal/tmux"
 )
 
-// clipboard holds a cut window or pane awaiting paste.
+// clipboard holds a cut window or pane, or a copied window, awaiting paste.
 type clipboard struct {
-	kind    string // "window" or "pane"
-	srcSess string
+	kind      string // "window" or "pane"
+	copy      bool   // window kind only: paste links the window instead of moving it
+	srcServer string
+	srcSess   string
 	srcWin  int    // window index (both kinds)
 	srcPane int    // pane kind only
 	label   string // e.g. `window "editor" from work`
@@ -31,7 +39,8 @@ const (
 // Model is the top-level Bubbletea model.
 type Model struct {
 	// Dependencies (injected via constructor).
-	tmux      tmux.Service
+	tmux      tmux.Service  // current server; same as servers[0].Service
+	servers   []tmux.Server // every server contributing sessions
 	config    *config.Config
 	appConfig *config.AppConfig
 	styles    Styles
@@ -45,48 +54,92 @@ type Model struct {
 	// State.
 	currentMode      Mode
 	sessions         []tmux.Session
+	serverSessions   map[string][]tmux.Session // server -> its sessions, last read
 	windows          []tmux.Window
 	panes            []tmux.Pane
+	currentServer    string // server of currentSess ("" = current server)
 	currentSess      string // session name when in window view
 	currentWin       int    // window index when in pane view
 	windowsBySession map[string][]string // session -> window names (for search)
 	helpShown     bool
 	markingMode   bool   // waiting for a mark-key press
 	markingTarget string // "session" or "window"
+	markJump      bool   // jump_mark pressed: the next key names a mark
+	pendingKeys   []string // keys of an unfinished key sequence
+	pendingSeq    int      // bumped per pending key so stale timeouts are ignored
 	filterMode    bool   // search input is active
 	filterQuery   string // current fuzzy-search term
 	dialog        *Dialog
 	pendingAction dialogAction
+	menu          []menuItem // entries of the open card menu
 	clipboard     *clipboard
+	broadcast     *broadcast // command and targets awaiting confirmation
+	cyclingLayout bool // the preview shows layoutPresets[layoutPick] until applied
+	layoutPick    int
+	attachArgv    []string // picked outside tmux: exec'd by main once the TUI exits
 
 	// Viewport.
 	width  int
 	height int
 
+	// Mouse.
+	lastClick     int       // item index of the last left click
+	lastClickTime time.Time // for double-click detection
+
 	// Feedback (status bar message with auto-expiry).
 	statusMsg     string
 	statusMsgTime time.Time
 	isStatusError bool
 }
 
-// NewModel creates a Model wired to a real tmux client and default app config.
+// NewModel creates a Model wired to the current tmux server plus every other
+// server discovered on this machine.
 func NewModel(appCfg *config.AppConfig) (*Model, error) {
-	return NewModelWith(tmux.NewClient(), appCfg)
+	return NewModelWithServers(Servers(appCfg), appCfg)
+}
+
+// Servers returns the current tmux server followed by every other local
+// server and the configured remote hosts.
+func Servers(appCfg *config.AppConfig) []tmux.Server {
+	if appCfg == nil {
+		appCfg = config.DefaultAppConfig()
+	}
+	servers := tmux.DiscoverServers(tmux.NewClient(), appCfg.Sockets)
+	servers[0].Name = "" // sessions on the current server carry no badge
+	for _, h := range appCfg.RemoteHosts {
+		name := h.Name
+		if name == "" {
+			name = h.Host
+		}
+		servers = append(servers, tmux.Server{Name: name, Service: tmux.NewRemoteClient(h.Host), Remote: true})
+	}
+	return servers
 }
 
 // NewModelWith creates a Model using the given tmux.Service (useful for tests).
 func NewModelWith(svc tmux.Service, appCfg *config.AppConfig) (*Model, error) {
+	return NewModelWithServers([]tmux.Server{{Service: svc}}, appCfg)
+}
+
+// NewModelWithServers creates a Model browsing the given servers. The first
+// server must be the one tswitch runs in; its sessions are switched to
+// directly, sessions on the others are attached from a new window.
+func NewModelWithServers(servers []tmux.Server, appCfg *config.AppConfig) (*Model, error) {
+	// Running on defaults would lose the marks, tags and order on the next
+	// save, so a state that can't be read stops tswitch.
 	cfg, err := config.LoadState()
 	if err != nil {
-		cfg = config.Default()
+		return nil, err
 	}
 	if appCfg == nil {
 		appCfg = config.DefaultAppConfig()
 	}
 
 	styles := NewStyles()
+	servers[0].Name = "" // sessions on the current server carry no badge
 	m := &Model{
-		tmux:        svc,
+		tmux:        servers[0].Service,
+		servers:     servers,
 		config:      cfg,
 		appConfig:   appCfg,
 		styles:      styles,
@@ -116,18 +169,69 @@ func NewModelWith(svc tmux.Service, appCfg *config.AppConfig) (*Model, error) {
 	return m, nil
 }
 
+// SetFilter presets the fuzzy filter, e.g. for `tswitch switch <query>`.
+func (m *Model) SetFilter(query string) {
+	m.filterQuery = query
+	m.applyFilter()
+}
+
+// SwitchToSingleMatch switches to the only session matching the filter, or to
+// the session named exactly like it, and reports whether it did. The ssh
+// hosts are read first so their sessions can match too. Outside tmux the
+// attach command is left in AttachCommand.
+func (m *Model) SwitchToSingleMatch() (bool, error) {
+	m.loadRemoteNow()
+	var match *SessionCard
+	count := 0
+	for _, item := range m.sessionGrid.Items() {
+		card, ok := item.(SessionCard)
+		if !ok {
+			continue
+		}
+		if card.session.Name == m.filterQuery {
+			match, count = &card, 1
+			break
+		}
+		match = &card
+		count++
+	}
+	if count != 1 {
+		return false, nil
+	}
+	return true, m.switchTo(match.session.Server, match.session.Name, -1, -1)
+}
+
+// AttachCommand returns the tmux client command line chosen when running
+// outside tmux, or nil. The caller should exec it after the program exits.
+func (m *Model) AttachCommand() []string {
+	return m.attachArgv
+}
+
 // captureResultMsg carries the output of an async pane capture.
 type captureResultMsg struct{ content string }
 
+// keyTimeoutMsg fires when a pending key sequence has waited too long.
+type keyTimeoutMsg struct{ seq int }
+
 // fzfResultMsg carries the result of the fzf directory browser.
 type fzfResultMsg struct {
 	path string // selected directory path (empty if cancelled)
 	err  error
 }
 
+// stateTickMsg asks to look for state.yaml changes from other instances.
+type stateTickMsg struct{}
+
+// stateCheckInterval is how often state.yaml is checked for changes.
+const stateCheckInterval = 2 * time.Second
+
+func watchState() tea.Cmd {
+	return tea.Tick(stateCheckInterval, func(time.Time) tea.Msg { return stateTickMsg{} })
+}
+
 // Init implements tea.Model.
 func (m *Model) Init() tea.Cmd {
-	return m.syncPreview()
+	return tea.Batch(m.syncPreview(), watchState(), m.loadRemote())
 }
 
 // Update implements tea.Model. It dispatches to focused handlers.
@@ -137,24 +241,41 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.handleKey(msg)
 	case tea.WindowSizeMsg:
 		m.resize(msg.Width, msg.Height)
+		if !m.previewPanel.IsCapture() || m.cyclingLayout {
+			return m, m.syncPreview() // layout diagrams are drawn to the panel size
+		}
 	case captureResultMsg:
-		m.previewPanel.SetCaptureContent(msg.content)
+		if !m.cyclingLayout {
+			m.previewPanel.SetCaptureContent(msg.content)
+		}
 	case fzfResultMsg:
 		return m.handleFzfResult(msg)
+	case keyTimeoutMsg:
+		return m.handleKeyTimeout(msg)
+	case stateTickMsg:
+		m.reloadState()
+		return m, watchState()
+	case remoteSessionsMsg:
+		m.handleRemoteSessions(msg)
+		return m, nil
+	case tea.MouseMsg:
+		return m.handleMouse(msg)
 	}
 	return m, nil
 }
 
 // View implements tea.Model.
 func (m *Model) View() string {
+	return m.renderKeyHints(m.renderView())
+}
+
+func (m *Model) renderView() string {
 	if m.dialog != nil {
+		m.dialog.Hint = m.dialogHint()
 		return lipgloss.Place(m.width, m.height,
 			lipgloss.Center, lipgloss.Center,
 			m.dialog.Render(m.width, m.height))
 	}
-	if m.helpShown {
-		return m.renderHelp()
-	}
 	switch m.currentMode {
 	case ModeSessionGrid:
 		return m.renderSessionView()
@@ -173,14 +294,21 @@ func (m *Model) View() string {
 func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	keyStr := msg.String()
 
-	// Dialog intercepts all keys.
-	if m.dialog != nil {
-		return m.handleDialogKey(msg)
+	// The key hint popup closes on the next key, which then acts as usual
+	// unless it is esc or the help key itself.
+	if m.helpShown {
+		m.helpShown = false
+		if keyStr == "esc" || keys.Resolve(m.keyScope(), keyStr) == keys.ActionToggleHelp {
+			return m, nil
+		}
 	}
 
-	// Filter mode intercepts all keys.
-	if m.filterMode {
-		return m.handleFilterKey(msg)
+	// Printable keys edit the filter or dialog input, unless they continue
+	// a pending sequence.
+	if len(m.pendingKeys) == 0 {
+		if model, cmd, ok := m.handleTextKey(msg); ok {
+			return model, cmd
+		}
 	}
 
 	// Marking mode intercepts all keys.
@@ -188,7 +316,85 @@ func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleMarkAssignment(keyStr)
 	}
 
-	action := keys.Resolve(keyStr)
+	// After the mark prefix, any key names a mark.
+	if m.markJump {
+		m.markJump = false
+		if keyStr == "esc" {
+			return m, nil
+		}
+		return m.handleJumpToMark(keyStr)
+	}
+
+	scope := m.keyScope()
+	seq := append(append([]string(nil), m.pendingKeys...), keyStr)
+	action, pending := keys.Lookup(scope, seq)
+	if pending {
+		m.pendingKeys = seq
+		m.pendingSeq++
+		id := m.pendingSeq
+		return m, tea.Tick(keys.Timeout(), func(time.Time) tea.Msg { return keyTimeoutMsg{seq: id} })
+	}
+	m.pendingKeys = nil
+
+	if action == keys.ActionNone {
+		switch {
+		case len(seq) > 1:
+			m.setStatusError(keys.FormatSequence(seq) + " is not bound")
+			return m, nil
+		case scope != keys.ScopeFilter && scope != keys.ScopeDialog && m.config.HasMark(keyStr):
+			// Unbound keys jump to marks on their own too, as they did
+			// before the mark prefix.
+			return m.handleJumpToMark(keyStr)
+		}
+	}
+	return m.dispatch(scope, action)
+}
+
+// handleKeyTimeout ends a pending sequence that got no further key, firing
+// the action bound to the keys typed so far, if any.
+func (m *Model) handleKeyTimeout(msg keyTimeoutMsg) (tea.Model, tea.Cmd) {
+	if msg.seq != m.pendingSeq || len(m.pendingKeys) == 0 {
+		return m, nil
+	}
+	scope := m.keyScope()
+	action, _ := keys.Lookup(scope, m.pendingKeys)
+	m.pendingKeys = nil
+	return m.dispatch(scope, action)
+}
+
+// keyScope returns the keymap that applies in the current mode.
+func (m *Model) keyScope() keys.Scope {
+	switch {
+	case m.dialog != nil:
+		return keys.ScopeDialog
+	case m.filterMode:
+		return keys.ScopeFilter
+	}
+	switch m.currentMode {
+	case ModeWindowGrid:
+		return keys.ScopeWindows
+	case ModePaneGrid:
+		return keys.ScopePanes
+	}
+	return keys.ScopeSessions
+}
+
+// dispatch performs action in scope.
+func (m *Model) dispatch(scope keys.Scope, action keys.Action) (tea.Model, tea.Cmd) {
+	switch scope {
+	case keys.ScopeDialog:
+		return m.handleDialogAction(action)
+	case keys.ScopeFilter:
+		if model, cmd, ok := m.handleFilterAction(action); ok {
+			return model, cmd
+		}
+	}
+
+	if m.cyclingLayout {
+		if model, cmd, ok := m.handleLayoutCycle(action); ok {
+			return model, cmd
+		}
+	}
 
 	switch action {
 	case keys.ActionQuit:
@@ -198,7 +404,7 @@ func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleBack()
 
 	case keys.ActionToggleHelp:
-		m.helpShown = !m.helpShown
+		m.helpShown = true
 
 	case keys.ActionTogglePreview:
 		m.previewPanel.ToggleMode()
@@ -207,12 +413,20 @@ func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		} else {
 			m.config.Settings.PreviewMode = config.PreviewModeMetadata
 		}
-		_ = config.SaveState(m.config)
+		if err := config.SaveState(m.config); err != nil {
+			m.setStatusError(fmt.Sprintf("Failed to save: %v", err))
+		}
 		return m, m.syncPreview()
 
+	case keys.ActionToggleGroup:
+		return m.handleToggleGroup()
+
 	case keys.ActionStartMark:
 		m.enterMarkingMode()
 
+	case keys.ActionJumpMark:
+		m.markJump = true
+
 	case keys.ActionFilter:
 		return m, m.enterFilterMode()
 
@@ -231,9 +445,40 @@ func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case keys.ActionCut:
 		return m.handleCut()
 
+	case keys.ActionCopy:
+		return m.handleCopy()
+
+	case keys.ActionBroadcast:
+		return m.handleBroadcast()
+
 	case keys.ActionPaste:
 		return m.handlePaste()
 
+	case keys.ActionTag:
+		return m.handleTag()
+
+	case keys.ActionMenu:
+		m.openCardMenu()
+
+	case keys.ActionSplitRight:
+		return m.handleSplitPane(true)
+	case keys.ActionSplitDown:
+		return m.handleSplitPane(false)
+	case keys.ActionBreakPane:
+		return m.handleBreakPane()
+	case keys.ActionZoomPane:
+		return m.handleZoomPane()
+	case keys.ActionResizeLeft:
+		return m.handleResizePane(-1, 0)
+	case keys.ActionResizeDown:
+		return m.handleResizePane(0, 1)
+	case keys.ActionResizeUp:
+		return m.handleResizePane(0, -1)
+	case keys.ActionResizeRight:
+		return m.handleResizePane(1, 0)
+	case keys.ActionCycleLayout:
+		return m.handleCycleLayout()
+
 	case keys.ActionMoveUp:
 		return m, m.moveFocus(0, -1)
 	case keys.ActionMoveDown:
@@ -242,6 +487,13 @@ func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, m.moveFocus(-1, 0)
 	case keys.ActionMoveRight:
 		return m, m.moveFocus(1, 0)
+	case keys.ActionFocusFirst:
+		m.activeGrid().SetFocus(0)
+		return m, m.syncPreview()
+	case keys.ActionFocusLast:
+		g := m.activeGrid()
+		g.SetFocus(len(g.Items()) - 1)
+		return m, m.syncPreview()
 
 	case keys.ActionReorderUp:
 		return m.handleReorder(0, -1)
@@ -260,12 +512,6 @@ func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case keys.ActionQuickSwap:
 		return m.handleQuickSwap()
-
-	case keys.ActionNone:
-		// Might be a mark-jump key.
-		if m.config.HasMark(keyStr) {
-			return m.handleJumpToMark(keyStr)
-		}
 	}
 
 	return m, nil
@@ -275,52 +521,241 @@ func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 // Data loading
 // ---------------------------------------------------------------------------
 
-func (m *Model) loadSessions() error {
-	sessions, err := m.tmux.ListSessions()
-	if err != nil {
-		return err
+// snapshot reads every session, window and pane of every local server into
+// m.sessions, along with the sessions last read from the ssh hosts (see
+// loadRemote). The grids are left alone; loadSessions, loadWindows and
+// loadPanes fill them from the snapshot, so drilling in needs no tmux
+// round-trip. Call it after changing tmux, before reloading a grid.
+func (m *Model) snapshot() error {
+	if m.serverSessions == nil {
+		m.serverSessions = make(map[string][]tmux.Session)
+	}
+	for i, srv := range m.servers {
+		if srv.Remote {
+			continue
+		}
+		list, err := srv.Service.Snapshot()
+		if errors.Is(err, tmux.ErrNoServer) && i == 0 && !m.tmux.IsInTmux() {
+			// Launched from a plain shell with no server running: start one.
+			// Without sessions it may exit again right away, which just
+			// leaves "new session here" as the only card.
+			if m.tmux.StartServer() == nil {
+				list, err = srv.Service.Snapshot()
+			}
+			if err != nil {
+				list, err = nil, nil
+			}
+		}
+		if err != nil {
+			if i == 0 {
+				return err
+			}
+			list = nil // stale socket or server shut down: skip it
+		}
+		for j := range list {
+			list[j].Server = srv.ID()
+		}
+		m.serverSessions[srv.ID()] = list
+	}
+	m.combineSessions()
+	return nil
+}
+
+// combineSessions sets m.sessions to the sessions of every server.
+func (m *Model) combineSessions() {
+	var sessions []tmux.Session
+	m.windowsBySession = make(map[string][]string)
+	for _, srv := range m.servers {
+		for _, sess := range m.serverSessions[srv.ID()] {
+			// Window names let session filtering match against them.
+			key := config.SessionKey(sess.Server, sess.Name)
+			for _, w := range sess.Windows {
+				m.windowsBySession[key] = append(m.windowsBySession[key], w.Name)
+			}
+			sessions = append(sessions, sess)
+		}
 	}
-	sessions = m.applySavedSessionOrder(sessions)
-	m.sessions = sessions
 
-	// Pre-fetch all window names so session filtering can match against them.
-	if wbs, err := m.tmux.ListAllWindowNames(); err == nil {
-		m.windowsBySession = wbs
+	if len(m.servers) > 1 {
+		sort.SliceStable(sessions, func(i, j int) bool {
+			return sessions[i].LastActive.After(sessions[j].LastActive)
+		})
+	}
+	m.sessions = m.applySavedSessionOrder(sessions)
+}
+
+// remoteSessionsMsg carries the sessions read from an ssh host.
+type remoteSessionsMsg struct {
+	server   string
+	sessions []tmux.Session
+	err      error
+}
+
+// loadRemote reads the sessions of every ssh host in the background. A host
+// that doesn't answer holds its command for the ssh connect timeout, which
+// the popup shouldn't wait for; its sessions appear when they arrive.
+func (m *Model) loadRemote() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, srv := range m.servers {
+		if srv.Remote {
+			cmds = append(cmds, func() tea.Msg { return readRemote(srv) })
+		}
 	}
+	return tea.Batch(cmds...)
+}
 
-	// Pre-fetch total pane counts per session.
-	if paneCounts, err := m.tmux.ListAllPaneCounts(); err == nil {
-		for i := range sessions {
-			sessions[i].PaneCount = paneCounts[sessions[i].Name]
+// loadRemoteNow reads the sessions of every ssh host like loadRemote but
+// waits for them, for resolving a session before the TUI runs. The hosts are
+// asked in parallel, so a dead one costs one connect timeout.
+func (m *Model) loadRemoteNow() {
+	msgs := make([]*remoteSessionsMsg, len(m.servers))
+	var wg sync.WaitGroup
+	for i, srv := range m.servers {
+		if srv.Remote {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				msg := readRemote(srv)
+				msgs[i] = &msg
+			}()
+		}
+	}
+	wg.Wait()
+	for _, msg := range msgs {
+		if msg != nil {
+			m.handleRemoteSessions(*msg)
 		}
 	}
+}
 
-	m.sessionGrid.SetItems(toGridItems(sessions, func(s tmux.Session) GridItem { return SessionCard{s} }))
+// readRemote reads the sessions of one ssh host.
+func readRemote(srv tmux.Server) remoteSessionsMsg {
+	list, err := srv.Service.Snapshot()
+	return remoteSessionsMsg{server: srv.ID(), sessions: list, err: err}
+}
+
+// handleRemoteSessions merges the sessions of an ssh host into the grid,
+// keeping the focus on the same card. An unreachable host's are dropped.
+func (m *Model) handleRemoteSessions(msg remoteSessionsMsg) {
+	if msg.err != nil {
+		msg.sessions = nil
+	}
+	for i := range msg.sessions {
+		msg.sessions[i].Server = msg.server
+	}
+	if m.serverSessions == nil {
+		m.serverSessions = make(map[string][]tmux.Session)
+	}
+	m.serverSessions[msg.server] = msg.sessions
+	m.combineSessions()
+	if m.currentMode != ModeSessionGrid {
+		return
+	}
+	focused := m.sessionGrid.GetFocused()
+	m.applyFilter()
+	m.sessionGrid.FocusFirstWhere(func(item GridItem) bool { return sameCard(item, focused) })
+}
+
+// sameCard reports whether a and b show the same thing; sessions are told
+// apart by server as well as by name.
+func sameCard(a, b GridItem) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if sa, ok := a.(SessionCard); ok {
+		sb, ok := b.(SessionCard)
+		return ok && sa.session.Server == sb.session.Server && sa.session.Name == sb.session.Name
+	}
+	return a.Title() == b.Title()
+}
+
+// findSession returns the snapshot of a session.
+func (m *Model) findSession(server, name string) (tmux.Session, bool) {
+	for _, s := range m.sessions {
+		if s.Server == server && s.Name == name {
+			return s, true
+		}
+	}
+	return tmux.Session{}, false
+}
+
+func (m *Model) loadSessions() error {
+	if err := m.snapshot(); err != nil {
+		return err
+	}
+	m.setSessionItems(m.sessions)
 	m.sessionGrid.FocusFirstWhere(func(item GridItem) bool {
 		sc, ok := item.(SessionCard)
-		return ok && sc.session.Attached
+		return ok && sc.session.Attached && sc.session.Server == ""
 	})
 	return nil
 }
 
-func (m *Model) loadWindows(sessionName string) error {
-	windows, err := m.tmux.ListWindows(sessionName)
-	if err != nil {
-		return err
+// setSessionItems fills the session grid. Remote sessions always get a
+// section per host after the local ones; in the server-grouped view every
+// local server gets its own section too. Sessions are regrouped stably, so
+// they keep their order within each section.
+func (m *Model) setSessionItems(sessions []tmux.Session) {
+	byServer := m.config.Settings.GroupByServer && len(m.servers) > 1
+	rank := make(map[string]int, len(m.servers))
+	section := make(map[string]string, len(m.servers))
+	grouped := byServer
+	for i, srv := range m.servers {
+		id := srv.ID()
+		switch {
+		case srv.Remote:
+			rank[id] = i
+			section[id] = srv.Name + " (ssh)"
+			grouped = true
+		case byServer:
+			rank[id] = i
+			section[id] = srv.Name
+			if id == "" {
+				section[id] = "current server"
+			}
+		default:
+			section[id] = "local"
+		}
 	}
-	windows = m.applySavedWindowOrder(sessionName, windows)
-	m.windows = windows
+
+	if grouped {
+		sessions = append([]tmux.Session(nil), sessions...)
+		sort.SliceStable(sessions, func(i, j int) bool {
+			return rank[sessions[i].Server] < rank[sessions[j].Server]
+		})
+	}
+	items := toGridItems(sessions, func(s tmux.Session) GridItem {
+		return SessionCard{session: s, server: m.serverLabel(s.Server), section: section[s.Server]}
+	})
+	if !m.tmux.IsInTmux() {
+		dir, _ := os.Getwd()
+		items = append([]GridItem{NewSessionCard{dir: dir}}, items...)
+	}
+	m.sessionGrid.SetItems(items)
+	m.sessionGrid.SetGrouped(grouped)
+}
+
+func (m *Model) loadWindows(server, sessionName string) error {
+	sess, ok := m.findSession(server, sessionName)
+	if !ok {
+		return fmt.Errorf("session %s no longer exists", sessionName)
+	}
+	m.currentServer = server
 	m.currentSess = sessionName
+	windows := m.applySavedWindowOrder(slices.Clone(sess.Windows))
+	m.windows = windows
 
 	m.windowGrid.SetItems(toGridItems(windows, func(w tmux.Window) GridItem { return WindowCard{w} }))
 	return nil
 }
 
 func (m *Model) loadPanes(sessionName string, windowIndex int) error {
-	panes, err := m.tmux.ListPanes(sessionName, windowIndex)
-	if err != nil {
-		return err
+	sess, _ := m.findSession(m.currentServer, sessionName)
+	i := slices.IndexFunc(sess.Windows, func(w tmux.Window) bool { return w.Index == windowIndex })
+	if i < 0 {
+		return fmt.Errorf("window %s:%d no longer exists", sessionName, windowIndex)
 	}
+	panes := sess.Windows[i].Panes
 	m.panes = panes
 	m.currentWin = windowIndex
 
@@ -342,8 +777,7 @@ func toGridItems[T any](items []T, wrap func(T) GridItem) []GridItem {
 func (m *Model) applyFilter() {
 	switch m.currentMode {
 	case ModeSessionGrid:
-		filtered := FilterSessions(m.sessions, m.filterQuery, m.windowsBySession)
-		m.sessionGrid.SetItems(toGridItems(filtered, func(s tmux.Session) GridItem { return SessionCard{s} }))
+		m.setSessionItems(FilterSessions(m.sessions, m.filterQuery, m.windowsBySession))
 	case ModeWindowGrid:
 		filtered := FilterWindows(m.windows, m.filterQuery)
 		m.windowGrid.SetItems(toGridItems(filtered, func(w tmux.Window) GridItem { return WindowCard{w} }))
@@ -354,39 +788,55 @@ func (m *Model) applyFilter() {
 }
 
 // applySavedSessionOrder reorders sessions according to the saved order.
-// Sessions not in the saved order are appended at the end.
 func (m *Model) applySavedSessionOrder(sessions []tmux.Session) []tmux.Session {
-	if len(m.config.SessionOrder) == 0 {
+	return ApplySessionOrder(sessions, m.config.SessionOrder)
+}
+
+// applySavedWindowOrder reorders the windows of the browsed session
+// according to the saved order.
+func (m *Model) applySavedWindowOrder(windows []tmux.Window) []tmux.Window {
+	return ApplyWindowOrder(windows, m.config.WindowOrder[m.sessionKey()])
+}
+
+// sessionKey returns the state key of the browsed session.
+func (m *Model) sessionKey() string {
+	return config.SessionKey(m.currentServer, m.currentSess)
+}
+
+// ApplySessionOrder reorders sessions according to a saved order of session
+// keys (see config.SessionKey). Sessions not in the saved order are appended
+// at the end.
+func ApplySessionOrder(sessions []tmux.Session, order []string) []tmux.Session {
+	if len(order) == 0 {
 		return sessions
 	}
 
-	byName := make(map[string]tmux.Session, len(sessions))
+	byKey := make(map[string]tmux.Session, len(sessions))
 	for _, s := range sessions {
-		byName[s.Name] = s
+		byKey[config.SessionKey(s.Server, s.Name)] = s
 	}
 
 	result := make([]tmux.Session, 0, len(sessions))
 	seen := make(map[string]bool)
 
-	for _, name := range m.config.SessionOrder {
-		if s, ok := byName[name]; ok {
+	for _, key := range order {
+		if s, ok := byKey[key]; ok {
 			result = append(result, s)
-			seen[name] = true
+			seen[key] = true
 		}
 	}
 	for _, s := range sessions {
-		if !seen[s.Name] {
+		if !seen[config.SessionKey(s.Server, s.Name)] {
 			result = append(result, s)
 		}
 	}
 	return result
 }
 
-// applySavedWindowOrder reorders windows according to the saved order.
+// ApplyWindowOrder reorders windows according to a saved order of indices.
 // Windows not in the saved order are appended at the end.
-func (m *Model) applySavedWindowOrder(sessionName string, windows []tmux.Window) []tmux.Window {
-	order, ok := m.config.WindowOrder[sessionName]
-	if !ok || len(order) == 0 {
+func ApplyWindowOrder(windows []tmux.Window, order []int) []tmux.Window {
+	if len(order) == 0 {
 		return windows
 	}
 
@@ -417,17 +867,33 @@ func (m *Model) applySavedWindowOrder(sessionName string, windows []tmux.Window)
 func (m *Model) refreshSessions() (tea.Model, tea.Cmd) {
 	_ = m.loadSessions()
 	m.applyFilter()
-	return m, m.syncPreview()
+	return m, tea.Batch(m.syncPreview(), m.loadRemote())
 }
 
 // refreshWindows reloads window data for the current session, re-applies the
 // current filter, and syncs the preview.
 func (m *Model) refreshWindows() (tea.Model, tea.Cmd) {
-	_ = m.loadWindows(m.currentSess)
+	_ = m.snapshot()
+	_ = m.loadWindows(m.currentServer, m.currentSess)
 	m.applyFilter()
 	return m, m.syncPreview()
 }
 
+// reloadState picks up marks, tags and orders another tswitch saved, and
+// reorders the grid, keeping the focus on the same card.
+func (m *Model) reloadState() {
+	if changed, err := m.config.Reload(); err != nil || !changed {
+		return
+	}
+	focused := m.activeGrid().GetFocused()
+	m.sessions = m.applySavedSessionOrder(m.sessions)
+	if m.currentSess != "" {
+		m.windows = m.applySavedWindowOrder(m.windows)
+	}
+	m.applyFilter()
+	m.activeGrid().FocusFirstWhere(func(item GridItem) bool { return sameCard(item, focused) })
+}
+
 // resetFilter clears filter state. The grid already contains all items
 // (no filter was applied when the mode was entered), so no SetItems call needed.
 func (m *Model) resetFilter() {
@@ -508,6 +974,12 @@ func (m *Model) applyLayout() {
 // Helpers
 // ---------------------------------------------------------------------------
 
+// Warn shows msg as an error in the status bar, e.g. a config problem found
+// at startup.
+func (m *Model) Warn(msg string) {
+	m.setStatusError(msg)
+}
+
 func (m *Model) setStatus(msg string) {
 	m.statusMsg = msg
 	m.statusMsgTime = time.Now()
@@ -534,6 +1006,37 @@ func (m *Model) statusMessage() string {
 	return m.statusMsg
 }
 
+// serviceFor returns the client for the named server, falling back to the
+// current server for unknown names.
+func (m *Model) serviceFor(server string) tmux.Service {
+	for _, srv := range m.servers {
+		if srv.ID() == server {
+			return srv.Service
+		}
+	}
+	return m.tmux
+}
+
+// serverLabel returns the display name of server, an ID as in
+// tmux.Session.Server.
+func (m *Model) serverLabel(server string) string {
+	return serverLabel(m.servers, server)
+}
+
+func serverLabel(servers []tmux.Server, server string) string {
+	for _, srv := range servers {
+		if srv.ID() == server {
+			return srv.Name
+		}
+	}
+	return server
+}
+
+// svc returns the client for the server of the session being browsed.
+func (m *Model) svc() tmux.Service {
+	return m.serviceFor(m.currentServer)
+}
+
 func (m *Model) activeGrid() *Grid {
 	switch m.currentMode {
 	case ModeWindowGrid:
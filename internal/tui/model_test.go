@@ -0,0 +1,476 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/luytbq/tswitch/internal/config"
+	"github.com/luytbq/tswitch/internal/tmux"
+	"github.com/luytbq/tswitch/internal/tmux/tmuxtest"
+)
+
+// fixture returns a server where the client is in work, whose editor window
+// has two panes. Pane ids: play %0, editor %1 and %3, logs %2.
+//
+//	play 0:bash[%0]
+//	work 0:editor[%1,%3] 1:logs[%2]
+func fixture() *tmuxtest.Fake {
+	return tmuxtest.New().
+		AddSession("play").
+		AddSession("work", "editor", "logs").
+		AddPanes("work", 0, 1).
+		Attach("work")
+}
+
+func newTestModel(t *testing.T, f *tmuxtest.Fake) *Model {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir()) // state.yaml
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("TSWITCH_STATE", "")
+	m, err := NewModelWith(f, config.DefaultAppConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.resize(120, 40)
+	return m
+}
+
+// press sends space-separated keys to m and reports whether they quit.
+func press(m *Model, keys string) (quit bool) {
+	for _, k := range strings.Fields(keys) {
+		if k == "menu" {
+			// The card menu has no default key; right-clicking the focused
+			// card opens it.
+			m.openCardMenu()
+			continue
+		}
+		if update(m, keyMsg(k)) {
+			quit = true
+		}
+	}
+	return quit
+}
+
+// update sends msg to m, running the commands it returns, and reports
+// whether one of them quit.
+func update(m *Model, msg tea.Msg) (quit bool) {
+	var run func(cmd tea.Cmd)
+	run = func(cmd tea.Cmd) {
+		if cmd == nil {
+			return
+		}
+		switch msg := cmd().(type) {
+		case tea.QuitMsg:
+			quit = true
+		case tea.BatchMsg:
+			for _, c := range msg {
+				run(c)
+			}
+		case captureResultMsg, remoteSessionsMsg:
+			_, cmd := m.Update(msg)
+			run(cmd)
+		}
+	}
+	_, cmd := m.Update(msg)
+	run(cmd)
+	return quit
+}
+
+func keyMsg(k string) tea.KeyMsg {
+	switch k {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	case "ctrl+u":
+		return tea.KeyMsg{Type: tea.KeyCtrlU}
+	case "up":
+		return tea.KeyMsg{Type: tea.KeyUp}
+	case "end":
+		return tea.KeyMsg{Type: tea.KeyEnd}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
+
+func titles(g *Grid) []string {
+	var out []string
+	for _, item := range g.Items() {
+		out = append(out, item.Title())
+	}
+	return out
+}
+
+func TestKeyFlow(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  func(f *tmuxtest.Fake) // applied to fixture()
+		keys   string
+		quit   bool
+		mode   Mode     // checked unless quit
+		focus  string   // title of the focused card; "" = unchecked
+		items  []string // card titles of the active grid; nil = unchecked
+		client string   // client target afterwards; "" = unchecked
+		dump   string   // server state afterwards; "" = unchecked
+		status string   // status message; "" = unchecked
+	}{
+		// Drill-down.
+		{name: "starts on the attached session", keys: "", mode: ModeSessionGrid, focus: "work", items: []string{"work", "play"}},
+		{name: "confirm opens windows", keys: "o", mode: ModeWindowGrid, focus: "0: editor", items: []string{"0: editor", "1: logs"}},
+		{name: "confirm opens panes", keys: "o o", mode: ModePaneGrid, focus: "Pane 0", items: []string{"Pane 0", "Pane 1"}},
+		{name: "confirm on a pane switches", keys: "o o o", quit: true, client: "work:0.0"},
+		{name: "quick swap on a pane switches", keys: "o o end enter", quit: true, client: "work:0.1"},
+		{name: "back returns to windows", keys: "o o esc", mode: ModeWindowGrid, focus: "0: editor"},
+		{name: "back returns to sessions", keys: "o o esc esc", mode: ModeSessionGrid, focus: "work"},
+		{name: "back on sessions quits", keys: "esc", quit: true, client: "work:0.1"},
+
+		// Single-child skipping.
+		{name: "single-pane session switches", keys: "end o", quit: true, client: "play:0.0"},
+		{
+			name:  "single-window session skips to panes",
+			setup: func(f *tmuxtest.Fake) { f.AddPanes("play", 0, 1) },
+			keys:  "end o", mode: ModePaneGrid, focus: "Pane 0", items: []string{"Pane 0", "Pane 1"}, client: "work:0.1",
+		},
+		{name: "single-pane window switches", keys: "o end o", quit: true, client: "work:1.0"},
+
+		// Cut / copy / paste.
+		{
+			name: "cut window pasted on a session moves it", keys: "o end x esc end p",
+			mode: ModeSessionGrid, status: `moved window "logs" from work → play`,
+			dump: "play 0:bash[%0] 1:logs[%2]\nwork 0:editor[%1,%3]",
+		},
+		{
+			name: "copied window pasted on a session links it", keys: "o end menu c o p y ( enter esc end p",
+			mode: ModeSessionGrid, status: `linked window "logs" from work → play`,
+			dump: "play 0:bash[%0] 1:logs[%2]\nwork 0:editor[%1,%3] 1:logs[%2]",
+		},
+		{
+			name:  "moving a window out renumbers the rest",
+			setup: func(f *tmuxtest.Fake) { f.RenumberWindows = true },
+			keys:  "o x esc end p",
+			dump:  "play 0:bash[%0] 1:editor[%1,%3]\nwork 0:logs[%2]",
+		},
+		{
+			name: "cut pane pasted on a window joins it", keys: "o o end x esc end p",
+			mode: ModeWindowGrid, items: []string{"0: editor", "1: logs"}, status: "moved pane 1 from work:0 → work:1",
+			dump: "play 0:bash[%0]\nwork 0:editor[%1] 1:logs[%2,%3]",
+		},
+		{name: "window pasted on its own session", keys: "o x esc p", status: "already in this session", dump: fixture().Dump()},
+		{name: "window pasted on a window", keys: "o x p", mode: ModeWindowGrid, status: "paste a window on a session (back out first)"},
+		{name: "pane pasted on a pane", keys: "o o x p", mode: ModePaneGrid, status: "cannot paste pane onto a pane — back out to window grid"},
+		{name: "pane pasted on its own window", keys: "o o x esc p", mode: ModeWindowGrid, status: "already in this window"},
+		{name: "paste without cut", keys: "p", status: "clipboard is empty"},
+
+		// Session management
+		{name: "rename session", keys: "r ctrl+u d e v enter", items: []string{"dev", "play"}, status: "Renamed to: dev"},
+		{name: "rename session to a taken name", keys: "r ctrl+u p l a y enter", status: `a session named "play" already exists`, dump: fixture().Dump()},
+
+		// Window management.
+		{
+			name: "new window fills the first free index and is focused",
+			setup: func(f *tmuxtest.Fake) {
+				f.NewWindow("work", "x")
+				f.KillWindow("work", 1)
+			},
+			keys: "o n tab ctrl+u / enter", // the fake's /home/user doesn't exist
+			mode: ModeWindowGrid, focus: "1: bash", items: []string{"0: editor", "1: bash", "2: x"},
+		},
+		{
+			name: "form fields recall their own history",
+			keys: "o n x tab ctrl+u / enter n up tab up enter",
+			mode: ModeWindowGrid, focus: "3: x", items: []string{"0: editor", "1: logs", "2: x", "3: x"},
+		},
+
+		// Marks.
+		{name: "mark jumped to after the prefix", keys: "l m j ' j", quit: true, client: "play:0.0"},
+		{name: "unbound mark key jumps on its own", keys: "l m w h w", quit: true, client: "play:0.0"},
+		{name: "bound mark key keeps its binding", keys: "l m j h j", mode: ModeSessionGrid, focus: "work"},
+
+		// Reorder.
+		{name: "reorder sessions", keys: "L", mode: ModeSessionGrid, focus: "work", items: []string{"play", "work"}},
+		{name: "reorder past the edge", keys: "H", mode: ModeSessionGrid, items: []string{"work", "play"}},
+		{
+			name: "reorder windows swaps them", keys: "o L",
+			mode: ModeWindowGrid, focus: "1: editor", items: []string{"0: logs", "1: editor"},
+			dump: "play 0:bash[%0]\nwork 0:logs[%2] 1:editor[%1,%3]",
+		},
+		{
+			name: "reorder panes swaps them", keys: "o o L",
+			mode: ModePaneGrid, focus: "Pane 1",
+			dump: "play 0:bash[%0]\nwork 0:editor[%3,%1] 1:logs[%2]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := fixture()
+			if tt.setup != nil {
+				tt.setup(f)
+			}
+			m := newTestModel(t, f)
+
+			if quit := press(m, tt.keys); quit != tt.quit {
+				t.Fatalf("quit = %v, want %v (status %q)", quit, tt.quit, m.statusMsg)
+			}
+			if !tt.quit && m.currentMode != tt.mode {
+				t.Errorf("mode = %v, want %v", m.currentMode, tt.mode)
+			}
+			if focused := m.activeGrid().GetFocused(); tt.focus != "" && (focused == nil || focused.Title() != tt.focus) {
+				t.Errorf("focused card = %v, want %q", focused, tt.focus)
+			}
+			if got := titles(m.activeGrid()); tt.items != nil && !slices.Equal(got, tt.items) {
+				t.Errorf("cards = %q, want %q", got, tt.items)
+			}
+			if got := f.ClientTarget(); tt.client != "" && got != tt.client {
+				t.Errorf("client on %s, want %s", got, tt.client)
+			}
+			if got := f.Dump(); tt.dump != "" && got != tt.dump {
+				t.Errorf("server:\n%s\nwant\n%s", got, tt.dump)
+			}
+			if tt.status != "" && m.statusMsg != tt.status {
+				t.Errorf("status = %q, want %q", m.statusMsg, tt.status)
+			}
+		})
+	}
+}
+
+func TestReorderSessionsIsSaved(t *testing.T) {
+	m := newTestModel(t, fixture())
+	press(m, "L")
+
+	cfg, err := config.LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"play", "work"}; !slices.Equal(cfg.SessionOrder, want) {
+		t.Errorf("saved session order = %q, want %q", cfg.SessionOrder, want)
+	}
+
+	// A new model shows the saved order.
+	m, err = NewModelWith(fixture(), config.DefaultAppConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := titles(m.sessionGrid), []string{"play", "work"}; !slices.Equal(got, want) {
+		t.Errorf("cards after restart = %q, want %q", got, want)
+	}
+}
+
+// TestDrillInUsesSnapshot checks the window and pane grids come from the
+// snapshot taken with the sessions, not from later queries.
+func TestDrillInUsesSnapshot(t *testing.T) {
+	f := fixture()
+	m := newTestModel(t, f)
+	f.AddPanes("work", 0, 2).AddPanes("work", 1, 1) // tmux changes after the load
+
+	press(m, "o")
+	if got := m.windows[1].PaneCount; got != 1 {
+		t.Errorf("logs has %d panes, want the snapshot's 1", got)
+	}
+	press(m, "o")
+	if got, want := titles(m.paneGrid), []string{"Pane 0", "Pane 1"}; !slices.Equal(got, want) {
+		t.Errorf("pane cards = %q, want the snapshot's %q", got, want)
+	}
+
+	// A change made through tswitch takes a new snapshot.
+	press(m, "esc esc o")
+	if got := m.windows[1].PaneCount; got != 1 {
+		t.Errorf("logs has %d panes before a refresh, want 1", got)
+	}
+	press(m, "r ctrl+u n e w enter")
+	if got := m.windows[1].PaneCount; got != 2 {
+		t.Errorf("logs has %d panes after renaming a window, want 2", got)
+	}
+}
+
+// TestStateReloadsLive checks changes another tswitch saves show up.
+func TestStateReloadsLive(t *testing.T) {
+	m := newTestModel(t, fixture())
+	other, err := config.LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.SetSessionOrder([]string{"play", "work"})
+	other.SetMark("w", "", "work", -1, -1)
+	if err := config.SaveState(other); err != nil {
+		t.Fatal(err)
+	}
+
+	m.Update(stateTickMsg{})
+	if got, want := titles(m.sessionGrid), []string{"play", "work"}; !slices.Equal(got, want) {
+		t.Errorf("cards = %q, want %q", got, want)
+	}
+	if item := m.sessionGrid.GetFocused(); item == nil || item.Title() != "work" {
+		t.Errorf("focus moved to %v, want it kept on work", item)
+	}
+	if !m.config.HasMark("w") {
+		t.Error("mark w not loaded")
+	}
+}
+
+func TestBroadcastTargets(t *testing.T) {
+	local := fixture()
+	if err := local.LinkWindow("work", 1, "play"); err != nil {
+		t.Fatal(err)
+	}
+	m := newTestModel(t, local)
+	m.servers = append(m.servers, tmux.Server{Name: "other", Service: tmuxtest.New().AddSession("work")})
+	if err := m.loadSessions(); err != nil {
+		t.Fatal(err)
+	}
+
+	// logs (%2) is linked into play as well and gets the command once.
+	targets, err := m.sessionTargets(m.sessions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, tg := range targets {
+		got = append(got, tg.String())
+	}
+	if want := []string{"work:0.0", "work:0.1", "work:1.0", "play:0.0", "other/work:0.0"}; !slices.Equal(got, want) {
+		t.Errorf("targets = %q, want %q", got, want)
+	}
+
+	// A tag applies to the session on its own server only.
+	m.config.AddSessionTag("", "work", "dev")
+	tagged := m.taggedSessions("dev")
+	if len(tagged) != 1 || tagged[0].Server != "" {
+		t.Errorf("sessions tagged dev = %+v, want the local work only", tagged)
+	}
+}
+
+func TestNewModelRefusesUnreadableState(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("TSWITCH_STATE", "")
+	path := filepath.Join(home, ".local", "state", "tswitch", "state.yaml")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("version: 99\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewModelWith(fixture(), config.DefaultAppConfig()); err == nil || !strings.Contains(err.Error(), "upgrade tswitch") {
+		t.Errorf("NewModelWith() error = %v, want the newer-version error", err)
+	}
+}
+
+// slowService answers Snapshot only once released, like an ssh host that
+// doesn't respond.
+type slowService struct {
+	*tmuxtest.Fake
+	release chan struct{}
+}
+
+func (s slowService) Snapshot() ([]tmux.Session, error) {
+	<-s.release
+	return s.Fake.Snapshot()
+}
+
+func TestRemoteSessionsLoadInBackground(t *testing.T) {
+	local := fixture()
+	remote := slowService{tmuxtest.New().AddSession("far"), make(chan struct{})}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("TSWITCH_STATE", "")
+	m, err := NewModelWithServers([]tmux.Server{{Service: local}, {Name: "devbox", Service: remote, Remote: true}}, config.DefaultAppConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := titles(m.sessionGrid), []string{"work", "play"}; !slices.Equal(got, want) {
+		t.Errorf("cards before the host answers = %q, want %q", got, want)
+	}
+
+	close(remote.release)
+	update(m, m.loadRemote()())
+	if got, want := titles(m.sessionGrid), []string{"work", "play", "far"}; !slices.Equal(got, want) {
+		t.Errorf("cards = %q, want %q", got, want)
+	}
+	if item, ok := m.sessionGrid.GetFocused().(SessionCard); !ok || item.session.Name != "work" {
+		t.Errorf("focus moved to %v, want it kept on work", m.sessionGrid.GetFocused())
+	}
+}
+
+func TestSwitchToRemoteMatch(t *testing.T) {
+	local := fixture()
+	remote := tmuxtest.New().AddSession("far")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("TSWITCH_STATE", "")
+	m, err := NewModelWithServers([]tmux.Server{{Service: local}, {Name: "devbox", Service: remote, Remote: true}}, config.DefaultAppConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.SetFilter("far")
+	switched, err := m.SwitchToSingleMatch()
+	if err != nil || !switched {
+		t.Fatalf("SwitchToSingleMatch() = %v, %v; want far on devbox", switched, err)
+	}
+	if !strings.Contains(local.Dump(), "far@devbox") {
+		t.Errorf("no window attaching far@devbox:\n%s", local.Dump())
+	}
+}
+
+func TestNewSessionFocusedOnCurrentServer(t *testing.T) {
+	m := newTestModel(t, fixture())
+	other := tmuxtest.New().AddSession("dev")
+	m.servers = append(m.servers, tmux.Server{Name: "/a/x", Socket: "/a/x", Service: other})
+	m.config.SetSessionOrder([]string{"/a/x:dev", "work", "play"})
+	if err := m.loadSessions(); err != nil {
+		t.Fatal(err)
+	}
+	press(m, "n d e v tab ctrl+u / enter")
+
+	var servers []string
+	for _, item := range m.sessionGrid.Items() {
+		if card := item.(SessionCard); card.session.Name == "dev" {
+			servers = append(servers, card.session.Server)
+		}
+	}
+	if want := []string{"/a/x", ""}; !slices.Equal(servers, want) {
+		t.Fatalf("dev sessions on %q, want %q", servers, want)
+	}
+	if card, ok := m.sessionGrid.GetFocused().(SessionCard); !ok || card.session.Server != "" || card.session.Name != "dev" {
+		t.Errorf("focus on %+v, want the new dev on the current server", m.sessionGrid.GetFocused())
+	}
+}
+
+func TestSameNamedSocketsStayApart(t *testing.T) {
+	m := newTestModel(t, fixture())
+	a := tmuxtest.New().AddSession("dev")
+	b := tmuxtest.New().AddSession("dev")
+	b.NewWindow("dev", "beta")
+	m.servers = append(m.servers,
+		tmux.Server{Name: "/a/x", Socket: "/a/x", Service: a},
+		tmux.Server{Name: "/b/x", Socket: "/b/x", Service: b})
+	if err := m.loadSessions(); err != nil {
+		t.Fatal(err)
+	}
+
+	if m.serviceFor("/a/x") != a || m.serviceFor("/b/x") != b {
+		t.Error("serviceFor doesn't tell the sockets apart")
+	}
+	if got := FilterSessions(m.sessions, "beta", m.windowsBySession); len(got) != 1 || got[0].Server != "/b/x" {
+		t.Errorf("sessions matching beta = %+v, want dev on /b/x only", got)
+	}
+
+	m.config.SetMark("d", "/b/x", "dev", -1, -1)
+	marks := m.buildMarkMap(true)
+	m.config.SetSessionOrder([]string{"/b/x:dev", "/a/x:dev"})
+	var order []string
+	for _, s := range m.applySavedSessionOrder(m.sessions) {
+		if s.Name == "dev" {
+			order = append(order, s.Server+":"+marks[config.SessionKey(s.Server, s.Name)])
+		}
+	}
+	if want := []string{"/b/x:d", "/a/x:"}; !slices.Equal(order, want) {
+		t.Errorf("dev sessions (server:mark) = %q, want %q", order, want)
+	}
+}
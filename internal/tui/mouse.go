@@ -0,0 +1,123 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// doubleClickTime is the longest gap between two clicks on the same card
+// that still counts as a double-click.
+const doubleClickTime = 400 * time.Millisecond
+
+// handleMouse focuses the clicked card, switches on double-click and opens
+// the card menu on right-click. The wheel scrolls whichever of the grid and
+// the preview is under the pointer.
+func (m *Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if msg.Action != tea.MouseActionPress {
+		return m, nil
+	}
+	m.helpShown = false
+	if m.dialog != nil {
+		return m.handleDialogMouse(msg)
+	}
+
+	grid := m.activeGrid()
+	overPreview := msg.X > grid.UsedWidth()
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		if overPreview {
+			m.previewPanel.ScrollBy(-3)
+		} else {
+			grid.ScrollBy(-1)
+		}
+		return m, nil
+	case tea.MouseButtonWheelDown:
+		if overPreview {
+			m.previewPanel.ScrollBy(3)
+		} else {
+			grid.ScrollBy(1)
+		}
+		return m, nil
+	case tea.MouseButtonLeft, tea.MouseButtonRight:
+	default:
+		return m, nil
+	}
+
+	idx := grid.ItemAt(msg.X, msg.Y-m.bodyTop())
+	if idx < 0 || overPreview {
+		return m, nil
+	}
+	m.pendingKeys = nil
+	double := msg.Button == tea.MouseButtonLeft && idx == m.lastClick &&
+		time.Since(m.lastClickTime) < doubleClickTime
+	m.lastClick, m.lastClickTime = idx, time.Now()
+
+	grid.SetFocus(idx)
+	cmd := m.syncPreview()
+	switch {
+	case msg.Button == tea.MouseButtonRight:
+		m.openCardMenu()
+	case double:
+		m.lastClickTime = time.Time{}
+		return m.handleQuickSwap()
+	}
+	return m, cmd
+}
+
+// handleDialogMouse lets the card menu be driven by the mouse: click picks
+// an entry, clicking outside or right-clicking closes it, the wheel moves
+// the selection. Clicking a form field focuses it. Other dialogs ignore the
+// mouse.
+func (m *Model) handleDialogMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	d := m.dialog
+	if d.Kind == DialogForm {
+		if msg.Button == tea.MouseButtonLeft {
+			_, top := m.dialogOrigin()
+			if i := d.FieldAt(msg.Y - top); i >= 0 {
+				d.SelectedIdx = i
+			}
+		}
+		return m, nil
+	}
+	if d.Kind != DialogMenu {
+		return m, nil
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		d.MoveSelection(-1)
+	case tea.MouseButtonWheelDown:
+		d.MoveSelection(1)
+	case tea.MouseButtonRight:
+		m.dialog, m.menu, m.pendingAction = nil, nil, dialogNone
+	case tea.MouseButtonLeft:
+		box := d.Render(m.width, m.height)
+		w, h := lipgloss.Width(box), lipgloss.Height(box)
+		left, top := m.dialogOrigin()
+		if msg.X < left || msg.X >= left+w || msg.Y < top || msg.Y >= top+h {
+			m.dialog, m.menu, m.pendingAction = nil, nil, dialogNone
+			return m, nil
+		}
+		if i := d.ChoiceAt(msg.Y - top); i >= 0 {
+			return m.runMenuChoice(i)
+		}
+	}
+	return m, nil
+}
+
+// dialogOrigin returns the screen position of the dialog's top-left corner;
+// renderView centers it with lipgloss.Place.
+func (m *Model) dialogOrigin() (x, y int) {
+	box := m.dialog.Render(m.width, m.height)
+	return (m.width - lipgloss.Width(box)) / 2, (m.height - lipgloss.Height(box)) / 2
+}
+
+// bodyTop returns the screen row where the grid and preview start: below
+// the header, the clipboard banner if shown, and the separator.
+func (m *Model) bodyTop() int {
+	if m.clipboard != nil {
+		return 3
+	}
+	return 2
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/luytbq/tswitch/internal/tmux"
 )
 
@@ -25,6 +26,7 @@ type PreviewPanel struct {
 	mode    PreviewMode
 	content string
 	title   string
+	scroll  int // first content line shown
 }
 
 // NewPreviewPanel creates a new preview panel.
@@ -59,6 +61,7 @@ func (pp *PreviewPanel) ToggleMode() {
 // SetSessionMetadata populates the panel for a session.
 func (pp *PreviewPanel) SetSessionMetadata(session tmux.Session) {
 	pp.title = "Session"
+	pp.scroll = 0
 
 	var lines []string
 	lines = append(lines, pp.styles.CardTitle.Render(session.Name))
@@ -88,6 +91,7 @@ func (pp *PreviewPanel) SetSessionMetadata(session tmux.Session) {
 // SetWindowMetadata populates the panel for a window.
 func (pp *PreviewPanel) SetWindowMetadata(window tmux.Window) {
 	pp.title = "Window"
+	pp.scroll = 0
 
 	var lines []string
 	lines = append(lines, pp.styles.CardTitle.Render(fmt.Sprintf("%d: %s", window.Index, window.Name)))
@@ -95,6 +99,9 @@ func (pp *PreviewPanel) SetWindowMetadata(window tmux.Window) {
 
 	lines = append(lines, fmt.Sprintf("Panes:       %d", window.PaneCount))
 	lines = append(lines, fmt.Sprintf("Layout:      %s", window.Layout))
+	if window.Linked {
+		lines = append(lines, fmt.Sprintf("Linked:      %s", strings.Join(window.LinkedSessions, ", ")))
+	}
 
 	if window.WorkingDir != "" {
 		lines = append(lines, fmt.Sprintf("Dir:         %s", window.WorkingDir))
@@ -107,13 +114,18 @@ func (pp *PreviewPanel) SetWindowMetadata(window tmux.Window) {
 			lines = append(lines, fmt.Sprintf("Command:     %s", window.ActivePaneCmd))
 		}
 	}
+	if d, ok := parseLayout(window.Layout); ok {
+		lines = pp.appendDiagram(lines, d, -1)
+	}
 
 	pp.content = strings.Join(lines, "\n")
 }
 
-// SetPaneMetadata populates the panel for a pane.
-func (pp *PreviewPanel) SetPaneMetadata(pane tmux.Pane) {
+// SetPaneMetadata populates the panel for a pane, with its place in the
+// window's layout when known.
+func (pp *PreviewPanel) SetPaneMetadata(pane tmux.Pane, layout layoutDiagram, pos int) {
 	pp.title = "Pane"
+	pp.scroll = 0
 
 	var lines []string
 	lines = append(lines, pp.styles.CardTitle.Render(fmt.Sprintf("Pane %d", pane.Index)))
@@ -129,16 +141,52 @@ func (pp *PreviewPanel) SetPaneMetadata(pane tmux.Pane) {
 		lines = append(lines, fmt.Sprintf("Command:     %s", pane.Command))
 	}
 	lines = append(lines, fmt.Sprintf("Size:        %dx%d", pane.Width, pane.Height))
+	lines = pp.appendDiagram(lines, layout, pos)
 
 	pp.content = strings.Join(lines, "\n")
 }
 
+// SetLayoutPreview shows how a preset layout would arrange the window.
+func (pp *PreviewPanel) SetLayoutPreview(name string, n, total int, layout layoutDiagram) {
+	pp.title = "Layout"
+	pp.scroll = 0
+
+	lines := []string{
+		pp.styles.CardTitle.Render(name),
+		pp.styles.CardSubtle.Render(fmt.Sprintf("%d/%d · enter applies, esc cancels", n, total)),
+	}
+	pp.content = strings.Join(pp.appendDiagram(lines, layout, -1), "\n")
+}
+
+// appendDiagram adds the layout diagram below lines, sized to the space
+// left in the panel.
+func (pp *PreviewPanel) appendDiagram(lines []string, layout layoutDiagram, focus int) []string {
+	diagram := layout.Render(pp.width, pp.maxLines()-len(lines)-1, focus, pp.styles.CardTitle)
+	if diagram == "" {
+		return lines
+	}
+	return append(append(lines, ""), strings.Split(diagram, "\n")...)
+}
+
 // SetCaptureContent sets raw capture-pane output.
 func (pp *PreviewPanel) SetCaptureContent(content string) {
 	pp.title = "Preview"
+	pp.scroll = 0
 	pp.content = content
 }
 
+// ScrollBy scrolls the content by dy lines, stopping at either end.
+func (pp *PreviewPanel) ScrollBy(dy int) {
+	total := strings.Count(pp.content, "\n") + 1
+	pp.scroll = clamp(pp.scroll+dy, 0, max(total-pp.maxLines(), 0))
+}
+
+// maxLines returns the number of content lines that fit in the box:
+// total height - border(2) - padding(2) - title(1) - blank after title(1).
+func (pp *PreviewPanel) maxLines() int {
+	return max(pp.height-6, 1)
+}
+
 // Render returns the rendered panel string.
 //
 // pp.width  = content width passed to lipgloss Width(). Rendered = pp.width + 2 (border).
@@ -157,27 +205,26 @@ func (pp *PreviewPanel) Render() string {
 		body = pp.styles.CardSubtle.Render("(no content)")
 	}
 
-	// Usable lines inside the box: total height - border(2) - padding(2) - title(1) - blank after title(1).
-	maxLines := pp.height - 6
-	if maxLines < 1 {
-		maxLines = 1
-	}
+	maxLines := pp.maxLines()
 	lines := strings.Split(body, "\n")
+	if pp.scroll > 0 && pp.scroll < len(lines) {
+		lines = lines[pp.scroll:]
+	}
 	if len(lines) > maxLines {
 		lines = lines[:maxLines]
 	}
 
 	// Truncate lines that exceed the panel width to prevent layout overflow.
-	// Use lipgloss.Width for accurate visual width (handles wide/multi-byte chars).
+	// Captures carry the pane's colors: cut around the escapes and reset at
+	// the end of each line so a color doesn't run into the border.
 	for i, line := range lines {
 		if lipgloss.Width(line) > pp.width {
-			runes := []rune(line)
-			cut := len(runes)
-			for cut > 0 && lipgloss.Width(string(runes[:cut])) > pp.width {
-				cut--
-			}
-			lines[i] = string(runes[:cut])
+			line = ansi.Truncate(line, pp.width, "")
+		}
+		if strings.Contains(line, "\x1b") {
+			line += ansi.ResetStyle
 		}
+		lines[i] = line
 	}
 
 	inner := titleLine + "\n" + strings.Join(lines, "\n")
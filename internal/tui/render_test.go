@@ -0,0 +1,142 @@
+package tui
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/luytbq/tswitch/internal/tmux/tmuxtest"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// renderSizes are the terminal sizes every view is rendered at, from a
+// small split up to a large monitor.
+var renderSizes = [][2]int{{40, 12}, {60, 18}, {80, 24}, {120, 40}, {200, 50}}
+
+// wideFixture has CJK and emoji names, which take two cells per rune.
+func wideFixture() *tmuxtest.Fake {
+	return tmuxtest.New().
+		AddSession("日本語プロジェクト", "編集", "ログ").
+		AddSession("🚀 rocket", "📝 notes", "🐛 debug").
+		AddSession("mixed 中文 ✨ emoji", "窗口").
+		AddPanes("日本語プロジェクト", 0, 1).
+		SetContent("日本語プロジェクト", 0, 0, "$ echo こんにちは世界\nこんにちは世界\n$ ls\n📁 docs  📄 README.md  中文文件名.txt").
+		Attach("日本語プロジェクト")
+}
+
+// manyFixture has more sessions than fit on a screen.
+func manyFixture() *tmuxtest.Fake {
+	f := tmuxtest.New()
+	for i := 1; i <= 30; i++ {
+		f.AddSession(fmt.Sprintf("project-%02d", i), "editor", "server", "logs")
+	}
+	return f.Attach("project-01")
+}
+
+// contentFixture is fixture with text in the panes.
+func contentFixture() *tmuxtest.Fake {
+	return fixture().
+		SetContent("work", 0, 0, "$ make test\nok  github.com/luytbq/tswitch/internal/tui  0.012s\n$ ").
+		SetContent("work", 0, 1, "$ tail -f app.log\n2024-01-01 12:00:00 INFO server started on :8080 with a line long enough to be cut at every width we render")
+}
+
+var renderCases = []struct {
+	name    string
+	fixture func() *tmuxtest.Fake
+	keys    string
+}{
+	{"sessions", contentFixture, ""},
+	{"sessions-metadata", contentFixture, "tab"},
+	{"windows", contentFixture, "o"},
+	{"windows-metadata", contentFixture, "o tab"},
+	{"panes", contentFixture, "o o"},
+	{"panes-metadata", contentFixture, "o o tab"},
+	{"help", contentFixture, "?"},
+	{"kill-confirm", contentFixture, "d"},
+	{"menu", contentFixture, "menu"},
+	{"filter", contentFixture, "/ w o"},
+	{"wide-sessions", wideFixture, ""},
+	{"wide-windows", wideFixture, "o"},
+	{"wide-panes", wideFixture, "o o"},
+	{"wide-metadata", wideFixture, "tab"},
+	{"many-sessions", manyFixture, ""},
+	{"many-sessions-last", manyFixture, "end"},
+}
+
+// TestRender renders each case at every size and compares the text, without
+// colors, with testdata/golden. Run with -update to rewrite the files after
+// an intended change, then review the diff.
+func TestRender(t *testing.T) {
+	defer func(orig func() time.Time) { now = orig }(now)
+	now = func() time.Time { return tmuxtest.Start.Add(2 * time.Hour) }
+
+	for _, tc := range renderCases {
+		for _, size := range renderSizes {
+			w, h := size[0], size[1]
+			t.Run(fmt.Sprintf("%s/%dx%d", tc.name, w, h), func(t *testing.T) {
+				m := newTestModel(t, tc.fixture())
+				update(m, tea.WindowSizeMsg{Width: w, Height: h})
+				press(m, tc.keys)
+				view := ansi.Strip(m.View())
+
+				lines := strings.Split(view, "\n")
+				for i, line := range lines {
+					if !utf8.ValidString(line) {
+						t.Errorf("line %d is not valid UTF-8: %q", i+1, line)
+					}
+					if lw := ansi.StringWidth(line); lw > w {
+						t.Errorf("line %d is %d cells wide, more than the terminal's %d:\n%s", i+1, lw, w, line)
+					}
+				}
+				if len(lines) > h {
+					t.Errorf("view has %d lines, more than the terminal's %d", len(lines), h)
+				}
+
+				golden := filepath.Join("testdata", "golden", fmt.Sprintf("%s-%dx%d.golden", tc.name, w, h))
+				if *updateGolden {
+					if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+						t.Fatal(err)
+					}
+					if err := os.WriteFile(golden, []byte(view), 0o644); err != nil {
+						t.Fatal(err)
+					}
+					return
+				}
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatalf("%v (run go test ./internal/tui -run TestRender -update to create it)", err)
+				}
+				if view != string(want) {
+					t.Errorf("view differs from %s (rerun with -update if intended):\n%s", golden, view)
+				}
+			})
+		}
+	}
+}
+
+// TestPreviewColors checks that a colored capture is cut by its visible
+// width and its color ends before the border.
+func TestPreviewColors(t *testing.T) {
+	pp := NewPreviewPanel(20, 10, NewStyles())
+	pp.SetCaptureContent("\x1b[31m" + strings.Repeat("é", 30) + "\nplain")
+	var red string
+	for _, line := range strings.Split(pp.Render(), "\n") {
+		if w := ansi.StringWidth(line); w > 24 {
+			t.Errorf("line is %d cells wide, more than the panel's 24: %q", w, line)
+		}
+		if strings.Contains(line, "\x1b[31m") {
+			red = line
+		}
+	}
+	if !strings.Contains(red, strings.Repeat("é", 20)+ansi.ResetStyle) {
+		t.Errorf("colored line = %q, want 20 cells then a reset", red)
+	}
+}
@@ -11,6 +11,7 @@ type Styles struct {
 	CardSubtle lipgloss.Style
 	CardAttached     lipgloss.Style
 	MarkBadge        lipgloss.Style
+	SectionHeader    lipgloss.Style
 
 	// Preview
 	PreviewBorder lipgloss.Style
@@ -63,6 +64,11 @@ func NewStyles() Styles {
 			Foreground(lipgloss.Color("222")).
 			Bold(true),
 
+		SectionHeader: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("176")).
+			Bold(true).
+			PaddingLeft(1),
+
 		PreviewBorder: lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
 			BorderForeground(lipgloss.Color("238")),
@@ -102,8 +108,7 @@ func NewStyles() Styles {
 
 		HelpSection: lipgloss.NewStyle().
 			Foreground(lipgloss.Color("114")).
-			Bold(true).
-			MarginTop(1),
+			Bold(true),
 
 		HelpDesc: lipgloss.NewStyle().
 			Foreground(lipgloss.Color("250")),
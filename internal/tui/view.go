@@ -2,9 +2,12 @@ package tui
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/luytbq/tswitch/internal/config"
+	"github.com/luytbq/tswitch/internal/keys"
 )
 
 // ---------------------------------------------------------------------------
@@ -15,7 +18,10 @@ func (m *Model) renderSessionView() string {
 	m.sessionGrid.SetMarks(m.buildMarkMap(true))
 
 	count := len(m.sessionGrid.Items())
-	header := m.styles.HeaderStyle.Render(fmt.Sprintf("Sessions (%d)", count))
+	if !m.tmux.IsInTmux() {
+		count-- // "new session here" card
+	}
+	header := m.renderHeader(fmt.Sprintf("Sessions (%d)", count))
 	separator := m.styles.CardSubtle.Render(strings.Repeat("─", m.width))
 
 	return m.renderLayout(header, separator, m.sessionGrid.Render(), m.previewPanel.Render())
@@ -25,7 +31,7 @@ func (m *Model) renderWindowView() string {
 	m.windowGrid.SetMarks(m.buildMarkMap(false))
 
 	count := len(m.windowGrid.Items())
-	header := m.styles.HeaderStyle.Render(fmt.Sprintf("Sessions › %s (%d)", m.currentSess, count))
+	header := m.renderHeader(fmt.Sprintf("Sessions › %s (%d)", m.sessionLabel(), count))
 	separator := m.styles.CardSubtle.Render(strings.Repeat("─", m.width))
 
 	return m.renderLayout(header, separator, m.windowGrid.Render(), m.previewPanel.Render())
@@ -34,59 +40,141 @@ func (m *Model) renderWindowView() string {
 func (m *Model) renderPaneView() string {
 	count := len(m.paneGrid.Items())
 	winName := m.windowName(m.currentWin)
-	header := m.styles.HeaderStyle.Render(fmt.Sprintf("Sessions › %s › %s (%d)", m.currentSess, winName, count))
+	header := m.renderHeader(fmt.Sprintf("Sessions › %s › %s (%d)", m.sessionLabel(), winName, count))
 	separator := m.styles.CardSubtle.Render(strings.Repeat("─", m.width))
 
 	return m.renderLayout(header, separator, m.paneGrid.Render(), m.previewPanel.Render())
 }
 
-func (m *Model) renderHelp() string {
+// renderHeader styles the breadcrumb, cut to the terminal width.
+func (m *Model) renderHeader(text string) string {
+	return m.styles.HeaderStyle.Render(truncateWidth(text, m.width-1)) // PaddingLeft(1)
+}
+
+// renderKeyHints draws the which-key popup over the bottom of view, just
+// above the status bar. After a pending prefix it lists the keys that can
+// follow; after the mark prefix it lists the marks; on toggle_help it lists
+// the whole keymap of the current mode.
+func (m *Model) renderKeyHints(view string) string {
+	scope := m.keyScope()
+	var title string
+	var entries []keys.Entry
+	switch {
+	case m.markJump:
+		title = "marks"
+		entries = m.markEntries()
+	case len(m.pendingKeys) > 0:
+		title = keys.FormatSequence(m.pendingKeys) + " …"
+		entries = keys.Continuations(scope, m.pendingKeys)
+	case m.helpShown:
+		title = m.modeName() + " keys"
+		entries = keys.Continuations(scope, nil)
+		if !keys.HasMarkPrefix() && scope != keys.ScopeFilter && scope != keys.ScopeDialog {
+			entries = append(entries, m.markEntries()...)
+		}
+	default:
+		return view
+	}
+	if len(entries) == 0 {
+		return view
+	}
+	return overlayBottom(view, m.renderKeyPopup(title, entries))
+}
+
+// markEntries lists the marks as popup entries.
+func (m *Model) markEntries() []keys.Entry {
+	names := make([]string, 0, len(m.config.Marks))
+	for key := range m.config.Marks {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	entries := make([]keys.Entry, 0, len(names))
+	for _, key := range names {
+		mark := m.config.Marks[key]
+		target := mark.SessionName
+		if mark.WindowIndex >= 0 {
+			target += fmt.Sprintf(":%d", mark.WindowIndex)
+		}
+		if mark.PaneIndex >= 0 {
+			target += fmt.Sprintf(".%d", mark.PaneIndex)
+		}
+		if mark.Server != "" {
+			target += "@" + m.serverLabel(mark.Server)
+		}
+		entries = append(entries, keys.Entry{Keys: key, Desc: "→ " + target})
+	}
+	return entries
+}
+
+// renderKeyPopup lays entries out in as many columns as fit the width,
+// filling each column top to bottom. When the entries don't fit the height
+// the columns get narrower and descriptions are truncated.
+func (m *Model) renderKeyPopup(title string, entries []keys.Entry) string {
 	s := m.styles
-	var b strings.Builder
+	innerW := max(m.width-4, 1)   // border + padding
+	maxRows := max(m.height-6, 1) // header, separator, status bar, border, title
 
-	b.WriteString(s.HeaderStyle.Render("TSWITCH - TMUX Session Navigator"))
-	b.WriteString("\n\n")
-
-	// Navigation
-	b.WriteString(s.HelpSection.Render("Navigation"))
-	b.WriteString("\n")
-	writeHelpLine(&b, s, "h/j/k/l, arrows", "Move between cards")
-	writeHelpLine(&b, s, "enter", "Drill into children / Select pane")
-	writeHelpLine(&b, s, "space", "Switch to focused item")
-	writeHelpLine(&b, s, "esc", "Go back / Quit")
-
-	b.WriteString("\n")
-	b.WriteString(s.HelpSection.Render("Marks"))
-	b.WriteString("\n")
-	writeHelpLine(&b, s, "m + key", "Mark focused item with a key")
-	writeHelpLine(&b, s, "key", "Jump to marked session/window")
-
-	b.WriteString("\n")
-	b.WriteString(s.HelpSection.Render("Management"))
-	b.WriteString("\n")
-	writeHelpLine(&b, s, "n", "New session / window")
-	writeHelpLine(&b, s, "r", "Rename session / window")
-	writeHelpLine(&b, s, "d", "Kill session / window")
-	writeHelpLine(&b, s, "x", "Cut window/pane (toggle to clear)")
-	writeHelpLine(&b, s, "p", "Paste cut window/pane onto focus")
-	writeHelpLine(&b, s, "H/J/K/L", "Reorder items")
-	writeHelpLine(&b, s, "f", "Browse dirs (fzf)")
-
-	b.WriteString("\n")
-	b.WriteString(s.HelpSection.Render("Search & UI"))
-	b.WriteString("\n")
-	writeHelpLine(&b, s, "/", "Search (fuzzy filter)")
-	writeHelpLine(&b, s, "tab", "Toggle preview mode")
-	writeHelpLine(&b, s, "?", "Toggle this help")
-	writeHelpLine(&b, s, "q", "Quit")
-
-	return lipgloss.NewStyle().Padding(1, 2).Render(b.String())
-}
-
-func writeHelpLine(b *strings.Builder, s Styles, key, desc string) {
-	k := s.HelpKey.Render(fmt.Sprintf("  %-18s", key))
-	d := s.HelpDesc.Render(desc)
-	b.WriteString(k + d + "\n")
+	keyW, descW := 0, 0
+	for _, e := range entries {
+		keyW = max(keyW, lipgloss.Width(e.Keys))
+		descW = max(descW, lipgloss.Width(e.Desc))
+	}
+	colW := keyW + 2 + descW + 3
+	cols := clamp(innerW/colW, 1, len(entries))
+	rows := (len(entries) + cols - 1) / cols
+	if rows > maxRows {
+		// Too tall: use more, narrower columns and truncate descriptions.
+		rows = maxRows
+		cols = (len(entries) + rows - 1) / rows
+	}
+	colW = innerW / cols
+	keyW = min(keyW, colW/2)
+
+	lines := make([]string, rows)
+	for i, e := range entries {
+		key := truncateWidth(e.Keys, keyW)
+		desc := truncateWidth(e.Desc, max(colW-keyW-4, 1))
+		descStyle := s.HelpDesc
+		if e.Prefix {
+			descStyle = s.HelpSection
+		}
+		cell := s.HelpKey.Render(key+strings.Repeat(" ", keyW-lipgloss.Width(key))) + "  " + descStyle.Render(desc)
+		if i/rows < cols-1 {
+			cell += strings.Repeat(" ", max(colW-lipgloss.Width(cell), 0))
+		}
+		lines[i%rows] += cell
+	}
+
+	body := s.HelpSection.Render(title) + "\n" + strings.Join(lines, "\n")
+	return s.BorderStyle.Padding(0, 1).Width(m.width - 2).Render(body)
+}
+
+// modeName labels the current key scope in the help popup.
+func (m *Model) modeName() string {
+	switch m.keyScope() {
+	case keys.ScopeWindows:
+		return "window"
+	case keys.ScopePanes:
+		return "pane"
+	case keys.ScopeFilter:
+		return "search"
+	case keys.ScopeDialog:
+		return "dialog"
+	}
+	return "session"
+}
+
+// overlayBottom draws panel over the lines of view just above its last line
+// (the status bar).
+func overlayBottom(view, panel string) string {
+	lines := strings.Split(view, "\n")
+	p := strings.Split(panel, "\n")
+	end := len(lines) - 1
+	if len(p) > end {
+		p = p[len(p)-end:]
+	}
+	copy(lines[end-len(p):end], p)
+	return strings.Join(lines, "\n")
 }
 
 // ---------------------------------------------------------------------------
@@ -125,35 +213,27 @@ func (m *Model) renderClipboardBanner() string {
 		return ""
 	}
 	s := m.styles
-	text := fmt.Sprintf("[CUT] %s  —  p=paste  x=clear", m.clipboard.label)
+	scope := m.keyScope()
+	tag, clear := "[CUT]", keys.ActionCut
+	if m.clipboard.copy {
+		tag, clear = "[COPY]", keys.ActionCopy
+	}
+	text := fmt.Sprintf("%s %s  —  %s=paste  %s=clear", tag, m.clipboard.label,
+		keys.KeyFor(scope, keys.ActionPaste), keys.KeyFor(scope, clear))
 	return s.StatusSuccess.Render(text)
 }
 
 func (m *Model) renderStatusBar() string {
 	s := m.styles
+	width := m.width - 2 // StatusBar padding
 
 	// Filter mode: show the search prompt, suppress other content.
 	if m.filterMode {
 		prompt := s.StatusHints.Render("/") + " " + s.StatusSuccess.Render(m.filterQuery+"█")
-		hint := s.StatusHints.Render("  esc:clear  enter:keep")
+		hint := s.StatusHints.Render(m.renderHints(width - lipgloss.Width(prompt)))
 		return s.StatusBar.Width(m.width).Render(prompt + hint)
 	}
 
-	// Left side: mode label + keybind hints.
-	var modeLabel, hints string
-	switch m.currentMode {
-	case ModeSessionGrid:
-		modeLabel = s.StatusMode.Render("SESSIONS")
-		hints = " hjkl/HJKL:nav/reorder  o:open  enter/space:switch  tab:preview  /:search  n:new  r:rename  d:kill  p:paste  m:mark  f:browse  ?:help  q:quit"
-	case ModeWindowGrid:
-		modeLabel = s.StatusMode.Render("WINDOWS")
-		hints = " hjkl/HJKL:nav/reorder  o:open  enter/space:switch  tab:preview  /:search  n:new  r:rename  d:kill  x:cut  p:paste  m:mark  esc:back  ?:help  q:quit"
-	case ModePaneGrid:
-		modeLabel = s.StatusMode.Render("PANES")
-		hints = " hjkl/HJKL:nav/reorder  enter/space:switch  tab:preview  /:search  x:cut  p:paste  m:mark  esc:back  ?:help  q:quit"
-	}
-	left := modeLabel + s.StatusHints.Render(hints)
-
 	// Right side: active filter indicator or feedback message.
 	var right string
 	switch {
@@ -161,6 +241,10 @@ func (m *Model) renderStatusBar() string {
 		right = s.StatusSuccess.Render("  /" + m.filterQuery)
 	case m.markingMode:
 		right = s.StatusSuccess.Render("  Press a key to assign mark (ESC to cancel)")
+	case m.markJump:
+		right = s.StatusSuccess.Render("  Press a mark key (ESC to cancel)")
+	case len(m.pendingKeys) > 0:
+		right = s.StatusSuccess.Render("  " + keys.FormatSequence(m.pendingKeys) + " …")
 	case m.statusMessage() != "":
 		msg := m.statusMessage()
 		if m.isStatusError {
@@ -170,6 +254,19 @@ func (m *Model) renderStatusBar() string {
 		}
 	}
 
+	// Left side: mode label + as many keybind hints as fit.
+	var modeLabel string
+	switch m.currentMode {
+	case ModeSessionGrid:
+		modeLabel = s.StatusMode.Render("SESSIONS")
+	case ModeWindowGrid:
+		modeLabel = s.StatusMode.Render("WINDOWS")
+	case ModePaneGrid:
+		modeLabel = s.StatusMode.Render("PANES")
+	}
+	hints := m.renderHints(width - lipgloss.Width(modeLabel) - lipgloss.Width(right))
+	left := modeLabel + s.StatusHints.Render(hints)
+
 	bar := left + right
 
 	// StatusBar style has Padding(0,1) which is inside Width(),
@@ -177,11 +274,56 @@ func (m *Model) renderStatusBar() string {
 	return s.StatusBar.Width(m.width).Render(bar)
 }
 
+// dialogHint formats the key hints for the open dialog, limited to the
+// actions its kind reacts to.
+func (m *Model) dialogHint() string {
+	var only []keys.Action
+	switch m.dialog.Kind {
+	case DialogInput:
+		only = []keys.Action{keys.ActionAccept, keys.ActionCancel}
+	case DialogConfirm:
+		only = []keys.Action{keys.ActionYes, keys.ActionNo, keys.ActionMoveLeft, keys.ActionMoveRight, keys.ActionAccept, keys.ActionCancel}
+	case DialogMenu, DialogForm:
+		only = []keys.Action{keys.ActionMoveDown, keys.ActionMoveUp, keys.ActionAccept, keys.ActionCancel}
+	}
+	var parts []string
+	for _, h := range keys.Hints(keys.ScopeDialog, only...) {
+		parts = append(parts, h.Keys+":"+h.Label)
+	}
+	if m.dialog.Kind == DialogInput || m.dialog.Kind == DialogForm {
+		// Line-editor keys are fixed, not part of the keymap.
+		if ed := m.dialog.Editor(); ed.complete != nil {
+			parts = append(parts, "tab:complete")
+		}
+		if len(m.dialog.Editor().history) > 0 {
+			parts = append(parts, "↑↓:history")
+		}
+	}
+	return strings.Join(parts, "  ")
+}
+
+// renderHints formats the key hints of the current mode, generated from the
+// live keymap, keeping only as many as fit in budget columns.
+func (m *Model) renderHints(budget int) string {
+	var b strings.Builder
+	for _, h := range keys.Hints(m.keyScope()) {
+		part := "  " + h.Keys + ":" + h.Label
+		if b.Len() == 0 {
+			part = part[1:]
+		}
+		if lipgloss.Width(b.String())+lipgloss.Width(part) > budget {
+			break
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
 // ---------------------------------------------------------------------------
 // Mark map builders
 // ---------------------------------------------------------------------------
 
-// buildMarkMap creates a mapping from display-names to mark keys,
+// buildMarkMap creates a mapping from item keys to mark keys,
 // used by Grid to render mark indicators on cards.
 // When multiple marks target the same item, keys are concatenated (e.g. "a,b").
 func (m *Model) buildMarkMap(forSessions bool) map[string]string {
@@ -189,9 +331,9 @@ func (m *Model) buildMarkMap(forSessions bool) map[string]string {
 	for key, mark := range m.config.Marks {
 		var displayKey string
 		if forSessions {
-			displayKey = mark.SessionName
+			displayKey = config.SessionKey(mark.Server, mark.SessionName)
 		} else {
-			if mark.SessionName != m.currentSess {
+			if mark.Server != m.currentServer || mark.SessionName != m.currentSess {
 				continue
 			}
 			displayKey = fmt.Sprintf("%d: %s", mark.WindowIndex, m.windowName(mark.WindowIndex))
@@ -205,6 +347,15 @@ func (m *Model) buildMarkMap(forSessions bool) map[string]string {
 	return mm
 }
 
+// sessionLabel returns the browsed session's name, qualified with its server
+// when it isn't on the current one.
+func (m *Model) sessionLabel() string {
+	if m.currentServer == "" {
+		return m.currentSess
+	}
+	return m.currentSess + "@" + m.serverLabel(m.currentServer)
+}
+
 // windowName finds a window name by index in the current window list.
 func (m *Model) windowName(index int) string {
 	for _, w := range m.windows {
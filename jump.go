@@ -0,0 +1,169 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/luytbq/tswitch/internal/config"
+	"github.com/luytbq/tswitch/internal/keys"
+	"github.com/luytbq/tswitch/internal/tmux"
+	"github.com/luytbq/tswitch/internal/tui"
+)
+
+const markUsage = `Usage: tswitch mark set <key> [session[:window[.pane]]]
+       tswitch mark rm <key>
+       tswitch mark ls [--json|--tsv|--format TEMPLATE]
+
+Without a target, "mark set" marks the current window.
+`
+
+// runJump switches to the target of a mark, like pressing its key in the TUI.
+func runJump(args []string, appCfg *config.AppConfig) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: tswitch jump <markkey>")
+	}
+	cfg, err := config.LoadState()
+	if err != nil {
+		return err
+	}
+	mark := cfg.GetMark(args[0])
+	if mark == nil {
+		return fmt.Errorf("no mark '%s'", args[0])
+	}
+
+	// Pane-level marks switch to the exact pane; session-level marks let tmux
+	// pick the active window.
+	argv, err := tui.SwitchTo(tui.Servers(appCfg), mark.Server, mark.SessionName, mark.WindowIndex, mark.PaneIndex)
+	if err != nil {
+		return err
+	}
+	return execAttach(argv)
+}
+
+// runSwitch switches to the session a fuzzy query resolves to. When the query
+// matches several sessions (or none), the TUI opens with the query as filter.
+func runSwitch(args []string, appCfg *config.AppConfig) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: tswitch switch <query>")
+	}
+	model, err := tui.NewModel(appCfg)
+	if err != nil {
+		return err
+	}
+	model.SetFilter(strings.Join(args, " "))
+
+	switched, err := model.SwitchToSingleMatch()
+	if err != nil {
+		return err
+	}
+	if switched {
+		return execAttach(model.AttachCommand())
+	}
+	return runTUI(model)
+}
+
+// runMark manages marks from the shell.
+func runMark(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%s", markUsage)
+	}
+	cfg, err := config.LoadState()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "set":
+		if len(args) < 2 || len(args) > 3 {
+			return fmt.Errorf("%s", markUsage)
+		}
+		key := args[1]
+		if keys.IsReserved(key) {
+			return fmt.Errorf("'%s' is reserved", key)
+		}
+		client := tmux.NewClient()
+		sess, win, pane, err := resolveMarkTarget(client, args[2:])
+		if err != nil {
+			return err
+		}
+		if !client.HasSession(sess) {
+			return fmt.Errorf("no session %q", sess)
+		}
+		// Same semantics as marking in the TUI: session and window marks
+		// replace any other key pointing at the same target.
+		if pane < 0 {
+			cfg.RemoveMarksForTarget("", sess, win)
+		}
+		cfg.SetMark(key, "", sess, win, pane)
+		if err := config.SaveState(cfg); err != nil {
+			return err
+		}
+		fmt.Printf("Marked %s -> [%s]\n", formatTarget(sess, win, pane), key)
+		return nil
+
+	case "rm":
+		if len(args) != 2 {
+			return fmt.Errorf("%s", markUsage)
+		}
+		if !cfg.HasMark(args[1]) {
+			return fmt.Errorf("no mark '%s'", args[1])
+		}
+		cfg.DeleteMark(args[1])
+		return config.SaveState(cfg)
+
+	case "ls":
+		var opts listOptions
+		fs := flag.NewFlagSet("mark ls", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		fs.BoolVar(&opts.json, "json", false, "")
+		fs.BoolVar(&opts.tsv, "tsv", false, "")
+		fs.StringVar(&opts.format, "format", "", "")
+		if err := fs.Parse(args[1:]); err != nil {
+			return fmt.Errorf("%v\n%s", err, markUsage)
+		}
+		return writeRows(os.Stdout, listMarks(cfg, ""), opts)
+	}
+	return fmt.Errorf("unknown mark command: %s\n%s", args[0], markUsage)
+}
+
+// resolveMarkTarget parses "session[:window[.pane]]" into its parts, using -1
+// for the levels left out. With no argument it returns the current window.
+func resolveMarkTarget(client *tmux.Client, args []string) (sess string, win, pane int, err error) {
+	if len(args) == 0 {
+		if !client.IsInTmux() {
+			return "", 0, 0, fmt.Errorf("not inside a tmux session; give a target")
+		}
+		sess, win, _, err = client.CurrentPane()
+		return sess, win, -1, err
+	}
+
+	target := args[0]
+	win, pane = -1, -1
+	sess, rest, hasWin := strings.Cut(target, ":")
+	if hasWin {
+		winStr, paneStr, hasPane := strings.Cut(rest, ".")
+		if win, err = strconv.Atoi(winStr); err != nil {
+			return "", 0, 0, fmt.Errorf("invalid window index in %q", target)
+		}
+		if hasPane {
+			if pane, err = strconv.Atoi(paneStr); err != nil {
+				return "", 0, 0, fmt.Errorf("invalid pane index in %q", target)
+			}
+		}
+	}
+	return sess, win, pane, nil
+}
+
+func formatTarget(sess string, win, pane int) string {
+	switch {
+	case win < 0:
+		return sess
+	case pane < 0:
+		return fmt.Sprintf("%s:%d", sess, win)
+	}
+	return fmt.Sprintf("%s:%d.%d", sess, win, pane)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/luytbq/tswitch/internal/config"
+	"github.com/luytbq/tswitch/internal/keys"
+)
+
+const keysUsage = `Usage: tswitch keys [sessions|windows|panes|filter|dialog] [--json|--tsv|--format TEMPLATE]
+
+Prints the effective keymap, the conflicts between bindings and any
+problems found in the key config.
+`
+
+// keyWarnings holds the problems found while applying the key config.
+var keyWarnings []string
+
+// applyKeyConfig installs the leader, sequence timeout and key overrides
+// from the app config and records what went wrong in keyWarnings. Per-mode
+// keymaps are applied after the top-level keys so they win within their
+// mode.
+func applyKeyConfig(appCfg *config.AppConfig) {
+	if err := keys.SetLeader(appCfg.Leader); err != nil {
+		keyWarnings = append(keyWarnings, err.Error())
+	}
+	keys.SetTimeout(time.Duration(appCfg.KeyTimeoutMs) * time.Millisecond)
+	keyWarnings = append(keyWarnings, keys.ApplyOverrides(appCfg.Keys)...)
+	modes := make([]string, 0, len(appCfg.Keymaps))
+	for mode := range appCfg.Keymaps {
+		modes = append(modes, mode)
+	}
+	sort.Strings(modes)
+	for _, mode := range modes {
+		keyWarnings = append(keyWarnings, keys.ApplyScopeOverrides(mode, appCfg.Keymaps[mode])...)
+	}
+}
+
+// markWarnings reports the marks whose key now starts a binding, which can't
+// be jumped to any more. It reads state.yaml without upgrading it, and is
+// only called where marks matter: the TUI and tswitch keys.
+func markWarnings() []string {
+	cfg, err := config.ReadState()
+	if err != nil {
+		return []string{fmt.Sprintf("marks not checked: %v", err)}
+	}
+	markKeys := make([]string, 0, len(cfg.Marks))
+	for key := range cfg.Marks {
+		markKeys = append(markKeys, key)
+	}
+	return keys.MarkCollisions(markKeys)
+}
+
+// keyRow is one binding in `tswitch keys` output.
+type keyRow struct {
+	Mode   string `json:"mode"`
+	Keys   string `json:"keys"`
+	Action string `json:"action"`
+	Source string `json:"source"`
+}
+
+// runKeys prints the effective keymap. In table form the conflicts and
+// warnings follow it; with a machine-readable format they go to stderr.
+func runKeys(args []string) error {
+	var opts listOptions
+	fs := flag.NewFlagSet("keys", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(&opts.json, "json", false, "")
+	fs.BoolVar(&opts.tsv, "tsv", false, "")
+	fs.StringVar(&opts.format, "format", "", "")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return fmt.Errorf("%v\n%s", err, keysUsage)
+	}
+	if len(pos) > 1 {
+		return fmt.Errorf("too many arguments\n%s", keysUsage)
+	}
+
+	scopes := keys.Scopes()
+	if len(pos) == 1 {
+		scope, ok := keys.ScopeByName(pos[0])
+		if !ok {
+			return fmt.Errorf("unknown mode: %s\n%s", pos[0], keysUsage)
+		}
+		scopes = []keys.Scope{scope}
+	}
+
+	rows := []keyRow{}
+	for _, scope := range scopes {
+		for _, b := range keys.Bindings(scope) {
+			rows = append(rows, keyRow{
+				Mode:   keys.ScopeName(scope),
+				Keys:   keys.FormatSequence(b.Keys),
+				Action: keys.Info(b.Action).Name,
+				Source: b.Source,
+			})
+		}
+	}
+	if err := writeRows(os.Stdout, rows, opts); err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if opts.json || opts.tsv || opts.format != "" {
+		out = os.Stderr
+	}
+	var conflicts []string
+	for _, c := range keys.Conflicts() {
+		for _, scope := range scopes {
+			if c.Scope == scope {
+				conflicts = append(conflicts, c.String())
+			}
+		}
+	}
+	printSection(out, "Conflicts", conflicts)
+	printSection(out, "Warnings", append(keyWarnings, markWarnings()...))
+	return nil
+}
+
+func printSection(w io.Writer, title string, lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s:\n", title)
+	for _, l := range lines {
+		fmt.Fprintln(w, "  "+l)
+	}
+}
@@ -0,0 +1,462 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/luytbq/tswitch/internal/config"
+	"github.com/luytbq/tswitch/internal/tmux"
+	"github.com/luytbq/tswitch/internal/tui"
+)
+
+const listUsage = `Usage: tswitch list [sessions|windows|panes|marks|tags] [target] [flags]
+
+  sessions            sessions in tswitch order (default)
+  windows [session]   windows of one session, or of every session
+  panes [sess[:win]]  panes of one window, one session, or everything
+  marks               saved marks
+  tags                saved tags
+
+Flags:
+  --json              print a JSON array
+  --tsv               print tab-separated values without a header
+  --format TEMPLATE   print each row with a Go template, e.g. '{{.Name}} {{.WindowCount}}'
+  --filter TERM       fuzzy filter, as with / in the TUI
+`
+
+// Row types for `tswitch list`. Field names double as template fields.
+
+type sessionRow struct {
+	Name        string    `json:"name"`
+	WindowCount int       `json:"window_count"`
+	PaneCount   int       `json:"pane_count"`
+	Attached    bool      `json:"attached"`
+	Created     time.Time `json:"created"`
+	LastActive  time.Time `json:"last_active"`
+	Dir         string    `json:"dir"`
+	Command     string    `json:"command"`
+	Marks       []string  `json:"marks"`
+	Tags        []string  `json:"tags"`
+}
+
+type windowRow struct {
+	Session   string   `json:"session"`
+	Index     int      `json:"index"`
+	Name      string   `json:"name"`
+	PaneCount int      `json:"pane_count"`
+	Active    bool     `json:"active"`
+	Layout    string   `json:"layout"`
+	Dir       string   `json:"dir"`
+	Command   string   `json:"command"`
+	Marks     []string `json:"marks"`
+}
+
+type paneRow struct {
+	Session string   `json:"session"`
+	Window  int      `json:"window"`
+	Index   int      `json:"index"`
+	Active  bool     `json:"active"`
+	Width   int      `json:"width"`
+	Height  int      `json:"height"`
+	Command string   `json:"command"`
+	Dir     string   `json:"dir"`
+	Title   string   `json:"title"`
+	PID     int      `json:"pid"`
+	Marks   []string `json:"marks"`
+}
+
+type markRow struct {
+	Key     string `json:"key"`
+	Server  string `json:"server,omitempty"`
+	Session string `json:"session"`
+	Window  int    `json:"window"`
+	Pane    int    `json:"pane"`
+}
+
+type tagRow struct {
+	Tag      string   `json:"tag"`
+	Sessions []string `json:"sessions"`
+}
+
+// listOptions holds the parsed flags of `tswitch list`.
+type listOptions struct {
+	json   bool
+	tsv    bool
+	format string
+	filter string
+}
+
+func runList(args []string) error {
+	var opts listOptions
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(&opts.json, "json", false, "")
+	fs.BoolVar(&opts.tsv, "tsv", false, "")
+	fs.StringVar(&opts.format, "format", "", "")
+	fs.StringVar(&opts.filter, "filter", "", "")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return fmt.Errorf("%v\n%s", err, listUsage)
+	}
+
+	kind, target := "sessions", ""
+	if len(pos) > 0 {
+		kind = pos[0]
+	}
+	if len(pos) > 1 {
+		target = pos[1]
+	}
+
+	cfg, err := config.LoadState()
+	if err != nil {
+		return err
+	}
+	client := tmux.NewClient()
+
+	var rows any
+	switch kind {
+	case "sessions":
+		rows, err = listSessions(client, cfg, opts.filter)
+	case "windows":
+		rows, err = listWindows(client, cfg, target, opts.filter)
+	case "panes":
+		rows, err = listPanes(client, cfg, target, opts.filter)
+	case "marks":
+		rows = listMarks(cfg, opts.filter)
+	case "tags":
+		rows = listTags(cfg, opts.filter)
+	default:
+		return fmt.Errorf("unknown list kind: %s\n%s", kind, listUsage)
+	}
+	if err != nil {
+		return err
+	}
+	return writeRows(os.Stdout, rows, opts)
+}
+
+// orderedSessions returns the current server's sessions in the order the TUI
+// shows them, with pane counts filled in.
+func orderedSessions(svc tmux.Service, cfg *config.Config) ([]tmux.Session, error) {
+	sessions, err := svc.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+	if paneCounts, err := svc.ListAllPaneCounts(); err == nil {
+		for i := range sessions {
+			sessions[i].PaneCount = paneCounts[sessions[i].Name]
+		}
+	}
+	return tui.ApplySessionOrder(sessions, cfg.SessionOrder), nil
+}
+
+func listSessions(svc tmux.Service, cfg *config.Config, filter string) ([]sessionRow, error) {
+	sessions, err := orderedSessions(svc, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if filter != "" {
+		wbs, _ := svc.ListAllWindowNames()
+		sessions = tui.FilterSessions(sessions, filter, wbs)
+	}
+
+	rows := make([]sessionRow, 0, len(sessions))
+	for _, s := range sessions {
+		marks := cfg.MarksFor("", s.Name, -1, -1)
+		tags := append([]string{}, cfg.GetSessionTags("", s.Name)...)
+		sort.Strings(tags)
+		rows = append(rows, sessionRow{
+			Name:        s.Name,
+			WindowCount: s.WindowCount,
+			PaneCount:   s.PaneCount,
+			Attached:    s.Attached,
+			Created:     s.Created,
+			LastActive:  s.LastActive,
+			Dir:         s.ActivePaneDir,
+			Command:     s.ActivePaneCmd,
+			Marks:       marks,
+			Tags:        tags,
+		})
+	}
+	return rows, nil
+}
+
+// targetSessions resolves the sessions a windows/panes listing covers: the
+// named one, or all of them in TUI order.
+func targetSessions(svc tmux.Service, cfg *config.Config, session string) ([]string, error) {
+	if session != "" {
+		return []string{session}, nil
+	}
+	sessions, err := orderedSessions(svc, cfg)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(sessions))
+	for i, s := range sessions {
+		names[i] = s.Name
+	}
+	return names, nil
+}
+
+func listWindows(svc tmux.Service, cfg *config.Config, session, filter string) ([]windowRow, error) {
+	names, err := targetSessions(svc, cfg, session)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []windowRow{}
+	for _, name := range names {
+		windows, err := svc.ListWindows(name)
+		if err != nil {
+			return nil, err
+		}
+		windows = tui.FilterWindows(tui.ApplyWindowOrder(windows, cfg.WindowOrder[name]), filter)
+		for _, w := range windows {
+			rows = append(rows, windowRow{
+				Session:   name,
+				Index:     w.Index,
+				Name:      w.Name,
+				PaneCount: w.PaneCount,
+				Active:    w.Active,
+				Layout:    w.Layout,
+				Dir:       w.WorkingDir,
+				Command:   w.ActivePaneCmd,
+				Marks:     cfg.MarksFor("", name, w.Index, -1),
+			})
+		}
+	}
+	return rows, nil
+}
+
+// listPanes lists the panes of target, which is "session:window", "session"
+// or empty for every session.
+func listPanes(svc tmux.Service, cfg *config.Config, target, filter string) ([]paneRow, error) {
+	session, window := target, -1
+	if i := strings.LastIndexByte(target, ':'); i >= 0 {
+		idx, err := strconv.Atoi(target[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid window index in %q", target)
+		}
+		session, window = target[:i], idx
+	}
+
+	names, err := targetSessions(svc, cfg, session)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []paneRow{}
+	for _, name := range names {
+		var indices []int
+		if window >= 0 {
+			indices = []int{window}
+		} else {
+			windows, err := svc.ListWindows(name)
+			if err != nil {
+				return nil, err
+			}
+			for _, w := range tui.ApplyWindowOrder(windows, cfg.WindowOrder[name]) {
+				indices = append(indices, w.Index)
+			}
+		}
+		for _, idx := range indices {
+			panes, err := svc.ListPanes(name, idx)
+			if err != nil {
+				return nil, err
+			}
+			for _, p := range tui.FilterPanes(panes, filter) {
+				rows = append(rows, paneRow{
+					Session: name,
+					Window:  idx,
+					Index:   p.Index,
+					Active:  p.Active,
+					Width:   p.Width,
+					Height:  p.Height,
+					Command: p.Command,
+					Dir:     p.WorkingDir,
+					Title:   p.Title,
+					PID:     p.PID,
+					Marks:   cfg.MarksFor("", name, idx, p.Index),
+				})
+			}
+		}
+	}
+	return rows, nil
+}
+
+func listMarks(cfg *config.Config, filter string) []markRow {
+	rows := []markRow{}
+	for key, m := range cfg.Marks {
+		if filter != "" && !strings.Contains(m.SessionName, filter) && key != filter {
+			continue
+		}
+		rows = append(rows, markRow{
+			Key:     key,
+			Server:  m.Server,
+			Session: m.SessionName,
+			Window:  m.WindowIndex,
+			Pane:    m.PaneIndex,
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Key < rows[j].Key })
+	return rows
+}
+
+func listTags(cfg *config.Config, filter string) []tagRow {
+	rows := []tagRow{}
+	for tag, sessions := range cfg.Tags {
+		if filter != "" && !strings.Contains(tag, filter) {
+			continue
+		}
+		rows = append(rows, tagRow{Tag: tag, Sessions: sessions})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Tag < rows[j].Tag })
+	return rows
+}
+
+// writeRows prints rows (a slice of one of the row types) in the format
+// selected by opts: JSON, TSV, a per-row template, or an aligned table.
+func writeRows(w io.Writer, rows any, opts listOptions) error {
+	switch {
+	case opts.json:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+
+	case opts.format != "":
+		tmpl, err := template.New("row").Funcs(template.FuncMap{"join": strings.Join}).Parse(opts.format)
+		if err != nil {
+			return fmt.Errorf("invalid --format: %w", err)
+		}
+		for _, row := range rowSlice(rows) {
+			if err := tmpl.Execute(w, row); err != nil {
+				return err
+			}
+			fmt.Fprintln(w)
+		}
+		return nil
+
+	case opts.tsv:
+		for _, row := range rowSlice(rows) {
+			fmt.Fprintln(w, strings.Join(rowFields(row), "\t"))
+		}
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if items := rowSlice(rows); len(items) > 0 {
+		fmt.Fprintln(tw, strings.Join(rowHeader(items[0]), "\t"))
+		for _, row := range items {
+			fmt.Fprintln(tw, strings.Join(rowFields(row), "\t"))
+		}
+	}
+	return tw.Flush()
+}
+
+// rowSlice converts a typed row slice to []any for uniform iteration.
+func rowSlice(rows any) []any {
+	var out []any
+	switch rs := rows.(type) {
+	case []sessionRow:
+		for _, r := range rs {
+			out = append(out, r)
+		}
+	case []windowRow:
+		for _, r := range rs {
+			out = append(out, r)
+		}
+	case []paneRow:
+		for _, r := range rs {
+			out = append(out, r)
+		}
+	case []markRow:
+		for _, r := range rs {
+			out = append(out, r)
+		}
+	case []tagRow:
+		for _, r := range rs {
+			out = append(out, r)
+		}
+	case []keyRow:
+		for _, r := range rs {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// rowHeader returns the column names for the table output.
+func rowHeader(row any) []string {
+	switch row.(type) {
+	case sessionRow:
+		return []string{"NAME", "WINDOWS", "PANES", "ATTACHED", "LAST_ACTIVE", "DIR", "MARKS", "TAGS"}
+	case windowRow:
+		return []string{"SESSION", "INDEX", "NAME", "PANES", "ACTIVE", "DIR", "COMMAND", "MARKS"}
+	case paneRow:
+		return []string{"SESSION", "WINDOW", "INDEX", "ACTIVE", "SIZE", "COMMAND", "DIR", "MARKS"}
+	case markRow:
+		return []string{"KEY", "SESSION", "WINDOW", "PANE", "SERVER"}
+	case tagRow:
+		return []string{"TAG", "SESSIONS"}
+	case keyRow:
+		return []string{"MODE", "KEYS", "ACTION", "SOURCE"}
+	}
+	return nil
+}
+
+// rowFields returns the values of a row, in rowHeader order, for the table
+// and TSV outputs.
+func rowFields(row any) []string {
+	b := strconv.FormatBool
+	i := strconv.Itoa
+	switch r := row.(type) {
+	case sessionRow:
+		return []string{r.Name, i(r.WindowCount), i(r.PaneCount), b(r.Attached),
+			formatUnix(r.LastActive), r.Dir, strings.Join(r.Marks, ","), strings.Join(r.Tags, ",")}
+	case windowRow:
+		return []string{r.Session, i(r.Index), r.Name, i(r.PaneCount), b(r.Active),
+			r.Dir, r.Command, strings.Join(r.Marks, ",")}
+	case paneRow:
+		return []string{r.Session, i(r.Window), i(r.Index), b(r.Active),
+			fmt.Sprintf("%dx%d", r.Width, r.Height), r.Command, r.Dir, strings.Join(r.Marks, ",")}
+	case markRow:
+		return []string{r.Key, r.Session, i(r.Window), i(r.Pane), r.Server}
+	case tagRow:
+		return []string{r.Tag, strings.Join(r.Sessions, ",")}
+	case keyRow:
+		return []string{r.Mode, r.Keys, r.Action, r.Source}
+	}
+	return nil
+}
+
+func formatUnix(t time.Time) string {
+	if t.IsZero() {
+		return "0"
+	}
+	return strconv.FormatInt(t.Unix(), 10)
+}
+
+// parseInterspersed parses flags that may appear before, between or after
+// positional arguments, returning the positional ones in order.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var pos []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return pos, nil
+		}
+		pos = append(pos, args[0])
+		args = args[1:]
+	}
+}
@@ -1,8 +1,9 @@
 package main
 
 import (
+	"flag"
 	"fmt"
-	"log"
+	"io"
 	"os"
 	"os/exec"
 	"runtime/debug"
@@ -10,7 +11,6 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/luytbq/tswitch/internal/config"
-	"github.com/luytbq/tswitch/internal/keys"
 	"github.com/luytbq/tswitch/internal/tmux"
 	"github.com/luytbq/tswitch/internal/tui"
 )
@@ -35,22 +35,32 @@ func resolveVersion() string {
 }
 
 func main() {
+	args, err := parseGlobalFlags(os.Args[1:])
+	if err == nil {
+		err = tapTmux()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Load JSON app config and apply key overrides before anything else.
 	appCfg, err := config.LoadAppConfig()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to load app config: %v\n", err)
 	}
-	if appCfg != nil && len(appCfg.Keys) > 0 {
-		keys.ApplyOverrides(appCfg.Keys)
+	if appCfg != nil {
+		applyKeyConfig(appCfg)
 	}
 
-	if len(os.Args) > 1 && os.Args[1] == "version" {
+	if len(args) > 0 && args[0] == "version" {
 		fmt.Println("tswitch " + resolveVersion())
+		fmt.Println(tmux.NewClient().Capabilities())
 		return
 	}
 
-	if len(os.Args) > 1 {
-		if err := runSubcommand(os.Args[1], appCfg); err != nil {
+	if len(args) > 0 {
+		if err := runSubcommand(args[0], args[1:], appCfg); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -62,26 +72,102 @@ func main() {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	keyWarnings = append(keyWarnings, markWarnings()...)
+	if n := len(keyWarnings); n > 0 {
+		model.Warn(fmt.Sprintf("%d problem(s) in the key config, see `tswitch keys`", n))
+	}
+	if err := runTUI(model); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// parseGlobalFlags handles the flags given before the command, --config and
+// --state, and returns the rest of args.
+func parseGlobalFlags(args []string) ([]string, error) {
+	fs := flag.NewFlagSet("tswitch", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	configPath := fs.String("config", "", "")
+	statePath := fs.String("state", "", "")
+	if err := fs.Parse(args); err != nil {
+		return nil, fmt.Errorf("%v\nUsage: tswitch [--config <file>] [--state <file>] [command]", err)
+	}
+	config.SetConfigPath(*configPath)
+	config.SetStatePath(*statePath)
+	return fs.Args(), nil
+}
 
-	p := tea.NewProgram(model, tea.WithAltScreen())
+// tapTmux sets up recording or replaying of tmux commands, for bug reports:
+// TSWITCH_RECORD=path writes every command and its output to path (pane
+// contents masked when TSWITCH_RECORD_REDACT is set), and TSWITCH_REPLAY=path
+// answers commands from such a file instead of running tmux.
+func tapTmux() error {
+	if path := os.Getenv("TSWITCH_REPLAY"); path != "" {
+		records, err := tmux.LoadRecords(path)
+		if err != nil {
+			return err
+		}
+		tmux.Tap(tmux.NewReplayer(records).Wrap)
+		return nil
+	}
+	if path := os.Getenv("TSWITCH_RECORD"); path != "" {
+		// Left open: tswitch may exec into tmux rather than return.
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		rec := tmux.NewRecorder(f)
+		rec.Redact = os.Getenv("TSWITCH_RECORD_REDACT") != ""
+		tmux.Tap(rec.Wrap)
+	}
+	return nil
+}
+
+// runTUI runs the interactive program. Outside tmux, picking a session hands
+// the terminal over to a tmux client once the program has exited.
+func runTUI(model *tui.Model) error {
+	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
 	if _, err := p.Run(); err != nil {
-		log.Fatal(err)
+		return err
 	}
+	return execAttach(model.AttachCommand())
 }
 
-func runSubcommand(cmd string, appCfg *config.AppConfig) error {
-	client := tmux.NewClient()
-	if !client.IsInTmux() {
-		return fmt.Errorf("not inside a tmux session")
+// execAttach replaces tswitch with argv, if any.
+func execAttach(argv []string) error {
+	if argv == nil {
+		return nil
 	}
+	return tmux.Exec(argv)
+}
+
+func runSubcommand(cmd string, args []string, appCfg *config.AppConfig) error {
+	client := tmux.NewClient()
 
 	switch cmd {
 	case "last":
+		if !client.IsInTmux() {
+			return fmt.Errorf("not inside a tmux session")
+		}
 		return client.SwitchToLast()
 	case "browse":
 		return runBrowse(client, appCfg)
+	case "list":
+		return runList(args)
+	case "jump":
+		return runJump(args, appCfg)
+	case "switch":
+		return runSwitch(args, appCfg)
+	case "mark":
+		return runMark(args)
+	case "keys":
+		return runKeys(args)
+	case "send":
+		return runSend(args)
+	case "paths":
+		return runPaths()
 	default:
-		return fmt.Errorf("unknown command: %s\nUsage: tswitch [last|browse]", cmd)
+		return fmt.Errorf("unknown command: %s\nUsage: tswitch [last|browse|list|jump|switch|mark|keys|send|paths]", cmd)
 	}
 }
 
@@ -118,5 +204,12 @@ func runBrowse(client *tmux.Client, appCfg *config.AppConfig) error {
 		return nil
 	}
 
+	if !client.IsInTmux() {
+		name, err := tui.EnsureSessionForDir(client, selected)
+		if err != nil {
+			return err
+		}
+		return client.AttachSession(name)
+	}
 	return tui.SwitchOrCreateSession(client, selected)
 }
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/luytbq/tswitch/internal/config"
+)
+
+// runPaths prints the config and state files tswitch uses and why.
+func runPaths() error {
+	appConfig, err := config.AppConfigPath()
+	if err != nil {
+		return err
+	}
+	state, err := config.StatePath()
+	if err != nil {
+		return err
+	}
+	for _, p := range []struct {
+		name string
+		path config.Path
+	}{
+		{"config", appConfig},
+		{"state", state},
+	} {
+		fmt.Printf("%-7s %s\n        %s\n", p.name, p.path.File, p.path.Reason)
+	}
+	return nil
+}
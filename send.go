@@ -0,0 +1,138 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/luytbq/tswitch/internal/config"
+	"github.com/luytbq/tswitch/internal/tmux"
+	"github.com/luytbq/tswitch/internal/tui"
+)
+
+const sendUsage = `Usage: tswitch send [flags] <command>
+
+Types command into panes and presses enter, like broadcast (b) in the TUI.
+Pick the panes with one of:
+  --target sess[:win[.pane]]  a pane, every pane of a window, or of a session
+  --tag TAG                   every pane of the sessions tagged TAG
+  --filter TERM               every pane of the sessions TERM matches, as with / in the TUI
+
+Flags:
+  --dry-run                   list the panes instead of sending
+
+Put -- before a command that has flags of its own: tswitch send --tag dev -- ls -la
+`
+
+// runSend sends a command to the panes picked by the flags.
+func runSend(args []string) error {
+	var target, tag, filter string
+	var dryRun bool
+	fs := flag.NewFlagSet("send", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&target, "target", "", "")
+	fs.StringVar(&tag, "tag", "", "")
+	fs.StringVar(&filter, "filter", "", "")
+	fs.BoolVar(&dryRun, "dry-run", false, "")
+	var command []string
+	if i := slices.Index(args, "--"); i >= 0 {
+		args, command = args[:i], args[i+1:]
+	}
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return fmt.Errorf("%v\n%s", err, sendUsage)
+	}
+	text := strings.Join(append(pos, command...), " ")
+	if text == "" {
+		return fmt.Errorf("no command given\n%s", sendUsage)
+	}
+	picked := 0
+	for _, s := range []string{target, tag, filter} {
+		if s != "" {
+			picked++
+		}
+	}
+	if picked != 1 {
+		return fmt.Errorf("give exactly one of --target, --tag or --filter\n%s", sendUsage)
+	}
+
+	cfg, err := config.LoadState()
+	if err != nil {
+		return err
+	}
+	client := tmux.NewClient()
+	targets, err := sendTargets(client, cfg, target, tag, filter)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("no panes to send to")
+	}
+
+	if dryRun {
+		for _, t := range targets {
+			fmt.Printf("%s\t%s\n", t, t.Command)
+		}
+		return nil
+	}
+	return tui.Broadcast(func(string) tmux.Service { return client }, targets, text)
+}
+
+// sendTargets resolves the panes of `tswitch send` on the current server.
+func sendTargets(client *tmux.Client, cfg *config.Config, target, tag, filter string) ([]tui.PaneTarget, error) {
+	if target != "" {
+		sess, win, pane, err := resolveMarkTarget(client, []string{target})
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case win < 0:
+			return tui.SessionTargets(client, "", sess)
+		case pane < 0:
+			return tui.WindowTargets(client, "", sess, win)
+		}
+		targets, err := tui.WindowTargets(client, "", sess, win)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range targets {
+			if t.Pane == pane {
+				return []tui.PaneTarget{t}, nil
+			}
+		}
+		return nil, fmt.Errorf("no pane %s", formatTarget(sess, win, pane))
+	}
+
+	sessions, err := orderedSessions(client, cfg)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	if tag != "" {
+		for _, s := range sessions {
+			for _, t := range cfg.GetSessionTags("", s.Name) {
+				if t == tag {
+					names = append(names, s.Name)
+					break
+				}
+			}
+		}
+	} else {
+		wbs, _ := client.ListAllWindowNames()
+		for _, s := range tui.FilterSessions(sessions, filter, wbs) {
+			names = append(names, s.Name)
+		}
+	}
+
+	var targets []tui.PaneTarget
+	for _, name := range names {
+		t, err := tui.SessionTargets(client, "", name)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, t...)
+	}
+	return tui.UniqueTargets(targets), nil
+}